@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrAccessDenied can be returned by an AccessCheck.
+var ErrAccessDenied = errors.New("The access to the key is denied")
+
+// Op is an operation checked by an AccessCheck.
+type Op int
+
+const (
+	OpGet Op = iota
+	OpSet
+	OpDelete
+)
+
+func (op Op) String() string {
+	switch op {
+	case OpGet:
+		return "get"
+	case OpSet:
+		return "set"
+	case OpDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// AccessCheck return an error if the caller identified by ctx may not do op
+// on the key, for example to keep the tenants of a shared cache in their
+// own namespaces.
+type AccessCheck func(ctx context.Context, key interface{}, op Op) error
+
+// SetAccessCheck set the check consulted by every method taking a key: the
+// reads, like Get, GetOrSet or Watch, check OpGet, the writes, like Set,
+// Increment, Touch or Append, check OpSet, and the deletes, like Delete or
+// Invalidate, check OpDelete. GetContext, SetContext and DeleteContext pass
+// their context to it and return its error; the other methods pass
+// context.Background and return its error, or behave like a miss or do
+// nothing when they have no error to return. The methods on all the items
+// filter them: Range, Scan and the exports skip the keys denied OpGet,
+// Import and the loads skip the entries denied OpSet, and Flush keeps the
+// keys denied OpDelete. The check is called with the lock held: it must be
+// fast and must not use the cache. Set to nil to remove it.
+func (c *Cache) SetAccessCheck(check AccessCheck) {
+	c.Lock()
+	c.accessCheck = check
+	c.Unlock()
+}
+
+// checkAccess call the access check if there is one. The caller must hold
+// the lock.
+func (c *Cache) checkAccess(ctx context.Context, key interface{}, op Op) error {
+	if c.accessCheck == nil {
+		return nil
+	}
+	return c.accessCheck(ctx, key, op)
+}
+
+// access works like checkAccess for the methods without a context. The
+// caller must hold the lock.
+func (c *Cache) access(key interface{}, op Op) error {
+	return c.checkAccess(context.Background(), key, op)
+}
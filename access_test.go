@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tenantKey struct{}
+
+func TestAccessCheck(t *testing.T) {
+	c := New(0, 0)
+	c.Set("acme:a", 1, 0)
+	c.SetAccessCheck(func(ctx context.Context, key interface{}, op Op) error {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		if op != OpGet && tenant == "" {
+			return ErrAccessDenied
+		}
+		if tenant != "" && !strings.HasPrefix(key.(string), tenant+":") {
+			return ErrAccessDenied
+		}
+		return nil
+	})
+	acme := context.WithValue(context.Background(), tenantKey{}, "acme")
+	other := context.WithValue(context.Background(), tenantKey{}, "other")
+	if val, err := c.GetContext(acme, "acme:a"); err != nil || val != 1 {
+		t.Error("The tenant must read its keys", err)
+	}
+	if _, err := c.GetContext(other, "acme:a"); err != ErrAccessDenied {
+		t.Error("Another tenant must be denied", err)
+	}
+	if err := c.SetContext(other, "acme:a", 2, 0); err != ErrAccessDenied {
+		t.Error("Another tenant must be denied", err)
+	}
+	if err := c.DeleteContext(other, "acme:a"); err != ErrAccessDenied {
+		t.Error("Another tenant must be denied", err)
+	}
+	c.Delete("acme:a")
+	if val, _ := c.Get("acme:a"); val != 1 {
+		t.Error("A denied Delete must do nothing")
+	}
+	if OpDelete.String() != "delete" {
+		t.Error("Impossiable!")
+	}
+}
+
+func TestAccessCheckEveryMethod(t *testing.T) {
+	c := New(0, 0)
+	c.Set("secret", 1, 0)
+	c.Set("public", 1, 0)
+	c.SetAccessCheck(func(ctx context.Context, key interface{}, op Op) error {
+		if key == "secret" {
+			return ErrAccessDenied
+		}
+		return nil
+	})
+	if _, loaded := c.GetOrSet("secret", 2, 0); loaded {
+		t.Error("GetOrSet must be denied")
+	}
+	if _, found := c.GetItemInfo("secret"); found {
+		t.Error("GetItemInfo must be denied")
+	}
+	if _, _, found := c.GetWithVersion("secret"); found {
+		t.Error("GetWithVersion must be denied")
+	}
+	if err := c.SetIfVersion("secret", 2, 0, 1); err != ErrAccessDenied {
+		t.Error("SetIfVersion must be denied", err)
+	}
+	if err := c.Increment("secret", 1); err != ErrAccessDenied {
+		t.Error("Increment must be denied", err)
+	}
+	if err := c.Decrement("secret", 1); err != ErrAccessDenied {
+		t.Error("Decrement must be denied", err)
+	}
+	if err := c.IncrementMulti(map[interface{}]int64{"secret": 1}); err != ErrAccessDenied {
+		t.Error("IncrementMulti must be denied", err)
+	}
+	if err := c.Append("secret", "a"); err != ErrAccessDenied {
+		t.Error("Append must be denied", err)
+	}
+	if _, err := c.ListPush("secret", 1); err != ErrAccessDenied {
+		t.Error("ListPush must be denied", err)
+	}
+	if err := c.SetChunked("secret", []byte("abc"), 2, 0); err != ErrAccessDenied {
+		t.Error("SetChunked must be denied", err)
+	}
+	if err := c.SetFenced("secret", 2, 0, c.Fence()); err != ErrAccessDenied {
+		t.Error("SetFenced must be denied", err)
+	}
+	if _, err := c.GetOrLoad("secret", 0, func() (interface{}, error) { return 2, nil }); err != ErrAccessDenied {
+		t.Error("GetOrLoad must be denied", err)
+	}
+	err := c.Update(func(tx *Txn) error {
+		if _, found := tx.Get("secret"); found {
+			t.Error("Txn.Get must be denied")
+		}
+		tx.Set("secret", 2, 0)
+		return nil
+	})
+	if err != ErrAccessDenied {
+		t.Error("Update must be denied", err)
+	}
+	if c.Touch("secret", time.Minute) {
+		t.Error("Touch must be denied")
+	}
+	if c.SetRefreshing("secret", true) {
+		t.Error("SetRefreshing must be denied")
+	}
+	c.SetForce("secret", 2, 0)
+	c.DeleteSoft("secret", time.Minute)
+	c.Invalidate("secret")
+	c.DeleteChunked("secret")
+	if _, ok := <-func() <-chan Event { ch, _ := c.Watch("secret"); return ch }(); ok {
+		t.Error("Watch must be denied")
+	}
+	c.Range(func(key, value interface{}) bool {
+		if key == "secret" {
+			t.Error("Range must skip the denied key")
+		}
+		return true
+	})
+	if keys, _ := c.Scan(0, 10); len(keys) != 1 || keys[0] != "public" {
+		t.Error("Scan must skip the denied key", keys)
+	}
+	var buf bytes.Buffer
+	if err := c.SaveCodec(&buf, JSONCodec{}); err != nil || strings.Contains(buf.String(), "secret") {
+		t.Error("SaveCodec must skip the denied key", err, buf.String())
+	}
+	buf.Reset()
+	if err := c.ExportJSON(&buf); err != nil || strings.Contains(buf.String(), "secret") {
+		t.Error("ExportJSON must skip the denied key", err, buf.String())
+	}
+	if s := c.Import([]Entry{{Key: "secret", Value: 2}}, Overwrite); s.Applied != 0 {
+		t.Error("Import must skip the denied key", s)
+	}
+	c.Flush()
+	c.SetAccessCheck(nil)
+	if val, found := c.Get("secret"); !found || val != 1 {
+		t.Error("Now, the denied key must be untouched", val)
+	}
+	if _, found := c.Get("public"); found {
+		t.Error("Flush must delete the allowed key")
+	}
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+)
+
+// BloomFilter is a set of keys which may give false positives, but no false
+// negatives, in a fixed size. A Cache uses it to know the keys which do not
+// exist in its Store, see SetKeyFilter.
+type BloomFilter struct {
+	bits   []uint64
+	m      uint64
+	hashes int
+}
+
+// NewBloomFilter create a BloomFilter sized for n keys with a false positive
+// rate of fpRate, like 0.01.
+func NewBloomFilter(n int, fpRate float64) (*BloomFilter, error) {
+	if n < 1 {
+		return nil, errors.New("The number of keys of Bloom Filter must greater than 0")
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		return nil, errors.New("The false positive rate of Bloom Filter must be between 0 and 1")
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	m = (m + 63) / 64 * 64
+	hashes := int(math.Round(float64(m) / float64(n) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &BloomFilter{bits: make([]uint64, m/64), m: m, hashes: hashes}, nil
+}
+
+// Add a key. It is safe to call concurrently with MayContain.
+func (f *BloomFilter) Add(key interface{}) {
+	h := hashKey(key)
+	for i := 0; i < f.hashes; i++ {
+		idx := f.index(h, i)
+		word, bit := &f.bits[idx/64], uint64(1)<<(idx%64)
+		for {
+			old := atomic.LoadUint64(word)
+			if old&bit != 0 || atomic.CompareAndSwapUint64(word, old, old|bit) {
+				break
+			}
+		}
+	}
+}
+
+// MayContain return false if the key was never added, and true if it
+// probably was.
+func (f *BloomFilter) MayContain(key interface{}) bool {
+	h := hashKey(key)
+	for i := 0; i < f.hashes; i++ {
+		idx := f.index(h, i)
+		if atomic.LoadUint64(&f.bits[idx/64])&(uint64(1)<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *BloomFilter) index(h uint64, i int) uint64 {
+	lo, hi := h&0xffffffff, h>>32
+	return (lo + uint64(i)*hi) % f.m
+}
+
+// SetKeyFilter make the misses of Get, Fetch and GetOrLoad for the keys not
+// in f fail with ErrNotFound without asking the Store or the loader, so the
+// lookups of keys which do not exist can not overload the backend. Seed f
+// with the keys of the backend; the keys set in the cache are added to it.
+// Set to nil to remove it.
+func (c *Cache) SetKeyFilter(f *BloomFilter) {
+	c.Lock()
+	c.keyFilter = f
+	c.Unlock()
+}
+
+// RebuildKeyFilter replace the key filter by f once seed has added the keys
+// of the backend to it with add, for example to forget the deleted keys.
+// The keys set in the cache meanwhile are added to f too. If seed returns
+// an error, the filter is not replaced.
+func (c *Cache) RebuildKeyFilter(f *BloomFilter, seed func(add func(key interface{})) error) error {
+	c.Lock()
+	if c.rebuildFilter != nil {
+		c.Unlock()
+		return errors.New("The key filter is being rebuilt")
+	}
+	c.rebuildFilter = f
+	c.Unlock()
+	err := seed(f.Add)
+	c.Lock()
+	if err == nil {
+		c.keyFilter = f
+	}
+	c.rebuildFilter = nil
+	c.Unlock()
+	return err
+}
+
+// filterKey add a key set in the cache to the key filters. The caller must
+// hold the lock.
+func (c *Cache) filterKey(key interface{}) {
+	if c.keyFilter != nil {
+		c.keyFilter.Add(key)
+	}
+	if c.rebuildFilter != nil {
+		c.rebuildFilter.Add(key)
+	}
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	f, err := NewBloomFilter(1000, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		f.Add(strconv.Itoa(i))
+	}
+	for i := 0; i < 1000; i++ {
+		if !f.MayContain(strconv.Itoa(i)) {
+			t.Fatal("A Bloom Filter has no false negative")
+		}
+	}
+	fp := 0
+	for i := 1000; i < 11000; i++ {
+		if f.MayContain(strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	if fp > 300 {
+		t.Errorf("You get too many false positives: %d", fp)
+	}
+	if _, err := NewBloomFilter(0, 0.01); err == nil {
+		t.Error("Impossiable!")
+	}
+}
+
+func TestKeyFilter(t *testing.T) {
+	s := &mapStore{data: map[interface{}]interface{}{"a": 1, "b": 2}}
+	c := New(0, 0)
+	c.SetStore(s)
+	f, _ := NewBloomFilter(100, 0.01)
+	f.Add("a")
+	c.SetKeyFilter(f)
+	if val, found := c.Get("a"); !found || val != 1 {
+		t.Error("The keys of the filter must be loaded")
+	}
+	if _, err := c.Fetch("nope"); err != ErrNotFound || s.loads != 1 {
+		t.Error("The keys not in the filter must not be loaded")
+	}
+	if _, err := c.GetOrLoad("nope", 0, func() (interface{}, error) { return 1, nil }); err != ErrNotFound {
+		t.Error("The loader must not be called")
+	}
+	c.Set("c", 3, 0)
+	if !f.MayContain("c") {
+		t.Error("The keys set must be added")
+	}
+	g, _ := NewBloomFilter(100, 0.01)
+	err := c.RebuildKeyFilter(g, func(add func(interface{})) error {
+		for k := range s.data {
+			add(k)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, found := c.Get("b"); !found || val != 2 {
+		t.Error("Now, the filter is rebuilt")
+	}
+	h, _ := NewBloomFilter(100, 0.01)
+	c.RebuildKeyFilter(h, func(add func(interface{})) error {
+		return errors.New("Failed")
+	})
+	if c.keyFilter != g {
+		t.Error("The filter must be kept when the rebuild fails")
+	}
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"fmt"
+)
+
+// Policy names an eviction policy of NewBounded.
+type Policy string
+
+// The policies of NewBounded.
+const (
+	PolicyLRU      Policy = "lru"
+	PolicyLFU      Policy = "lfu"
+	Policy2Q       Policy = "2q"
+	PolicyClock    Policy = "clock"
+	PolicySieve    Policy = "sieve"
+	PolicyS3FIFO   Policy = "s3fifo"
+	PolicyFIFO     Policy = "fifo"
+	PolicyRandom   Policy = "random"
+	PolicySmallLRU Policy = "small"
+	// PolicyNone builds a NopCache, storing nothing, to disable a cache.
+	PolicyNone Policy = "none"
+)
+
+// Policies is the list of the eviction policies of NewBounded, PolicyNone
+// excluded.
+var Policies = []Policy{
+	PolicyLRU, PolicyLFU, Policy2Q, PolicyClock, PolicySieve, PolicyS3FIFO,
+	PolicyFIFO, PolicyRandom, PolicySmallLRU,
+}
+
+// NewBounded create a BoundedCache of size entries evicting them by policy,
+// so the implementations can be chosen by name, from a config file or a
+// flag. The size is 0 means no limit for the policies which allow it.
+func NewBounded(policy Policy, size int) (BoundedCache, error) {
+	switch policy {
+	case PolicyLRU:
+		return bounded(NewLRU(size))
+	case PolicyLFU:
+		return bounded(NewLFU(size))
+	case Policy2Q:
+		return bounded(NewTwoQueue(size))
+	case PolicyClock:
+		return bounded(NewClock(size))
+	case PolicySieve:
+		return bounded(NewSieve(size))
+	case PolicyS3FIFO:
+		return bounded(NewS3FIFO(size))
+	case PolicyFIFO:
+		return bounded(NewWithPolicy(size, NewFIFOPolicy()))
+	case PolicyRandom:
+		return bounded(NewWithPolicy(size, NewRandomPolicy()))
+	case PolicySmallLRU:
+		return bounded(NewSmallLRU(size))
+	case PolicyNone:
+		return NopCache{}, nil
+	}
+	return nil, fmt.Errorf("Unknown policy %s", policy)
+}
+
+// bounded return a nil BoundedCache with err, not a nil pointer of the
+// cache type, so the result can be compared to nil.
+func bounded(c BoundedCache, err error) (BoundedCache, error) {
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// The caches of the package implement the common interfaces.
+var (
+	_ Interface = (*Cache)(nil)
+	_ Interface = (*LRUCache)(nil)
+	_ Interface = (*TieredCache)(nil)
+	_ Interface = NopCache{}
+	_ Interface = (*ReadMostlyCache)(nil)
+
+	_ BoundedCache = (*LRUCache)(nil)
+	_ BoundedCache = (*LFUCache)(nil)
+	_ BoundedCache = (*TwoQueueCache)(nil)
+	_ BoundedCache = (*ClockCache)(nil)
+	_ BoundedCache = (*SieveCache)(nil)
+	_ BoundedCache = (*S3FIFOCache)(nil)
+	_ BoundedCache = (*PolicyCache)(nil)
+	_ BoundedCache = (*SmallLRUCache)(nil)
+	_ BoundedCache = NopCache{}
+)
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestNewBounded(t *testing.T) {
+	for _, policy := range Policies {
+		c, err := NewBounded(policy, 2)
+		if err != nil {
+			t.Fatal(policy, err)
+		}
+		c.Add("a", 1)
+		c.Add("b", 2)
+		c.Add("c", 3)
+		if c.Len() != 2 {
+			t.Errorf("The %s cache must hold 2 entries, not %d", policy, c.Len())
+		}
+		if val, found := c.Get("c"); !found || val != 3 {
+			t.Errorf("The %s cache must keep the last entry", policy)
+		}
+	}
+	if _, err := NewBounded("nope", 2); err == nil {
+		t.Error("Impossiable!")
+	}
+	if c, err := NewBounded(PolicyLRU, -1); err == nil || c != nil {
+		t.Error("The cache must be nil with the error", c)
+	}
+}
@@ -0,0 +1,260 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrTooLarge is returned by BytesCache.Set when an entry does not fit in a
+// segment.
+var ErrTooLarge = errors.New("The entry is larger than a segment")
+
+// errBytesClosed is returned by BytesCache.Set after Close.
+var errBytesClosed = errors.New("The cache is closed")
+
+// BytesCache is a goroutine-safe cache of []byte values by string keys,
+// stored in pre-allocated segments, so a large cache does not hold millions
+// of pointers for the GC to scan. Each segment is a ring buffer: when it is
+// full, its oldest entries are overwritten. The index of a segment maps the
+// hash of the keys to their offset, and holds no pointer either.
+//
+// The expired entries are not returned by Get, and their space is reused
+// when the ring buffer comes back to them. See NewBytesMmap to store the
+// segments outside the Go heap.
+type BytesCache struct {
+	segments          []bytesSegment
+	defaultExpiration time.Duration
+	// mapped is the memory of the segments when they are mapped by
+	// NewBytesMmap, and file the file mapped, if any.
+	mapped []byte
+	file   *os.File
+}
+
+type bytesSegment struct {
+	sync.Mutex
+	buf   []byte
+	head  int
+	tail  int
+	used  int
+	index map[uint64]uint32
+	// meta is where head, tail and used are saved for a file mapped by
+	// NewBytesMmap, nil otherwise.
+	meta []byte
+}
+
+// The header of an entry: the hash of the key, its expiration in UnixNano or
+// 0, the length of the key and of the value.
+const bytesHeaderSize = 8 + 8 + 2 + 4
+
+// NewBytes create a BytesCache holding maxBytes of entries, split in
+// segments locked separately. An entry uses the length of its key and value
+// plus 22 bytes, and must fit in a segment. If the dur of Set is 0, the
+// defaultExpiration is used, and if it is less than 1 the entries never
+// expire.
+func NewBytes(maxBytes int, segments int, defaultExpiration time.Duration) (*BytesCache, error) {
+	if segments <= 0 {
+		return nil, errors.New("The number of segments must greater than 0")
+	}
+	if maxBytes/segments <= bytesHeaderSize {
+		return nil, errors.New("The max bytes are too small for the segments")
+	}
+	if uint64(maxBytes/segments) > math.MaxUint32 {
+		return nil, errors.New("A segment must be smaller than 4GB")
+	}
+	return newBytes(make([]byte, maxBytes), segments, defaultExpiration), nil
+}
+
+// newBytes create a BytesCache whose segments are slices of buf.
+func newBytes(buf []byte, segments int, defaultExpiration time.Duration) *BytesCache {
+	c := &BytesCache{
+		segments:          make([]bytesSegment, segments),
+		defaultExpiration: defaultExpiration,
+	}
+	size := len(buf) / segments
+	for i := range c.segments {
+		c.segments[i].buf = buf[i*size : (i+1)*size : (i+1)*size]
+		c.segments[i].index = map[uint64]uint32{}
+	}
+	return c
+}
+
+func (c *BytesCache) segment(h uint64) *bytesSegment {
+	return &c.segments[h%uint64(len(c.segments))]
+}
+
+// Get return a copy of the value of the key, and a bool indicating whether
+// it was found.
+func (c *BytesCache) Get(key string) ([]byte, bool) {
+	h := hashString(key)
+	s := c.segment(h)
+	s.Lock()
+	defer s.Unlock()
+	off, ok := s.lookup(h, key)
+	if !ok {
+		return nil, false
+	}
+	hdr := s.header(off)
+	if exp := int64(binary.LittleEndian.Uint64(hdr[8:])); exp != 0 && exp < time.Now().UnixNano() {
+		return nil, false
+	}
+	keyLen := int(binary.LittleEndian.Uint16(hdr[16:]))
+	val := make([]byte, binary.LittleEndian.Uint32(hdr[18:]))
+	s.read(s.offset(off, bytesHeaderSize+keyLen), val)
+	return val, true
+}
+
+// Set add a new key or replace an exist key, copying the value. If the dur
+// is 0, the default expiration is used. It returns ErrTooLarge if the entry
+// does not fit in a segment.
+func (c *BytesCache) Set(key string, val []byte, dur time.Duration) error {
+	if len(key) > 0xffff {
+		return ErrTooLarge
+	}
+	h := hashString(key)
+	s := c.segment(h)
+	size := bytesHeaderSize + len(key) + len(val)
+	if size > len(s.buf) {
+		return ErrTooLarge
+	}
+	if dur == 0 {
+		dur = c.defaultExpiration
+	}
+	var hdr [bytesHeaderSize]byte
+	binary.LittleEndian.PutUint64(hdr[0:], h)
+	if dur > 0 {
+		binary.LittleEndian.PutUint64(hdr[8:], uint64(time.Now().Add(dur).UnixNano()))
+	}
+	binary.LittleEndian.PutUint16(hdr[16:], uint16(len(key)))
+	binary.LittleEndian.PutUint32(hdr[18:], uint32(len(val)))
+
+	s.Lock()
+	defer s.Unlock()
+	if s.buf == nil {
+		return errBytesClosed
+	}
+	for s.used+size > len(s.buf) {
+		s.evictOldest()
+	}
+	off := s.tail
+	s.write(off, hdr[:])
+	s.write(s.offset(off, bytesHeaderSize), []byte(key))
+	s.write(s.offset(off, bytesHeaderSize+len(key)), val)
+	s.tail = s.offset(off, size)
+	s.used += size
+	s.saveMeta()
+	s.index[h] = uint32(off)
+	return nil
+}
+
+// Delete a key-value pair if the key is existed. Its space is reused when
+// the ring buffer comes back to it.
+func (c *BytesCache) Delete(key string) {
+	h := hashString(key)
+	s := c.segment(h)
+	s.Lock()
+	if off, ok := s.lookup(h, key); ok {
+		// The entry is marked expired for the index rebuilt from a file.
+		var exp [8]byte
+		binary.LittleEndian.PutUint64(exp[:], 1)
+		s.write(s.offset(off, 8), exp[:])
+		delete(s.index, h)
+	}
+	s.Unlock()
+}
+
+// Len return the number of entries, the expired ones included.
+func (c *BytesCache) Len() int {
+	n := 0
+	for i := range c.segments {
+		s := &c.segments[i]
+		s.Lock()
+		n += len(s.index)
+		s.Unlock()
+	}
+	return n
+}
+
+// Clear delete all the entries.
+func (c *BytesCache) Clear() {
+	for i := range c.segments {
+		s := &c.segments[i]
+		s.Lock()
+		s.head, s.tail, s.used = 0, 0, 0
+		s.saveMeta()
+		s.index = map[uint64]uint32{}
+		s.Unlock()
+	}
+}
+
+// lookup return the offset of the entry of the key. Two keys may have the
+// same hash, so the key of the entry is compared. The caller must hold the
+// lock.
+func (s *bytesSegment) lookup(h uint64, key string) (int, bool) {
+	off, ok := s.index[h]
+	if !ok {
+		return 0, false
+	}
+	hdr := s.header(int(off))
+	if int(binary.LittleEndian.Uint16(hdr[16:])) != len(key) {
+		return 0, false
+	}
+	k := make([]byte, len(key))
+	s.read(s.offset(int(off), bytesHeaderSize), k)
+	if string(k) != key {
+		return 0, false
+	}
+	return int(off), true
+}
+
+// evictOldest free the space of the oldest entry, and remove it from the
+// index unless the key was written again since. The caller must hold the
+// lock.
+func (s *bytesSegment) evictOldest() {
+	hdr := s.header(s.head)
+	h := binary.LittleEndian.Uint64(hdr[0:])
+	size := bytesHeaderSize + int(binary.LittleEndian.Uint16(hdr[16:])) + int(binary.LittleEndian.Uint32(hdr[18:]))
+	if off, ok := s.index[h]; ok && int(off) == s.head {
+		delete(s.index, h)
+	}
+	s.head = s.offset(s.head, size)
+	s.used -= size
+	s.saveMeta()
+}
+
+// saveMeta save head, tail and used in the mapped file. The caller must
+// hold the lock.
+func (s *bytesSegment) saveMeta() {
+	if s.meta != nil {
+		binary.LittleEndian.PutUint64(s.meta[0:], uint64(s.head))
+		binary.LittleEndian.PutUint64(s.meta[8:], uint64(s.tail))
+		binary.LittleEndian.PutUint64(s.meta[16:], uint64(s.used))
+	}
+}
+
+func (s *bytesSegment) header(off int) [bytesHeaderSize]byte {
+	var hdr [bytesHeaderSize]byte
+	s.read(off, hdr[:])
+	return hdr
+}
+
+// offset return the offset n bytes after off in the ring buffer.
+func (s *bytesSegment) offset(off, n int) int {
+	return (off + n) % len(s.buf)
+}
+
+// write copy data at off, wrapping around the end of the ring buffer.
+func (s *bytesSegment) write(off int, data []byte) {
+	n := copy(s.buf[off:], data)
+	copy(s.buf, data[n:])
+}
+
+// read copy len(dst) bytes at off, wrapping around the end of the ring
+// buffer.
+func (s *bytesSegment) read(off int, dst []byte) {
+	n := copy(dst, s.buf[off:])
+	copy(dst[n:], s.buf)
+}
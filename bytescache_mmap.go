@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"time"
+)
+
+// The layout of a file mapped by NewBytesMmap: a header with the magic, the
+// number of segments and the size of their ring buffer, then every segment
+// with its saved head, tail and used before its ring buffer.
+const (
+	bytesFileMagic      = "GOCACHE1"
+	bytesFileHeaderSize = 8 + 8 + 8
+	bytesMetaSize       = 8 + 8 + 8
+)
+
+// NewBytesMmap works like NewBytes, but the segments are mapped in memory
+// outside the Go heap, so a cache of several GB does not grow it. With an
+// empty path, the memory is anonymous. Otherwise it is the file at path,
+// created if needed: the entries of a file closed by Close, and not
+// expired, are found again by the next NewBytesMmap of the file with the
+// same maxBytes and segments. A file of another size or layout is
+// overwritten. The entries written while the process crashes may be lost.
+// Call Close to unmap the memory. It is only supported on unix.
+func NewBytesMmap(path string, maxBytes int, segments int, defaultExpiration time.Duration) (*BytesCache, error) {
+	if segments <= 0 {
+		return nil, errors.New("The number of segments must greater than 0")
+	}
+	segSize := maxBytes / segments
+	if segSize <= bytesHeaderSize {
+		return nil, errors.New("The max bytes are too small for the segments")
+	}
+	if uint64(segSize) > math.MaxUint32 {
+		return nil, errors.New("A segment must be smaller than 4GB")
+	}
+	size := bytesFileHeaderSize + segments*(bytesMetaSize+segSize)
+	var f *os.File
+	if path != "" {
+		var err error
+		if f, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+			return nil, err
+		}
+		fi, err := f.Stat()
+		if err == nil && fi.Size() != int64(size) {
+			err = f.Truncate(0)
+			if err == nil {
+				err = f.Truncate(int64(size))
+			}
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	data, err := mmap(f, size)
+	if err != nil {
+		if f != nil {
+			f.Close()
+		}
+		return nil, err
+	}
+	c := &BytesCache{
+		segments:          make([]bytesSegment, segments),
+		defaultExpiration: defaultExpiration,
+		mapped:            data,
+		file:              f,
+	}
+	hdr := data[:bytesFileHeaderSize]
+	valid := string(hdr[:8]) == bytesFileMagic &&
+		binary.LittleEndian.Uint64(hdr[8:]) == uint64(segments) &&
+		binary.LittleEndian.Uint64(hdr[16:]) == uint64(segSize)
+	for i := range c.segments {
+		s := &c.segments[i]
+		start := bytesFileHeaderSize + i*(bytesMetaSize+segSize)
+		s.meta = data[start : start+bytesMetaSize : start+bytesMetaSize]
+		s.buf = data[start+bytesMetaSize : start+bytesMetaSize+segSize : start+bytesMetaSize+segSize]
+		s.index = map[uint64]uint32{}
+		if !valid || !s.rebuild() {
+			s.head, s.tail, s.used = 0, 0, 0
+			s.index = map[uint64]uint32{}
+			s.saveMeta()
+		}
+	}
+	copy(hdr, bytesFileMagic)
+	binary.LittleEndian.PutUint64(hdr[8:], uint64(segments))
+	binary.LittleEndian.PutUint64(hdr[16:], uint64(segSize))
+	return c, nil
+}
+
+// rebuild load head, tail and used from the file, and index the entries
+// which are not expired. It returns false if the segment is corrupted.
+func (s *bytesSegment) rebuild() bool {
+	head := binary.LittleEndian.Uint64(s.meta[0:])
+	tail := binary.LittleEndian.Uint64(s.meta[8:])
+	used := binary.LittleEndian.Uint64(s.meta[16:])
+	n := uint64(len(s.buf))
+	if head >= n || tail >= n || used > n || (head+used)%n != tail {
+		return false
+	}
+	s.head, s.tail, s.used = int(head), int(tail), int(used)
+	now := time.Now().UnixNano()
+	// The entries are visited from the oldest, so a newer entry of a key
+	// replaces the older ones.
+	for off, left := s.head, s.used; left > 0; {
+		if left < bytesHeaderSize {
+			return false
+		}
+		hdr := s.header(off)
+		h := binary.LittleEndian.Uint64(hdr[0:])
+		size := bytesHeaderSize + int(binary.LittleEndian.Uint16(hdr[16:])) + int(binary.LittleEndian.Uint32(hdr[18:]))
+		if size > left {
+			return false
+		}
+		if exp := int64(binary.LittleEndian.Uint64(hdr[8:])); exp != 0 && exp < now {
+			delete(s.index, h)
+		} else {
+			s.index[h] = uint32(off)
+		}
+		off = s.offset(off, size)
+		left -= size
+	}
+	return true
+}
+
+// Close unmap the memory of a cache made by NewBytesMmap, which keeps the
+// entries in its file, if any. The cache must not be used after. Close does
+// nothing for the caches made by NewBytes.
+func (c *BytesCache) Close() error {
+	if c.mapped == nil {
+		return nil
+	}
+	for i := range c.segments {
+		s := &c.segments[i]
+		s.Lock()
+		s.buf, s.meta = nil, nil
+		s.index = map[uint64]uint32{}
+		s.Unlock()
+	}
+	err := munmap(c.mapped)
+	c.mapped = nil
+	if c.file != nil {
+		if cerr := c.file.Close(); err == nil {
+			err = cerr
+		}
+		c.file = nil
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestBytesCacheMmap(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("The memory mapping is only supported on unix")
+	}
+	c, err := NewBytesMmap("", 1024, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("a", []byte("lalala"), 0)
+	if got, found := c.Get("a"); !found || string(got) != "lalala" {
+		t.Error("You get a wrong value", string(got))
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := c.Get("a"); found || c.Set("a", nil, 0) == nil {
+		t.Error("A closed cache must not be used")
+	}
+}
+
+func TestBytesCacheMmapFile(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("The memory mapping is only supported on unix")
+	}
+	dir, err := ioutil.TempDir("", "bytescache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cache")
+	c, err := NewBytesMmap(path, 256, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b", "c", "d"} {
+		c.Set(key, []byte("old "+key), 0)
+		c.Set(key, []byte("new "+key), 0)
+	}
+	c.Delete("b")
+	c.Set("e", []byte("x"), time.Nanosecond)
+	c.Close()
+
+	c, err = NewBytesMmap(path, 256, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, found := c.Get("a"); !found || string(got) != "new a" {
+		t.Error("The entries must survive the restart", string(got))
+	}
+	if _, found := c.Get("b"); found {
+		t.Error("The deleted entry must stay deleted")
+	}
+	if _, found := c.Get("e"); found || c.Len() != 3 {
+		t.Error("The expired entry must not be indexed", c.Len())
+	}
+	c.Set("f", []byte("y"), 0)
+	c.Close()
+
+	c, err = NewBytesMmap(path, 512, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.Len() != 0 {
+		t.Error("A file of another size must be overwritten")
+	}
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBytesCache(t *testing.T) {
+	if _, err := NewBytes(100, 0, 0); err == nil {
+		t.Error("Impossiable!")
+	}
+	if _, err := NewBytes(10, 1, 0); err == nil {
+		t.Error("Impossiable!")
+	}
+	c, err := NewBytes(1024, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val := []byte("lalala")
+	c.Set("a", val, 0)
+	val[0] = 'b'
+	if got, found := c.Get("a"); !found || string(got) != "lalala" {
+		t.Error("The value must be copied", string(got))
+	}
+	c.Set("a", []byte("bababa"), 0)
+	if got, _ := c.Get("a"); string(got) != "bababa" || c.Len() != 1 {
+		t.Error("The value must be replaced", string(got))
+	}
+	c.Set("b", []byte("x"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, found := c.Get("b"); found {
+		t.Error("The expired entry must not be found")
+	}
+	c.Delete("a")
+	if _, found := c.Get("a"); found {
+		t.Error("The key is delete, you should not get")
+	}
+	if err := c.Set("big", make([]byte, 512), 0); err != ErrTooLarge {
+		t.Error("You get a wrong error", err)
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Error("Now, the cache is cleared")
+	}
+}
+
+func TestBytesCacheWrap(t *testing.T) {
+	c, _ := NewBytes(200, 1, 0)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint("key", i)
+		if err := c.Set(key, bytes.Repeat([]byte{byte(i)}, i%20), 0); err != nil {
+			t.Fatal(err)
+		}
+		if got, found := c.Get(key); !found || !bytes.Equal(got, bytes.Repeat([]byte{byte(i)}, i%20)) {
+			t.Fatal("The last entry must be found across the end of the ring", i)
+		}
+	}
+	if _, found := c.Get("key0"); found {
+		t.Error("The oldest entries must be overwritten")
+	}
+	if n := c.Len(); n == 0 || n > 200/bytesHeaderSize {
+		t.Error("You get a wrong len", n)
+	}
+}
+
+func TestBytesCacheConcurrent(t *testing.T) {
+	c, _ := NewBytes(1<<16, 8, 0)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := fmt.Sprint(g, "-", i%50)
+				c.Set(key, []byte(key), 0)
+				if got, found := c.Get(key); found && string(got) != key {
+					t.Error("You get a wrong value", string(got))
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
@@ -3,13 +3,29 @@
 // https://github.com/pmylund/go-cache
 // and LRU cache implementation in groupcache:
 // https://github.com/golang/groupcache/tree/master/lru
+//
+// This package is the entry point for all the caches, which are
+// interchangeable behind three interfaces:
+//
+//	Interface     Get, Set with a TTL and Delete: Cache, LRUCache, TieredCache,
+//	              and the remote caches of the redis and memcache subpackages.
+//	BoundedCache  a limited number of entries: NewBounded builds one for
+//	              every eviction policy by name.
+//	Store         the origin behind a read-through Cache, see SetStore, and
+//	              WritableStore for a write-through one.
+//
+// The unsafe subpackage holds a Cache and a LRUCache without locking, for use
+// by a single goroutine.
 package cache
 
 import (
 	"container/list"
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,14 +34,117 @@ type Cache struct {
 	sync.RWMutex
 	items             map[interface{}]*Item
 	defaultExpiration time.Duration
+	tombstones        map[interface{}]time.Time
+	fenceSeq          uint64
+	versionSeq        uint64
+	fences            map[interface{}]uint64
+	expirySubs        []*expirySubscriber
+	expiries          *expiryHeap
+	expiredQueue      *expiredHeap
+	expiryWake        chan struct{}
+	maxItems          int
+	evictSamples      int
+	evictMode         SampleEviction
+	keyGuard          atomic.Value
+	neverHit          *neverHitStats
+	parent            *Cache
+	janitor           *janitor
+	onEvicted         func(interface{}, interface{})
+	evicted           []keyValue
+	stats             *statCounters
+	merge             MergeFunc
+	evictLimit        *rateLimiter
+	flags             Flags
+	degraded          bool
+	hotKeyWindow      time.Duration
+	topK              *TopK
+	staleWindow       time.Duration
+	onStateChange     func(interface{}, ItemState)
+	stateChanges      []stateChange
+	loads             map[interface{}]*loadCall
+	revalidate        func(interface{}) (interface{}, error)
+	store             Store
+	accessCheck       AccessCheck
+	writeBehind       *WriteBehind
+	ops               atomic.Value
+	bus               atomic.Value
+	negativeTTL       time.Duration
+	keyFilter         *BloomFilter
+	rebuildFilter     *BloomFilter
+	minTTL            time.Duration
+	maxTTL            time.Duration
+	cleanupItems      int
+	cleanupTime       time.Duration
+	sweep             *reflect.MapIter
+	sweepMap          uintptr
+	fullPolicy        FullPolicy
+	room              chan struct{}
+	watchers          map[interface{}][]*watcher
+	removalSubs       []*removalSubscriber
+	removals          []Event
+	copier            func(interface{}) interface{}
+	changes           uint64
+	snapshotReads     bool
+	snapshot          atomic.Value
+	clock             Clock
+	keyLocks          *[keyLockStripes]sync.Mutex
+	maxValueSize      int64
+	valueSizePolicy   ValueSizePolicy
+	wheel             *timingWheel
+	flushEvicts       bool
+	errorHandler      func(error)
+}
+
+type keyValue struct {
+	key   interface{}
+	value interface{}
 }
 
 type Item struct {
-	Object     interface{}
-	Expiration *time.Time
+	// accessed is the last access time in UnixNano, only tracked for the
+	// sampled LRU eviction and the hot keys. It is the first field to be
+	// 64-bit aligned.
+	accessed int64
+	// hits is the number of Get which found the item, only tracked for the
+	// never-hit report and the hot keys.
+	hits int64
+	// written is the time of the write which stored the item in UnixNano.
+	written int64
+	// created is when the key was stored, kept when its value is replaced.
+	created int64
+	// windowStart and windowHits count the hits of the current hot keys
+	// window of the item.
+	windowStart int64
+	windowHits  int64
+	// shared is set when the item is shared by forked caches, so it must be
+	// copied before being modified in place.
+	shared int32
+	// version is the version of the value, see GetWithVersion.
+	version uint64
+	// refreshing is set by SetRefreshing, and staleNotified once the item
+	// was reported stale to the state callback.
+	refreshing    bool
+	staleNotified bool
+	Object        interface{}
+	Expiration    *time.Time
 }
 
-// Returns true if the item has expired.
+// expiringItem is an Item allocated with its expiration, so a Set with a
+// TTL makes one allocation.
+type expiringItem struct {
+	Item
+	expiration time.Time
+}
+
+func newExpiringItem(val interface{}, t time.Time) *Item {
+	e := &expiringItem{expiration: t}
+	e.Object = val
+	e.Expiration = &e.expiration
+	return &e.Item
+}
+
+// Returns true if the item has expired, by the system clock even if the
+// cache has another one, see SetClock.
 func (item *Item) Expired() bool {
 	if item.Expiration == nil {
 		return false
@@ -42,72 +161,331 @@ func New(defaultExpiration, cleanInterval time.Duration) *Cache {
 	c := &Cache{
 		items:             map[interface{}]*Item{},
 		defaultExpiration: defaultExpiration,
+		stats:             &statCounters{},
 	}
 	if cleanInterval > 0 {
-		go func() {
-			for {
-				time.Sleep(cleanInterval)
-				c.DeleteExpired()
-			}
-		}()
+		c.janitor = newJanitor(cleanInterval)
+		go c.janitor.run(c, realClock{}.NewTicker(cleanInterval))
 	}
 	return c
 }
 
 // Get return an item or nil, and a bool indicating whether
-// the key was found.
+// the key was found. A child cache looks the key up in its parent if it
+// does not have it, and a cache with a Store loads it from the store. See
+// SetStaleWhileRevalidate for the stale items. A key cached as missing by
+// SetNegative is found, with a Negative value.
 func (c *Cache) Get(key interface{}) (interface{}, bool) {
+	val, err := c.get(context.Background(), key)
+	return val, err == nil
+}
+
+// Fetch works like Get, but returns ErrNotFound if the key is not found, or
+// the error of the Store. For a key cached as missing, it returns the error
+// of its Negative value.
+func (c *Cache) Fetch(key interface{}) (interface{}, error) {
+	return c.GetContext(context.Background(), key)
+}
+
+// GetContext works like Fetch, passing ctx to the access check, see
+// SetAccessCheck, and to the Store if it is a ContextStore. It returns the
+// error of ctx if ctx is done, before the lookup or while waiting for the
+// Store.
+func (c *Cache) GetContext(ctx context.Context, key interface{}) (interface{}, error) {
+	val, err := c.get(ctx, key)
+	if n, ok := val.(Negative); ok && err == nil {
+		return nil, n.error()
+	}
+	return val, err
+}
+
+// get look a key up and record it in the operation log.
+func (c *Cache) get(ctx context.Context, key interface{}) (interface{}, error) {
+	val, err := c.getContext(ctx, key)
+	if log := c.opLog(); log != nil {
+		op := "hit"
+		if err != nil {
+			op = "miss"
+		}
+		log.record(op, key)
+	}
+	return val, err
+}
+
+func (c *Cache) getContext(ctx context.Context, key interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !c.keyAllowed(key) {
+		return nil, ErrNotFound
+	}
 	c.RLock()
+	if err := c.checkAccess(ctx, key, OpGet); err != nil {
+		c.RUnlock()
+		return nil, err
+	}
+	if c.topK != nil {
+		c.topK.Record(key)
+	}
+	if c.bypassed() {
+		c.RUnlock()
+		atomic.AddUint64(&c.stats.misses, 1)
+		return nil, ErrNotFound
+	}
 	item, ok := c.items[key]
-	if !ok || item.Expired() {
+	if ok && c.expired(item) && c.revalidate != nil && !c.refreshDisabled() && !c.removable(item) {
+		refreshing := item.refreshing
+		c.recordHit(item)
+		val := c.copyValue(item.Object)
 		c.RUnlock()
-		return nil, false
+		if !refreshing {
+			c.startRevalidate(key)
+		}
+		return val, nil
 	}
+	if !ok || c.expired(item) {
+		store, filter := c.store, c.keyFilter
+		c.RUnlock()
+		atomic.AddUint64(&c.stats.misses, 1)
+		if c.parent != nil {
+			if val, found := c.parent.Get(key); found {
+				return val, nil
+			}
+		}
+		if store != nil && (filter == nil || filter.MayContain(key)) {
+			return c.load(ctx, key, func() (interface{}, time.Duration, error) {
+				if s, ok := store.(ContextStore); ok {
+					return s.LoadContext(ctx, key)
+				}
+				return store.Load(key)
+			})
+		}
+		return nil, ErrNotFound
+	}
+	c.recordHit(item)
+	val := c.copyValue(item.Object)
 	c.RUnlock()
-	return item.Object, true
+	return val, nil
+}
+
+// recordHit update the access tracking of an item found by Get. The caller
+// must hold the lock, the read lock is enough.
+func (c *Cache) recordHit(item *Item) {
+	if c.maxItems > 0 && c.evictMode == EvictLeastRecentlyUsed || c.hotKeyWindow > 0 {
+		atomic.StoreInt64(&item.accessed, c.now().UnixNano())
+	}
+	if c.neverHit != nil || c.hotKeyWindow > 0 {
+		atomic.AddInt64(&item.hits, 1)
+	}
+	if c.hotKeyWindow > 0 {
+		c.recordHotKey(item)
+	}
+	atomic.AddUint64(&c.stats.hits, 1)
 }
 
 // Set add a new key or replace an exist key. If the dur is 0, we will
-// use the defaultExpiration.
+// use the defaultExpiration. Set does nothing if the key was deleted by
+// DeleteSoft and its tombstone is not expired yet, or if a new key does not
+// fit in a full cache with FullReject, see SetFullPolicy.
 func (c *Cache) Set(key interface{}, val interface{}, dur time.Duration) {
-	var t *time.Time
-	c.Lock()
-	if dur == 0 {
-		dur = c.defaultExpiration
+	c.SetSync(key, val, dur)
+}
+
+// set store a value written through the API of the cache and publish the
+// key to the peers, see InvalidationBus. The caller must hold the lock.
+func (c *Cache) set(key interface{}, val interface{}, dur time.Duration) error {
+	if err := c.fill(key, val, dur); err != nil {
+		return err
 	}
-	if dur > 0 {
-		tmp := time.Now().Add(dur)
-		t = &tmp
+	c.publishInvalidation(key)
+	return nil
+}
+
+// fill store a value like set, but without publishing the key, for the
+// values read from the Store or a WarmSource, which the peers can read too.
+func (c *Cache) fill(key interface{}, val interface{}, dur time.Duration) error {
+	val, err := c.limitValue(val)
+	if err != nil {
+		return err
 	}
-	c.items[key] = &Item{
-		Object:     val,
-		Expiration: t,
+	val = c.copyValue(val)
+	var item *Item
+	if dur = c.cappedTTL(dur); dur > 0 {
+		item = newExpiringItem(val, c.now().Add(dur))
+	} else {
+		item = &Item{Object: val}
 	}
-	c.Unlock()
+	if old, ok := c.items[key]; ok && c.onStateChange != nil {
+		if state := c.state(old); state == StateStale || state == StateRefreshing {
+			c.stateChanged(key, StateFresh)
+		}
+	}
+	if err := c.insert(key, item); err != nil {
+		return err
+	}
+	c.filterKey(key)
+	c.watchEvent(EventSet, key, val)
+	atomic.AddUint64(&c.stats.sets, 1)
+	return nil
 }
 
-// Delete a key-value pair if the key is existed.
+// insert store an item, evicting another one if the cache is full, or
+// return ErrFull if the FullPolicy does not evict. The caller must hold the
+// lock.
+func (c *Cache) insert(key interface{}, item *Item) error {
+	if c.full(key) {
+		if err := c.makeRoom(); err != nil {
+			return err
+		}
+	}
+	now := c.now()
+	if c.maxTTL > 0 {
+		// The items imported with their expiration are capped too.
+		if max := now.Add(c.maxTTL); item.Expiration == nil || item.Expiration.After(max) {
+			item.Expiration = &max
+		}
+	}
+	item.accessed = now.UnixNano()
+	if item.written == 0 {
+		item.written = item.accessed
+	}
+	item.created = item.accessed
+	if old, ok := c.items[key]; ok && !old.expiredAt(now) {
+		item.created = old.created
+	}
+	item.version = c.nextVersion()
+	c.items[key] = item
+	if item.Expiration != nil {
+		c.scheduleExpiry(key, *item.Expiration)
+	}
+	c.stats.observe(len(c.items), 0)
+	return nil
+}
+
+// Delete a key-value pair if the key is existed. See DeleteSync for a
+// write-through cache.
 func (c *Cache) Delete(key interface{}) {
+	c.DeleteSync(key)
+}
+
+// OnEvicted set a function called with the key and value of the items
+// removed from the cache: deleted, expired, evicted or dropped by Close,
+// but not replaced. The function is called without the lock held, so it may
+// use the cache. Set to nil to disable.
+func (c *Cache) OnEvicted(f func(interface{}, interface{})) {
 	c.Lock()
-	delete(c.items, key)
+	c.onEvicted = f
 	c.Unlock()
 }
 
-// Delete all cache.
+// removed queue an item removed from the cache for the eviction callback.
+// The caller must hold the lock and release it by unlockAndNotify.
+func (c *Cache) removed(key interface{}, item *Item) {
+	c.removedAs(EventDelete, key, item)
+}
+
+// removedAs works like removed, reporting the removal to the watchers of
+// the key as an event of type typ.
+func (c *Cache) removedAs(typ EventType, key interface{}, item *Item) {
+	c.changes++
+	c.signalRoom()
+	if c.onEvicted != nil {
+		c.evicted = append(c.evicted, keyValue{key, item.Object})
+	}
+	c.watchEvent(typ, key, item.Object)
+	if (typ == EventExpire || typ == EventEvict) && len(c.removalSubs) > 0 {
+		c.removals = append(c.removals, Event{Type: typ, Key: key, Value: item.Object, At: c.now()})
+	}
+}
+
+// unlockAndNotify release the lock, then call the eviction callback for the
+// items removed while it was held.
+func (c *Cache) unlockAndNotify() {
+	evicted, f, limit := c.evicted, c.onEvicted, c.evictLimit
+	changes, onChange := c.stateChanges, c.onStateChange
+	removals, subs := c.removals, c.removalSubs
+	c.evicted, c.stateChanges, c.removals = nil, nil, nil
+	if c.degraded {
+		evicted, removals = nil, nil
+	}
+	c.Unlock()
+	for _, sc := range changes {
+		onChange(sc.key, sc.state)
+	}
+	for _, kv := range evicted {
+		if limit == nil || limit.allow() {
+			f(kv.key, kv.value)
+		}
+	}
+	for _, ev := range removals {
+		for _, sub := range subs {
+			sub.f(ev)
+		}
+	}
+}
+
+// Delete all cache, tombstones included. The eviction callback is not
+// called, unless SetFlushEvicts is on, see FlushFunc. With an access check,
+// the keys denied OpDelete are kept, with the tombstones and fences.
 func (c *Cache) Flush() {
+	c.flush()
+}
+
+// flush delete all cache like Flush, and return the items deleted.
+func (c *Cache) flush() map[interface{}]*Item {
 	c.Lock()
-	c.items = map[interface{}]*Item{}
-	c.Unlock()
+	items, kept := c.items, map[interface{}]*Item{}
+	if c.accessCheck != nil {
+		items = make(map[interface{}]*Item, len(c.items))
+		for k, v := range c.items {
+			if c.access(k, OpDelete) == nil {
+				items[k] = v
+			} else {
+				kept[k] = v
+			}
+		}
+	}
+	if c.flushEvicts {
+		for k, v := range items {
+			c.removed(k, v)
+		}
+	} else {
+		for key := range c.watchers {
+			if item, ok := items[key]; ok {
+				c.watchEvent(EventDelete, key, item.Object)
+			}
+		}
+	}
+	c.items = kept
+	c.changes++
+	if len(kept) == 0 {
+		if c.wheel != nil {
+			c.wheel = newTimingWheel(c.wheel.tick, c.now())
+		}
+		c.tombstones = nil
+		c.fences = nil
+	}
+	c.signalRoom()
+	c.unlockAndNotify()
+	return items
 }
 
 // Add a number to a key-value pair.
 func (c *Cache) Increment(key interface{}, x int64) error {
+	if !c.keyAllowed(key) {
+		return fmt.Errorf("Item %s not found", key)
+	}
 	c.Lock()
+	if err := c.access(key, OpSet); err != nil {
+		c.Unlock()
+		return err
+	}
 	val, ok := c.items[key]
-	if !ok || val.Expired() {
+	if !ok || val.expiredAt(c.now()) {
 		c.Unlock()
 		return fmt.Errorf("Item %s not found", key)
 	}
+	val = c.writable(key, val)
 	switch val.Object.(type) {
 	case int:
 		val.Object = val.Object.(int) + int(x)
@@ -135,18 +513,30 @@ func (c *Cache) Increment(key interface{}, x int64) error {
 		c.Unlock()
 		return fmt.Errorf("The value type error")
 	}
+	val.written = c.now().UnixNano()
+	val.version = c.nextVersion()
+	c.watchEvent(EventSet, key, val.Object)
+	c.publishInvalidation(key)
 	c.Unlock()
 	return nil
 }
 
 // Sub a number to a key-value pair.
 func (c *Cache) Decrement(key interface{}, x int64) error {
+	if !c.keyAllowed(key) {
+		return fmt.Errorf("Item %s not found", key)
+	}
 	c.Lock()
+	if err := c.access(key, OpSet); err != nil {
+		c.Unlock()
+		return err
+	}
 	val, ok := c.items[key]
-	if !ok || val.Expired() {
+	if !ok || val.expiredAt(c.now()) {
 		c.Unlock()
 		return fmt.Errorf("Item %s not found", key)
 	}
+	val = c.writable(key, val)
 	switch val.Object.(type) {
 	case int:
 		val.Object = val.Object.(int) - int(x)
@@ -174,6 +564,10 @@ func (c *Cache) Decrement(key interface{}, x int64) error {
 		c.Unlock()
 		return fmt.Errorf("The value type error")
 	}
+	val.written = c.now().UnixNano()
+	val.version = c.nextVersion()
+	c.watchEvent(EventSet, key, val.Object)
+	c.publishInvalidation(key)
 	c.Unlock()
 	return nil
 }
@@ -186,28 +580,83 @@ func (c *Cache) ItemCount() int {
 	return counts
 }
 
-// Delete all expired items.
-func (c *Cache) DeleteExpired() {
+// Delete all expired items past their stale window, and the expired
+// tombstones. It returns the number of items deleted, which are also given
+// to the eviction callback, see OnEvicted, and to SubscribeExpired.
+func (c *Cache) DeleteExpired() int {
+	var expired []Expiration
+	deleted := 0
 	c.Lock()
 	for k, v := range c.items {
-		if v.Expired() {
-			delete(c.items, k)
+		if c.deleteIfExpired(k, v, &expired) {
+			deleted++
 		}
 	}
-	c.Unlock()
+	c.deleteExpiredTombstones()
+	c.notifyExpired(expired)
+	c.unlockAndNotify()
+	return deleted
+}
+
+// deleteIfExpired delete an item if it is expired past its stale window,
+// and return true if it did. The expiration is appended to expired for the
+// subscribers. The caller must hold the lock.
+func (c *Cache) deleteIfExpired(k interface{}, v *Item, expired *[]Expiration) bool {
+	if !c.expired(v) {
+		return false
+	}
+	if !c.removable(v) {
+		if !v.staleNotified && c.onStateChange != nil {
+			v = c.writable(k, v)
+			v.staleNotified = true
+			c.stateChanged(k, StateStale)
+		}
+		return false
+	}
+	delete(c.items, k)
+	atomic.AddUint64(&c.stats.expired, 1)
+	c.recordRemoval(k, v)
+	c.removedAs(EventExpire, k, v)
+	c.stateChanged(k, StateExpired)
+	if len(c.expirySubs) > 0 {
+		*expired = append(*expired, Expiration{Key: k, Value: v.Object, At: *v.Expiration})
+	}
+	return true
+}
+
+// BoundedCache is the common interface of the caches in this package which
+// hold a limited number of entries and evict some of them when full.
+type BoundedCache interface {
+	Add(key interface{}, value interface{})
+	Get(key interface{}) (interface{}, bool)
+	Remove(key interface{})
+	Len() int
+	Clear()
+	SetMaxEntries(max int) error
 }
 
 // The LRUCache is a goroutine-safe cache.
 type LRUCache struct {
 	sync.RWMutex
 	maxEntries int
+	defaultTTL time.Duration
 	items      map[interface{}]*list.Element
 	cacheList  *list.List
+	admission  AdmissionPolicy
+	maxWeight  int64
+	weight     int64
+	stats      *statCounters
 }
 
 type entry struct {
-	key   interface{}
-	value interface{}
+	key        interface{}
+	value      interface{}
+	expiration *time.Time
+	weight     int64
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return e.expiration != nil && e.expiration.Before(now)
 }
 
 // NewLRU create a LRUCache with max size. The size is 0 means no limit.
@@ -219,44 +668,146 @@ func NewLRU(size int) (*LRUCache, error) {
 		maxEntries: size,
 		items:      make(map[interface{}]*list.Element, size),
 		cacheList:  list.New(),
+		stats:      &statCounters{},
+	}
+	return lru, nil
+}
+
+// NewExpirableLRU create a LRUCache with max size whose entries also expire
+// after defaultTTL. If the defaultTTL is less than 1, entries added without
+// an explicit TTL never expire. Expired entries are skipped by Get and purged
+// lazily, or all at once by DeleteExpired.
+func NewExpirableLRU(size int, defaultTTL time.Duration) (*LRUCache, error) {
+	lru, err := NewLRU(size)
+	if err != nil {
+		return nil, err
 	}
+	lru.defaultTTL = defaultTTL
 	return lru, nil
 }
 
 // Add a new key-value pair to the LRUCache.
 func (c *LRUCache) Add(key interface{}, value interface{}) {
+	c.AddWithTTL(key, value, 0)
+}
+
+// AddWithTTL add a new key-value pair which expires after ttl. If the ttl
+// is 0, the default TTL of the LRUCache is used, and if it is less than 0
+// the entry never expires.
+func (c *LRUCache) AddWithTTL(key interface{}, value interface{}, ttl time.Duration) {
+	c.add(key, value, ttl, 1)
+}
+
+// AddWithWeight add a new key-value pair weighing weight, for example its
+// size in bytes. Entries added by Add weigh 1. If the LRUCache has a max
+// weight, the oldest entries are evicted until the total weight fits it; an
+// entry heavier than the max weight is not kept at all.
+func (c *LRUCache) AddWithWeight(key interface{}, value interface{}, weight int64) {
+	c.add(key, value, 0, weight)
+}
+
+func (c *LRUCache) add(key interface{}, value interface{}, ttl time.Duration, weight int64) {
+	var t *time.Time
+	if ttl == 0 {
+		ttl = c.defaultTTL
+	}
+	if ttl > 0 {
+		tmp := time.Now().Add(ttl)
+		t = &tmp
+	}
 	c.Lock()
 	defer c.Unlock()
+	if c.admission != nil {
+		c.admission.Record(key)
+	}
+	if c.maxWeight > 0 && weight > c.maxWeight {
+		if ent, hit := c.items[key]; hit {
+			c.removeElement(ent)
+			atomic.AddUint64(&c.stats.evictions, 1)
+		}
+		return
+	}
 	if ent, hit := c.items[key]; hit {
+		atomic.AddUint64(&c.stats.sets, 1)
 		c.cacheList.MoveToFront(ent)
 		ent.Value.(*entry).value = value
+		ent.Value.(*entry).expiration = t
+		c.weight += weight - ent.Value.(*entry).weight
+		ent.Value.(*entry).weight = weight
+		c.evictOverweight()
+		c.stats.observe(c.cacheList.Len(), c.weight)
 		return
 	}
+	if c.admission != nil && c.maxEntries > 0 && c.cacheList.Len() >= c.maxEntries {
+		victim := c.cacheList.Back().Value.(*entry)
+		if !victim.expired(time.Now()) && !c.admission.Admit(key, victim.key) {
+			return
+		}
+	}
 	ent := &entry{
-		key:   key,
-		value: value,
+		key:        key,
+		value:      value,
+		expiration: t,
+		weight:     weight,
 	}
+	atomic.AddUint64(&c.stats.sets, 1)
 	entry := c.cacheList.PushFront(ent)
 	c.items[key] = entry
+	c.weight += weight
 
 	if c.maxEntries > 0 && c.cacheList.Len() > c.maxEntries {
 		c.removeOldestElement()
 	}
+	c.evictOverweight()
+	c.stats.observe(c.cacheList.Len(), c.weight)
 }
 
 // Get a value from the LRUCache. And a bool indicating
-// whether found or not.
+// whether found or not. An expired entry is removed and reported as not found.
 func (c *LRUCache) Get(key interface{}) (interface{}, bool) {
-	c.RLock()
-	defer c.RUnlock()
+	c.Lock()
+	defer c.Unlock()
+	if c.admission != nil {
+		c.admission.Record(key)
+	}
 
 	if ent, hit := c.items[key]; hit {
+		if ent.Value.(*entry).expired(time.Now()) {
+			c.removeElement(ent)
+			atomic.AddUint64(&c.stats.expired, 1)
+			atomic.AddUint64(&c.stats.misses, 1)
+			return nil, false
+		}
 		c.cacheList.MoveToFront(ent)
+		atomic.AddUint64(&c.stats.hits, 1)
+		return ent.Value.(*entry).value, true
+	}
+	atomic.AddUint64(&c.stats.misses, 1)
+	return nil, false
+}
+
+// Peek return a value from the LRUCache without updating the recency of
+// the entry. And a bool indicating whether found or not.
+func (c *LRUCache) Peek(key interface{}) (interface{}, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	if ent, hit := c.items[key]; hit && !ent.Value.(*entry).expired(time.Now()) {
 		return ent.Value.(*entry).value, true
 	}
 	return nil, false
 }
 
+// Contains return true if the key is in the LRUCache and not expired,
+// without updating the recency of the entry.
+func (c *LRUCache) Contains(key interface{}) bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	ent, hit := c.items[key]
+	return hit && !ent.Value.(*entry).expired(time.Now())
+}
+
 // Remove a key-value pair in LRUCache. If the key is not existed,
 // nothing will happen.
 func (c *LRUCache) Remove(key interface{}) {
@@ -265,10 +816,12 @@ func (c *LRUCache) Remove(key interface{}) {
 
 	if ent, hit := c.items[key]; hit {
 		c.removeElement(ent)
+		atomic.AddUint64(&c.stats.deletes, 1)
 	}
 }
 
-// Return the number of key-value pair in LRUCache.
+// Return the number of key-value pair in LRUCache. Expired entries which
+// are not purged yet are counted too.
 func (c *LRUCache) Len() int {
 	c.RLock()
 	length := c.cacheList.Len()
@@ -281,9 +834,29 @@ func (c *LRUCache) Clear() {
 	c.Lock()
 	c.cacheList = list.New()
 	c.items = make(map[interface{}]*list.Element, c.maxEntries)
+	c.weight = 0
 	c.Unlock()
 }
 
+// DeleteExpired remove all expired entries from the LRUCache, and return
+// their number.
+func (c *LRUCache) DeleteExpired() int {
+	now := time.Now()
+	deleted := 0
+	c.Lock()
+	for e := c.cacheList.Back(); e != nil; {
+		prev := e.Prev()
+		if e.Value.(*entry).expired(now) {
+			c.removeElement(e)
+			deleted++
+			atomic.AddUint64(&c.stats.expired, 1)
+		}
+		e = prev
+	}
+	c.Unlock()
+	return deleted
+}
+
 // Resize the max limit.
 func (c *LRUCache) SetMaxEntries(max int) error {
 	if max < 0 {
@@ -295,15 +868,106 @@ func (c *LRUCache) SetMaxEntries(max int) error {
 	return nil
 }
 
+// SetAdmissionPolicy set the policy deciding whether a new key may evict an
+// entry when the LRUCache is full, for example a TinyLFU. A nil policy
+// admits every key.
+func (c *LRUCache) SetAdmissionPolicy(p AdmissionPolicy) {
+	c.Lock()
+	c.admission = p
+	c.Unlock()
+}
+
+// GetOldest return the least recently used entry of the LRUCache without
+// updating its recency. The ok is false if the LRUCache is empty.
+func (c *LRUCache) GetOldest() (key interface{}, value interface{}, ok bool) {
+	c.Lock()
+	defer c.Unlock()
+	if e := c.oldestElement(); e != nil {
+		ent := e.Value.(*entry)
+		return ent.key, ent.value, true
+	}
+	return nil, nil, false
+}
+
+// RemoveOldest remove the least recently used entry of the LRUCache and
+// return it. The ok is false if the LRUCache is empty.
+func (c *LRUCache) RemoveOldest() (key interface{}, value interface{}, ok bool) {
+	c.Lock()
+	defer c.Unlock()
+	if e := c.oldestElement(); e != nil {
+		c.removeElement(e)
+		atomic.AddUint64(&c.stats.deletes, 1)
+		ent := e.Value.(*entry)
+		return ent.key, ent.value, true
+	}
+	return nil, nil, false
+}
+
+// oldestElement return the least recently used element which is not
+// expired, removing the expired ones met on the way.
+func (c *LRUCache) oldestElement() *list.Element {
+	now := time.Now()
+	for e := c.cacheList.Back(); e != nil; e = c.cacheList.Back() {
+		if !e.Value.(*entry).expired(now) {
+			return e
+		}
+		c.removeElement(e)
+		atomic.AddUint64(&c.stats.expired, 1)
+	}
+	return nil
+}
+
+// NewWeightedLRU create a LRUCache whose limit is the total weight of its
+// entries instead of their number. See AddWithWeight.
+func NewWeightedLRU(maxWeight int64) (*LRUCache, error) {
+	lru, err := NewLRU(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := lru.SetMaxWeight(maxWeight); err != nil {
+		return nil, err
+	}
+	return lru, nil
+}
+
+// SetMaxWeight set the max total weight of the entries. The max is 0 means
+// no limit.
+func (c *LRUCache) SetMaxWeight(max int64) error {
+	if max < 0 {
+		return errors.New("The max weight must no less than 0")
+	}
+	c.Lock()
+	c.maxWeight = max
+	c.evictOverweight()
+	c.Unlock()
+	return nil
+}
+
+// Weight return the total weight of the entries in LRUCache.
+func (c *LRUCache) Weight() int64 {
+	c.RLock()
+	weight := c.weight
+	c.RUnlock()
+	return weight
+}
+
+func (c *LRUCache) evictOverweight() {
+	for c.maxWeight > 0 && c.weight > c.maxWeight {
+		c.removeOldestElement()
+	}
+}
+
 func (c *LRUCache) removeElement(e *list.Element) {
 	c.cacheList.Remove(e)
 	ent := e.Value.(*entry)
 	delete(c.items, ent.key)
+	c.weight -= ent.weight
 }
 
 func (c *LRUCache) removeOldestElement() {
 	ent := c.cacheList.Back()
 	if ent != nil {
 		c.removeElement(ent)
+		atomic.AddUint64(&c.stats.evictions, 1)
 	}
 }
@@ -186,6 +186,114 @@ func TestLRUCache(t *testing.T) {
 	}
 }
 
+func TestLRUPeek(t *testing.T) {
+	lru, _ := NewLRU(2)
+	lru.Add("1", 111)
+	lru.Add("2", 222)
+	val, hit := lru.Peek("1")
+	if !hit || val != 111 {
+		t.Error("I should peek the key")
+	}
+	lru.Add("3", 333)
+	if _, hit := lru.Peek("1"); hit {
+		t.Error("Peek must not update the recency")
+	}
+	if _, hit := lru.Peek("2"); !hit {
+		t.Error("I should peek the key")
+	}
+}
+
+func TestLRUContains(t *testing.T) {
+	lru, _ := NewLRU(2)
+	lru.Add("1", 111)
+	lru.Add("2", 222)
+	if !lru.Contains("1") {
+		t.Error("The key is in cache")
+	}
+	lru.Add("3", 333)
+	if lru.Contains("1") {
+		t.Error("Contains must not update the recency")
+	}
+	if lru.Contains("4") {
+		t.Error("The key is not in cache")
+	}
+}
+
+func TestLRUOldest(t *testing.T) {
+	lru, _ := NewLRU(0)
+	if _, _, ok := lru.GetOldest(); ok {
+		t.Error("The lru cache is empty")
+	}
+	lru.Add("1", 111)
+	lru.Add("2", 222)
+	lru.Get("1")
+	key, val, ok := lru.GetOldest()
+	if !ok || key != "2" || val != 222 {
+		t.Error("You get a wrong oldest entry")
+	}
+	key, _, _ = lru.RemoveOldest()
+	if key != "2" || lru.Len() != 1 {
+		t.Error("The oldest entry must be removed")
+	}
+	lru.RemoveOldest()
+	if _, _, ok := lru.RemoveOldest(); ok {
+		t.Error("The lru cache is empty")
+	}
+}
+
+func TestWeightedLRU(t *testing.T) {
+	if _, err := NewWeightedLRU(-1); err == nil {
+		t.Error("Impossiable!")
+	}
+	lru, _ := NewWeightedLRU(100)
+	lru.AddWithWeight("1", []byte("a"), 40)
+	lru.AddWithWeight("2", []byte("b"), 40)
+	lru.Add("3", 333)
+	if lru.Weight() != 81 {
+		t.Error("You get a wrong weight", lru.Weight())
+	}
+	lru.AddWithWeight("4", []byte("d"), 30)
+	if lru.Contains("1") || lru.Weight() != 71 {
+		t.Error("The oldest entry must be evicted to fit the max weight")
+	}
+	lru.AddWithWeight("2", []byte("b"), 10)
+	if lru.Weight() != 41 {
+		t.Error("Updating an entry must update the weight", lru.Weight())
+	}
+	lru.AddWithWeight("big", []byte("big"), 200)
+	if lru.Contains("big") || lru.Weight() != 41 {
+		t.Error("An entry heavier than the max weight must not be kept")
+	}
+	lru.Clear()
+	if lru.Weight() != 0 {
+		t.Error("Now, the lru cache is cleared")
+	}
+}
+
+func TestExpirableLRU(t *testing.T) {
+	lru, err := NewExpirableLRU(2, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lru.Add("1", 111)
+	lru.AddWithTTL("2", 222, -1)
+	if _, hit := lru.Get("1"); !hit {
+		t.Error("The key is not expired yet")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, hit := lru.Get("1"); hit {
+		t.Error("The key is expired, you should not get")
+	}
+	if val, hit := lru.Get("2"); !hit || val != 222 {
+		t.Error("The key never expires")
+	}
+	lru.AddWithTTL("3", 333, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if lru.DeleteExpired() != 1 || lru.Len() != 1 {
+		t.Error("Only the key without TTL should be left")
+	}
+}
+
 func ExampleCache() {
 	c := New(0, 0)
 	c.Set("1", 1111, 0)
@@ -221,3 +329,23 @@ func ExampleLRUCache() {
 	// Output:
 	// Not hit key 1
 }
+
+func BenchmarkCacheSetWithTTL(b *testing.B) {
+	b.StopTimer()
+	tc := New(0, 0)
+	b.ReportAllocs()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tc.Set("key", "values", time.Hour)
+	}
+}
+
+func BenchmarkCacheSetWithoutTTL(b *testing.B) {
+	b.StopTimer()
+	tc := New(0, 0)
+	b.ReportAllocs()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tc.Set("key", "values", -1)
+	}
+}
@@ -0,0 +1,15 @@
+package cache
+
+// NewChild create a cache overlaying parent: Get falls through to the parent
+// when the child misses, while Set, Delete and every other method only
+// touch the child. The parent is never written through the child, so a
+// request- or tenant-scoped child can share a base cache without copying
+// it. The child has the default expiration of the parent and no janitor.
+func NewChild(parent *Cache) *Cache {
+	parent.RLock()
+	defaultExpiration := parent.defaultExpiration
+	parent.RUnlock()
+	c := New(defaultExpiration, 0)
+	c.parent = parent
+	return c
+}
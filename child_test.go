@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewChild(t *testing.T) {
+	parent := New(time.Minute, 0)
+	parent.Set("shared", 1, 0)
+	parent.Set("overlaid", 2, 0)
+	child := NewChild(parent)
+	child.Set("overlaid", 20, 0)
+	child.Set("own", 3, 0)
+	if val, found := child.Get("shared"); !found || val != 1 {
+		t.Error("The child must fall through to the parent")
+	}
+	if val, _ := child.Get("overlaid"); val != 20 {
+		t.Error("The child value must overlay the parent one")
+	}
+	if _, found := parent.Get("own"); found {
+		t.Error("The child must not write to the parent")
+	}
+	child.Delete("overlaid")
+	if val, _ := child.Get("overlaid"); val != 2 {
+		t.Error("Deleting from the child must uncover the parent value")
+	}
+	if child.ItemCount() != 1 {
+		t.Error("The child only counts its own items")
+	}
+	grandchild := NewChild(child)
+	if val, found := grandchild.Get("shared"); !found || val != 1 {
+		t.Error("The lookup must go up to the root cache")
+	}
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrChunkMissing is returned when a chunk of a chunked value is missing,
+// like in a value which was not stored by SetChunked.
+var ErrChunkMissing = errors.New("A chunk of the value is missing")
+
+// chunkedValue is the value of a key stored by SetChunked. Its fields are
+// exported so it can be saved with gob, like the other values.
+type chunkedValue struct {
+	Length    int
+	ChunkSize int
+	Chunks    [][]byte
+}
+
+// Size return the number of bytes of the value, see Sizer.
+func (v *chunkedValue) Size() int64 {
+	return int64(v.Length)
+}
+
+// SetChunked store data split in chunks of chunkSize bytes, in a single
+// item of the key. A large value does not need a single allocation to be
+// read back, and a range can be read alone with GetRange. The item is set
+// for dur, like Set, and counts as one item, see SetMaxItems. If it is not
+// stored, see SetFullPolicy and SetMaxValueSize, the error is returned.
+func (c *Cache) SetChunked(key interface{}, data []byte, chunkSize int, dur time.Duration) error {
+	if chunkSize < 1 {
+		return errors.New("The chunk size must greater than 0")
+	}
+	v := &chunkedValue{
+		Length:    len(data),
+		ChunkSize: chunkSize,
+		Chunks:    make([][]byte, 0, (len(data)+chunkSize-1)/chunkSize),
+	}
+	for off := 0; off < len(data); off += chunkSize {
+		end := off + chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := make([]byte, end-off)
+		copy(chunk, data[off:end])
+		v.Chunks = append(v.Chunks, chunk)
+	}
+	if !c.keyAllowed(key) {
+		return nil
+	}
+	c.Lock()
+	if err := c.access(key, OpSet); err != nil {
+		c.Unlock()
+		return err
+	}
+	var err error
+	if !c.tombstoned(key) {
+		err = c.set(key, v, dur)
+	}
+	c.unlockAndNotify()
+	return err
+}
+
+// GetChunked return the whole value stored by SetChunked, and a bool
+// indicating whether it was found with all its chunks.
+func (c *Cache) GetChunked(key interface{}) ([]byte, bool) {
+	v, ok := c.chunked(key)
+	if !ok {
+		return nil, false
+	}
+	data, err := v.read(0, v.Length)
+	return data, err == nil
+}
+
+// GetRange return n bytes of the value stored by SetChunked from offset
+// off, copying only the chunks of the range. The range is truncated at
+// the end of the value. It returns ErrNotFound if the key is not found,
+// and ErrChunkMissing if a chunk of the range is.
+func (c *Cache) GetRange(key interface{}, off, n int) ([]byte, error) {
+	v, ok := c.chunked(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if off < 0 || n < 0 {
+		return nil, errors.New("The range must not be negative")
+	}
+	return v.read(off, n)
+}
+
+// DeleteChunked delete a value stored by SetChunked. It is Delete, as the
+// chunks are stored in the item of the key.
+func (c *Cache) DeleteChunked(key interface{}) {
+	c.Delete(key)
+}
+
+func (c *Cache) chunked(key interface{}) (*chunkedValue, bool) {
+	v, found := c.Get(key)
+	if !found {
+		return nil, false
+	}
+	cv, ok := v.(*chunkedValue)
+	return cv, ok && cv.ChunkSize > 0
+}
+
+// read return n bytes of the value from offset off, truncated at its end.
+func (v *chunkedValue) read(off, n int) ([]byte, error) {
+	if off > v.Length {
+		off = v.Length
+	}
+	if off+n > v.Length {
+		n = v.Length - off
+	}
+	data := make([]byte, 0, n)
+	for i := off / v.ChunkSize; len(data) < n; i++ {
+		if i >= len(v.Chunks) {
+			return nil, ErrChunkMissing
+		}
+		chunk := v.Chunks[i]
+		start := 0
+		if i == off/v.ChunkSize {
+			start = off - i*v.ChunkSize
+		}
+		if start > len(chunk) {
+			return nil, ErrChunkMissing
+		}
+		end := len(chunk)
+		if rest := n - len(data); end-start > rest {
+			end = start + rest
+		}
+		if end == start {
+			return nil, ErrChunkMissing
+		}
+		data = append(data, chunk[start:end]...)
+	}
+	return data, nil
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestChunked(t *testing.T) {
+	c := New(0, 0)
+	data := []byte("0123456789abcdefghij")
+	if err := c.SetChunked("blob", data, 8, 0); err != nil {
+		t.Fatal(err)
+	}
+	if c.ItemCount() != 1 {
+		t.Error("The chunks must be stored in the item of the key", c.ItemCount())
+	}
+	if got, found := c.GetChunked("blob"); !found || !bytes.Equal(got, data) {
+		t.Error("You get a wrong value", string(got))
+	}
+	tests := []struct {
+		off, n int
+		want   string
+	}{
+		{0, 3, "012"},
+		{6, 5, "6789a"},
+		{16, 10, "ghij"},
+		{25, 5, ""},
+	}
+	for _, test := range tests {
+		if got, err := c.GetRange("blob", test.off, test.n); err != nil || string(got) != test.want {
+			t.Errorf("GetRange(%d, %d) = %q, %v", test.off, test.n, got, err)
+		}
+	}
+	c.Set("broken", &chunkedValue{Length: 20, ChunkSize: 8, Chunks: [][]byte{[]byte("01234567")}}, 0)
+	if _, err := c.GetRange("broken", 6, 5); err != ErrChunkMissing {
+		t.Error("You get a wrong error", err)
+	}
+	c.SetChunked("blob", []byte("short"), 8, 0)
+	if got, _ := c.GetChunked("blob"); string(got) != "short" {
+		t.Error("You get a wrong value", string(got))
+	}
+	c.DeleteChunked("blob")
+	if _, err := c.GetRange("blob", 0, 1); err != ErrNotFound {
+		t.Error("Now, the value is deleted")
+	}
+}
+
+func TestChunkedSaveLoad(t *testing.T) {
+	c := New(0, 0)
+	data := []byte("0123456789abcdefghij")
+	c.SetChunked("blob", data, 8, 0)
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	c2 := New(0, 0)
+	if err := c2.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, found := c2.GetChunked("blob"); !found || !bytes.Equal(got, data) {
+		t.Error("You get a wrong value from the saved cache", string(got))
+	}
+	var keys []interface{}
+	c2.Range(func(key, value interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) != 1 || keys[0] != "blob" {
+		t.Error("Range must only visit the key of the chunked value", keys)
+	}
+	buf.Reset()
+	if err := c.ExportJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := New(0, 0).ImportJSON(&buf); err != nil {
+		t.Error("The exported chunked value must be imported", err)
+	}
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ClockCache is a goroutine-safe cache using the CLOCK (second-chance)
+// eviction algorithm. A hit only sets a reference bit on the entry, so Get
+// runs under a read lock and never reorders anything; when the cache is full
+// a clock hand sweeps the entries, clearing reference bits, and evicts the
+// first entry which was not referenced since the last sweep.
+type ClockCache struct {
+	sync.RWMutex
+	items map[interface{}]int
+	slots []clockSlot
+	free  []int
+	hand  int
+}
+
+type clockSlot struct {
+	key        interface{}
+	value      interface{}
+	used       bool
+	referenced uint32
+}
+
+// NewClock create a ClockCache with max size. The size must be greater
+// than 0.
+func NewClock(size int) (*ClockCache, error) {
+	if size <= 0 {
+		return nil, errors.New("The size of Clock Cache must greater than 0")
+	}
+	c := &ClockCache{}
+	c.reset(size)
+	return c, nil
+}
+
+// Add a new key-value pair to the ClockCache.
+func (c *ClockCache) Add(key interface{}, value interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if idx, hit := c.items[key]; hit {
+		c.slots[idx].value = value
+		atomic.StoreUint32(&c.slots[idx].referenced, 1)
+		return
+	}
+	var idx int
+	if n := len(c.free); n > 0 {
+		idx = c.free[n-1]
+		c.free = c.free[:n-1]
+	} else {
+		idx = c.victim()
+		delete(c.items, c.slots[idx].key)
+	}
+	c.slots[idx] = clockSlot{key: key, value: value, used: true}
+	c.items[key] = idx
+}
+
+// Get a value from the ClockCache. And a bool indicating
+// whether found or not.
+func (c *ClockCache) Get(key interface{}) (interface{}, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	if idx, hit := c.items[key]; hit {
+		slot := &c.slots[idx]
+		if atomic.LoadUint32(&slot.referenced) == 0 {
+			atomic.StoreUint32(&slot.referenced, 1)
+		}
+		return slot.value, true
+	}
+	return nil, false
+}
+
+// Remove a key-value pair in ClockCache. If the key is not existed,
+// nothing will happen.
+func (c *ClockCache) Remove(key interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if idx, hit := c.items[key]; hit {
+		delete(c.items, key)
+		c.slots[idx] = clockSlot{}
+		c.free = append(c.free, idx)
+	}
+}
+
+// Return the number of key-value pair in ClockCache.
+func (c *ClockCache) Len() int {
+	c.RLock()
+	length := len(c.items)
+	c.RUnlock()
+	return length
+}
+
+// Delete all entry in the ClockCache. But the max size will hold.
+func (c *ClockCache) Clear() {
+	c.Lock()
+	c.reset(len(c.slots))
+	c.Unlock()
+}
+
+// Resize the max limit. The limit must be greater than 0. If the cache holds
+// more entries than the new limit, entries are evicted by the clock hand.
+func (c *ClockCache) SetMaxEntries(max int) error {
+	if max <= 0 {
+		return errors.New("The max limit of entryies must greater than 0")
+	}
+	c.Lock()
+	defer c.Unlock()
+	for len(c.items) > max {
+		idx := c.victim()
+		delete(c.items, c.slots[idx].key)
+		c.slots[idx] = clockSlot{}
+	}
+	old := c.slots
+	c.reset(max)
+	c.free = c.free[:0]
+	for _, slot := range old {
+		if slot.used {
+			idx := len(c.items)
+			c.slots[idx] = slot
+			c.items[slot.key] = idx
+		}
+	}
+	for idx := max - 1; idx >= len(c.items); idx-- {
+		c.free = append(c.free, idx)
+	}
+	return nil
+}
+
+// victim advance the clock hand until it finds an used slot which was not
+// referenced, giving a second chance to the referenced ones.
+func (c *ClockCache) victim() int {
+	for {
+		slot := &c.slots[c.hand]
+		idx := c.hand
+		c.hand = (c.hand + 1) % len(c.slots)
+		if !slot.used {
+			continue
+		}
+		if atomic.LoadUint32(&slot.referenced) == 0 {
+			return idx
+		}
+		atomic.StoreUint32(&slot.referenced, 0)
+	}
+}
+
+func (c *ClockCache) reset(size int) {
+	c.items = make(map[interface{}]int, size)
+	c.slots = make([]clockSlot, size)
+	c.free = make([]int, 0, size)
+	for idx := size - 1; idx >= 0; idx-- {
+		c.free = append(c.free, idx)
+	}
+	c.hand = 0
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestClockCache(t *testing.T) {
+	if _, err := NewClock(0); err == nil {
+		t.Error("Impossiable!")
+	}
+	var c BoundedCache
+	c, err := NewClock(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add("1", 111)
+	c.Add("2", 222)
+	c.Get("1")
+	c.Add("3", 333)
+	if c.Len() != 2 {
+		t.Error("Now, there is only two values in cache")
+	}
+	if _, hit := c.Get("2"); hit {
+		t.Error("The value without second chance must be removed")
+	}
+	if val, hit := c.Get("1"); !hit || val != 111 {
+		t.Error("The referenced value must be kept")
+	}
+	c.Remove("1")
+	c.Add("4", 444)
+	if c.Len() != 2 {
+		t.Error("The removed slot must be reused")
+	}
+	if err := c.SetMaxEntries(1); err != nil {
+		t.Fatal(err)
+	}
+	if c.Len() != 1 {
+		t.Error("The clock cache must be shrunk to the new limit")
+	}
+	c.SetMaxEntries(3)
+	c.Add("5", 555)
+	c.Add("6", 666)
+	if c.Len() != 3 {
+		t.Error("Now, the len of clock cache must be 3")
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Error("Now, the clock cache is cleared")
+	}
+}
+
+func TestClockCacheConcurrentGet(t *testing.T) {
+	c, _ := NewClock(16)
+	for i := 0; i < 16; i++ {
+		c.Add(i, i)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Get(i % 16)
+			}
+		}()
+	}
+	wg.Wait()
+	if c.Len() != 16 {
+		t.Error("Get must not change the content")
+	}
+}
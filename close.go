@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"io"
+)
+
+// Close stop the janitor and remove every item from the cache, calling the
+// eviction callback for each of them.
+func (c *Cache) Close() {
+	c.CloseAndSave(nil)
+}
+
+// CloseAndSave stop the janitor, write the items to w with Save if w is not
+// nil, then remove every item from the cache, calling the eviction callback
+// for each of them, so a graceful shutdown does not drop state silently.
+// The items are removed even if Save fails, and its error is returned.
+func (c *Cache) CloseAndSave(w io.Writer) error {
+	if j := c.getJanitor(); j != nil {
+		j.Stop()
+	}
+	var err error
+	if w != nil {
+		err = c.Save(w)
+	}
+	c.Lock()
+	items := c.items
+	c.items = map[interface{}]*Item{}
+	for k, v := range items {
+		c.removed(k, v)
+	}
+	c.unlockAndNotify()
+	return err
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestOnEvicted(t *testing.T) {
+	c := New(0, 0)
+	evicted := map[interface{}]interface{}{}
+	c.OnEvicted(func(k, v interface{}) {
+		evicted[k] = v
+		// The callback runs without the lock, it may use the cache.
+		c.ItemCount()
+	})
+	c.Set("deleted", 1, 0)
+	c.Set("expired", 2, time.Millisecond)
+	c.Set("replaced", 3, 0)
+	c.Set("replaced", 4, 0)
+	c.Delete("deleted")
+	time.Sleep(2 * time.Millisecond)
+	c.DeleteExpired()
+	if len(evicted) != 2 || evicted["deleted"] != 1 || evicted["expired"] != 2 {
+		t.Error("You get wrong evicted items", evicted)
+	}
+}
+
+func TestCloseAndSave(t *testing.T) {
+	c := New(0, time.Millisecond)
+	var evicted []interface{}
+	c.OnEvicted(func(k, v interface{}) {
+		evicted = append(evicted, k)
+	})
+	c.Set("key", "val", 0)
+	var buf bytes.Buffer
+	if err := c.CloseAndSave(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 1 || c.ItemCount() != 0 {
+		t.Error("Every item must be evicted on close")
+	}
+	c2 := New(0, 0)
+	c2.Load(&buf)
+	if val, _ := c2.Get("key"); val != "val" {
+		t.Error("The items must be saved on close")
+	}
+	c2.Close()
+	c2.Close()
+}
@@ -0,0 +1,193 @@
+// Command cachebench replays a workload against a cache of this package and
+// prints its hit rate, memory and latency, to check the sizing of a cache
+// before deploying it.
+//
+// The workload is either a recorded trace, a file with one key per line, or
+// a synthetic one drawing keys from a uniform or zipfian distribution:
+//
+//	cachebench -policy s3fifo -size 10000 -trace keys.txt
+//	cachebench -policy lru -size 10000 -ttl 1m -keys 100000 -ops 1000000 -zipf 1.1
+//
+// Every key missed by Get is added, like a read-through cache.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+type config struct {
+	policy string
+	size   int
+	ttl    time.Duration
+}
+
+type result struct {
+	ops    int
+	hits   int
+	memory uint64
+	p50    time.Duration
+	p99    time.Duration
+}
+
+// getAdder is the part of a cache the benchmark uses.
+type getAdder interface {
+	Get(key interface{}) (interface{}, bool)
+	Add(key interface{}, value interface{})
+}
+
+// kvCache adapts a Cache with a max number of items.
+type kvCache struct {
+	c   *cache.Cache
+	ttl time.Duration
+}
+
+func (k kvCache) Get(key interface{}) (interface{}, bool) {
+	return k.c.Get(key)
+}
+
+func (k kvCache) Add(key interface{}, value interface{}) {
+	k.c.Set(key, value, k.ttl)
+}
+
+func newCache(cfg config) (getAdder, error) {
+	if cfg.ttl != 0 && cfg.policy != "lru" && cfg.policy != "cache" {
+		return nil, fmt.Errorf("The policy %s does not support a TTL", cfg.policy)
+	}
+	switch cfg.policy {
+	case "cache":
+		c := cache.New(0, 0)
+		if err := c.SetMaxItems(cfg.size, 0, cache.EvictLeastRecentlyUsed); err != nil {
+			return nil, err
+		}
+		ttl := cfg.ttl
+		if ttl == 0 {
+			ttl = -1
+		}
+		return kvCache{c, ttl}, nil
+	case "lru":
+		return cache.NewExpirableLRU(cfg.size, cfg.ttl)
+	}
+	return cache.NewBounded(cache.Policy(cfg.policy), cfg.size)
+}
+
+// run replay the keys given by next until it returns false.
+func run(c getAdder, next func() (string, bool)) result {
+	var res result
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	var latencies []time.Duration
+	for key, ok := next(); ok; key, ok = next() {
+		start := time.Now()
+		if _, hit := c.Get(key); hit {
+			res.hits++
+		} else {
+			c.Add(key, key)
+		}
+		latencies = append(latencies, time.Since(start))
+		res.ops++
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	if after.HeapAlloc > before.HeapAlloc {
+		res.memory = after.HeapAlloc - before.HeapAlloc
+	}
+	runtime.KeepAlive(c)
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		res.p50 = latencies[len(latencies)*50/100]
+		res.p99 = latencies[len(latencies)*99/100]
+	}
+	return res
+}
+
+// traceKeys read one key per line from r.
+func traceKeys(r io.Reader) func() (string, bool) {
+	scanner := bufio.NewScanner(r)
+	return func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		return scanner.Text(), true
+	}
+}
+
+// syntheticKeys draw ops keys among n. The keys are uniform if s is 0, and
+// zipfian with the exponent s otherwise, which must be greater than 1.
+func syntheticKeys(n, ops int, s float64, seed int64) (func() (string, bool), error) {
+	if n < 1 || ops < 0 {
+		return nil, errors.New("The number of keys must greater than 0")
+	}
+	rnd := rand.New(rand.NewSource(seed))
+	draw := func() uint64 { return uint64(rnd.Intn(n)) }
+	if s != 0 {
+		if s <= 1 {
+			return nil, errors.New("The zipf exponent must greater than 1")
+		}
+		draw = rand.NewZipf(rnd, s, 1, uint64(n-1)).Uint64
+	}
+	return func() (string, bool) {
+		if ops == 0 {
+			return "", false
+		}
+		ops--
+		return strconv.FormatUint(draw(), 10), true
+	}, nil
+}
+
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.policy, "policy", "lru", "eviction policy: cache, lru, lfu, 2q, clock, sieve, s3fifo, fifo, random or small")
+	flag.IntVar(&cfg.size, "size", 10000, "max number of entries")
+	flag.DurationVar(&cfg.ttl, "ttl", 0, "entry TTL, for the cache and lru policies")
+	trace := flag.String("trace", "", "file with one key per line, instead of a synthetic workload")
+	keys := flag.Int("keys", 100000, "number of distinct synthetic keys")
+	ops := flag.Int("ops", 1000000, "number of synthetic operations")
+	zipf := flag.Float64("zipf", 1.1, "zipf exponent of the synthetic keys, 0 for uniform keys")
+	seed := flag.Int64("seed", 1, "seed of the synthetic keys")
+	flag.Parse()
+
+	c, err := newCache(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	var next func() (string, bool)
+	if *trace != "" {
+		f, err := os.Open(*trace)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		next = traceKeys(f)
+	} else if next, err = syntheticKeys(*keys, *ops, *zipf, *seed); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	res := run(c, next)
+	hitRate := 0.0
+	if res.ops > 0 {
+		hitRate = float64(res.hits) / float64(res.ops)
+	}
+	fmt.Printf("policy:   %s\n", cfg.policy)
+	fmt.Printf("size:     %d\n", cfg.size)
+	fmt.Printf("ops:      %d\n", res.ops)
+	fmt.Printf("hit rate: %.4f\n", hitRate)
+	fmt.Printf("memory:   %d bytes\n", res.memory)
+	fmt.Printf("p50:      %v\n", res.p50)
+	fmt.Printf("p99:      %v\n", res.p99)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunTrace(t *testing.T) {
+	for _, policy := range []string{"cache", "lru", "lfu", "2q", "clock", "sieve", "s3fifo"} {
+		c, err := newCache(config{policy: policy, size: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := run(c, traceKeys(strings.NewReader("a\nb\na\na\nb\n")))
+		if res.ops != 5 || res.hits != 3 {
+			t.Errorf("%s: you get %d hits in %d ops", policy, res.hits, res.ops)
+		}
+	}
+}
+
+func TestNewCache(t *testing.T) {
+	if _, err := newCache(config{policy: "nope", size: 2}); err == nil {
+		t.Error("Impossiable!")
+	}
+	if _, err := newCache(config{policy: "sieve", size: 2, ttl: time.Minute}); err == nil {
+		t.Error("The sieve policy does not support a TTL")
+	}
+}
+
+func TestSyntheticKeys(t *testing.T) {
+	if _, err := syntheticKeys(10, 10, 0.5, 1); err == nil {
+		t.Error("Impossiable!")
+	}
+	next, _ := syntheticKeys(10, 100, 1.1, 1)
+	c, _ := newCache(config{policy: "lru", size: 10})
+	if res := run(c, next); res.ops != 100 || res.hits < 90 {
+		t.Errorf("You get %d hits in %d ops", res.hits, res.ops)
+	}
+}
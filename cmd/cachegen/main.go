@@ -0,0 +1,125 @@
+// Command cachegen generates a strongly-typed wrapper around a Cache, for
+// use with go:generate:
+//
+//	//go:generate cachegen -type UserCache -key int64 -value *User -output user_cache.go
+//
+// generates a UserCache with NewUserCache(c *cache.Cache) and the methods
+// Get(key int64) (*User, bool), Set(key int64, val *User, dur time.Duration)
+// and Delete(key int64). The method names can be changed with -get, -set
+// and -delete, and the packages used by the key and value types imported
+// with -import.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+)
+
+type params struct {
+	Package string
+	Type    string
+	Key     string
+	Value   string
+	Imports []string
+	Get     string
+	Set     string
+	Delete  string
+}
+
+var tmpl = template.Must(template.New("wrapper").Parse(`// Code generated by cachegen; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"time"
+
+	"github.com/maemual/go-cache"
+{{range .Imports}}	"{{.}}"
+{{end}})
+
+// {{.Type}} is a Cache of {{.Value}} by {{.Key}}.
+type {{.Type}} struct {
+	c *cache.Cache
+}
+
+// New{{.Type}} wrap c, which must only be used through the {{.Type}}.
+func New{{.Type}}(c *cache.Cache) *{{.Type}} {
+	return &{{.Type}}{c: c}
+}
+
+// Cache return the wrapped Cache.
+func (w *{{.Type}}) Cache() *cache.Cache {
+	return w.c
+}
+
+// {{.Get}} return the value of the key, and a bool indicating whether the
+// key was found.
+func (w *{{.Type}}) {{.Get}}(key {{.Key}}) ({{.Value}}, bool) {
+	if v, ok := w.c.Get(key); ok {
+		if val, ok := v.({{.Value}}); ok {
+			return val, true
+		}
+	}
+	var zero {{.Value}}
+	return zero, false
+}
+
+// {{.Set}} add a new key or replace an exist key, see Cache.Set.
+func (w *{{.Type}}) {{.Set}}(key {{.Key}}, val {{.Value}}, dur time.Duration) {
+	w.c.Set(key, val, dur)
+}
+
+// {{.Delete}} the key if it is existed.
+func (w *{{.Type}}) {{.Delete}}(key {{.Key}}) {
+	w.c.Delete(key)
+}
+`))
+
+// generate return the formatted source of the wrapper.
+func generate(p params) ([]byte, error) {
+	if p.Package == "" || p.Type == "" || p.Key == "" || p.Value == "" {
+		return nil, errors.New("The package, type, key and value must be set")
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+func main() {
+	var p params
+	flag.StringVar(&p.Package, "package", os.Getenv("GOPACKAGE"), "package of the generated file, $GOPACKAGE by default")
+	flag.StringVar(&p.Type, "type", "", "name of the wrapper type")
+	flag.StringVar(&p.Key, "key", "", "type of the keys")
+	flag.StringVar(&p.Value, "value", "", "type of the values")
+	imports := flag.String("import", "", "comma separated import paths needed by the key and value types")
+	flag.StringVar(&p.Get, "get", "Get", "name of the get method")
+	flag.StringVar(&p.Set, "set", "Set", "name of the set method")
+	flag.StringVar(&p.Delete, "delete", "Delete", "name of the delete method")
+	output := flag.String("output", "", "output file, the lower-case type followed by .go by default")
+	flag.Parse()
+
+	if *imports != "" {
+		p.Imports = strings.Split(*imports, ",")
+	}
+	src, err := generate(p)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cachegen:", err)
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = strings.ToLower(p.Type) + ".go"
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "cachegen:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := generate(params{
+		Package: "users",
+		Type:    "UserCache",
+		Key:     "int64",
+		Value:   "*model.User",
+		Imports: []string{"example.com/model"},
+		Get:     "Lookup",
+		Set:     "Store",
+		Delete:  "Forget",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"package users",
+		"\"example.com/model\"",
+		"func NewUserCache(c *cache.Cache) *UserCache",
+		"func (w *UserCache) Lookup(key int64) (*model.User, bool)",
+		"func (w *UserCache) Store(key int64, val *model.User, dur time.Duration)",
+		"func (w *UserCache) Forget(key int64)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("%s is not generated in:\n%s", want, src)
+		}
+	}
+	if _, err := generate(params{Package: "users"}); err == nil {
+		t.Error("Impossiable!")
+	}
+}
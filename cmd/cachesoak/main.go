@@ -0,0 +1,173 @@
+// Command cachesoak runs a mixed workload against a Cache for a long time
+// while checking invariants, to validate the stability of a configuration:
+//
+//   - Get never returns a value which expired before the call;
+//   - the cache never holds more items than its max, and its stats agree
+//     with its item count;
+//   - the goroutines and the heap do not grow from one check to the next
+//     beyond a tolerance.
+//
+// For example:
+//
+//	cachesoak -duration 4h -workers 16 -keys 100000 -max-items 50000 -ttl 1s -cleanup 100ms
+//
+// It exits with status 1 at the first violation.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+type config struct {
+	duration      time.Duration
+	workers       int
+	keys          int
+	maxItems      int
+	ttl           time.Duration
+	cleanup       time.Duration
+	checkInterval time.Duration
+	// heapGrowth is the max growth of the heap between the first check and
+	// any later one, as a factor.
+	heapGrowth float64
+	// goroutineGrowth is the max number of goroutines more than at the
+	// first check.
+	goroutineGrowth int
+}
+
+type report struct {
+	ops    uint64
+	checks int
+}
+
+// value is stored with the deadline of its item in UnixNano, to detect a
+// Get returning an expired value. The deadline is taken after the Set
+// returns, so it is never before the expiration of the item, and it is 0
+// until then or if the item does not expire.
+type value struct {
+	deadline int64
+}
+
+func soak(cfg config) (report, error) {
+	var rep report
+	c := cache.New(cfg.ttl, cfg.cleanup)
+	defer c.Close()
+	if err := c.SetMaxItems(cfg.maxItems, 0, cache.EvictLeastRecentlyUsed); err != nil {
+		return rep, err
+	}
+
+	var ops uint64
+	var violation atomic.Value
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.workers; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				key := rnd.Intn(cfg.keys)
+				switch op := rnd.Intn(10); {
+				case op < 6:
+					before := time.Now()
+					if v, found := c.Get(key); found {
+						if d := atomic.LoadInt64(&v.(*value).deadline); d != 0 && d < before.UnixNano() {
+							violation.Store(fmt.Errorf("Get(%d) returned a value expired at %v", key, time.Unix(0, d)))
+						}
+					}
+				case op < 9:
+					ttl := time.Duration(rnd.Int63n(int64(2*cfg.ttl) + 1))
+					if ttl == 0 {
+						ttl = -1
+					}
+					v := &value{}
+					c.Set(key, v, ttl)
+					if ttl > 0 {
+						atomic.StoreInt64(&v.deadline, time.Now().Add(ttl).UnixNano())
+					}
+				default:
+					c.Delete(key)
+				}
+				atomic.AddUint64(&ops, 1)
+			}
+		}(int64(i))
+	}
+
+	var baseHeap uint64
+	var baseGoroutines int
+	deadline := time.Now().Add(cfg.duration)
+	ticker := time.NewTicker(cfg.checkInterval)
+	defer ticker.Stop()
+	var err error
+	for err == nil && time.Now().Before(deadline) {
+		<-ticker.C
+		if v := violation.Load(); v != nil {
+			err = v.(error)
+			break
+		}
+		if n := c.ItemCount(); cfg.maxItems > 0 && n > cfg.maxItems {
+			err = fmt.Errorf("The cache holds %d items, more than %d", n, cfg.maxItems)
+			break
+		}
+		if s := c.Stats(); s.Evictions > s.Sets {
+			err = fmt.Errorf("The cache evicted %d items but only %d were set", s.Evictions, s.Sets)
+			break
+		}
+		runtime.GC()
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+		goroutines := runtime.NumGoroutine()
+		if rep.checks == 0 {
+			baseHeap, baseGoroutines = mem.HeapAlloc, goroutines
+		} else if float64(mem.HeapAlloc) > float64(baseHeap)*cfg.heapGrowth {
+			err = fmt.Errorf("The heap grew from %d to %d bytes", baseHeap, mem.HeapAlloc)
+		} else if goroutines > baseGoroutines+cfg.goroutineGrowth {
+			err = fmt.Errorf("The goroutines grew from %d to %d", baseGoroutines, goroutines)
+		}
+		rep.checks++
+	}
+	close(stop)
+	wg.Wait()
+	if err == nil {
+		if v := violation.Load(); v != nil {
+			err = v.(error)
+		}
+	}
+	rep.ops = atomic.LoadUint64(&ops)
+	return rep, err
+}
+
+func main() {
+	var cfg config
+	flag.DurationVar(&cfg.duration, "duration", time.Hour, "how long to run")
+	flag.IntVar(&cfg.workers, "workers", runtime.NumCPU(), "number of goroutines using the cache")
+	flag.IntVar(&cfg.keys, "keys", 100000, "number of distinct keys")
+	flag.IntVar(&cfg.maxItems, "max-items", 50000, "max number of items, 0 means no limit")
+	flag.DurationVar(&cfg.ttl, "ttl", time.Second, "mean TTL of the items")
+	flag.DurationVar(&cfg.cleanup, "cleanup", 100*time.Millisecond, "cleanup interval")
+	flag.DurationVar(&cfg.checkInterval, "check", 10*time.Second, "interval of the invariant checks")
+	flag.Float64Var(&cfg.heapGrowth, "heap-growth", 2, "max growth factor of the heap")
+	flag.IntVar(&cfg.goroutineGrowth, "goroutine-growth", 10, "max number of extra goroutines")
+	flag.Parse()
+
+	start := time.Now()
+	rep, err := soak(cfg)
+	fmt.Printf("ops: %d, checks: %d, elapsed: %v\n", rep.ops, rep.checks, time.Since(start).Round(time.Second))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "violation:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSoak(t *testing.T) {
+	rep, err := soak(config{
+		duration:        300 * time.Millisecond,
+		workers:         4,
+		keys:            1000,
+		maxItems:        500,
+		ttl:             10 * time.Millisecond,
+		cleanup:         5 * time.Millisecond,
+		checkInterval:   50 * time.Millisecond,
+		heapGrowth:      10,
+		goroutineGrowth: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.ops == 0 || rep.checks < 2 {
+		t.Errorf("You get a wrong report %+v", rep)
+	}
+}
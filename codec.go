@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// Codec turn values into bytes and back, for SaveCodec and LoadCodec. Plug
+// in msgpack, protobuf or any other format by implementing it.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// GobCodec is a Codec using encoding/gob. The concrete types of the keys
+// and values must be registered with gob.Register to be decoded; SaveCodec
+// registers them when saving.
+type GobCodec struct{}
+
+// Marshal v with gob.
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+// Unmarshal data into v with gob.
+func (GobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// JSONCodec is a Codec using encoding/json. Keys and values are decoded as
+// the generic encoding/json types, see ImportJSON.
+type JSONCodec struct{}
+
+// Marshal v with json.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal data into v with json.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// SaveCodec write the items of the cache to w as a slice of Entry encoded
+// by codec, keeping their expiration and write time.
+func (c *Cache) SaveCodec(w io.Writer, codec Codec) (err error) {
+	c.RLock()
+	entries := make([]Entry, 0, len(c.items))
+	for k, v := range c.items {
+		if c.access(k, OpGet) != nil {
+			continue
+		}
+		entries = append(entries, Entry{
+			Key:        k,
+			Value:      v.Object,
+			Expiration: v.Expiration,
+			Written:    time.Unix(0, v.written),
+		})
+	}
+	c.RUnlock()
+	if _, ok := codec.(GobCodec); ok {
+		defer func() {
+			if x := recover(); x != nil {
+				err = fmt.Errorf("Error registering item types with gob: %v", x)
+			}
+		}()
+		for _, e := range entries {
+			registerType(e.Key)
+			registerType(e.Value)
+		}
+	}
+	data, err := codec.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// LoadCodec add the items written by SaveCodec with the same codec to the
+// cache, like Load. It returns an error without loading anything if a key
+// is decoded as a type which can not be a key, like JSONCodec decodes the
+// structs as maps.
+func (c *Cache) LoadCodec(r io.Reader, codec Codec) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var entries []Entry
+	if err := codec.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := decodedKeyError(e.Key); err != nil {
+			return err
+		}
+	}
+	c.Import(entries, KeepExisting)
+	return nil
+}
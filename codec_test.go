@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadCodec(t *testing.T) {
+	for _, codec := range []Codec{GobCodec{}, JSONCodec{}} {
+		c := New(0, 0)
+		c.Set("a", "x", -1)
+		c.Set("b", "y", time.Hour)
+		var buf bytes.Buffer
+		if err := c.SaveCodec(&buf, codec); err != nil {
+			t.Fatal(err)
+		}
+		c2 := New(0, 0)
+		if err := c2.LoadCodec(&buf, codec); err != nil {
+			t.Fatal(err)
+		}
+		if val, _ := c2.Get("b"); val != "y" || c2.ItemCount() != 2 {
+			t.Errorf("%T: you get wrong loaded items", codec)
+		}
+		info, _ := c2.GetItemInfo("b")
+		orig, _ := c.GetItemInfo("b")
+		if info.Expiration == nil || !info.Written.Equal(orig.Written) {
+			t.Errorf("%T: the metadata must be kept", codec)
+		}
+	}
+}
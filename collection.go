@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"errors"
+)
+
+// errCollectionType is returned by the collection operations when the
+// value of the key does not have the type of the operation.
+var errCollectionType = errors.New("The value type error")
+
+// Append add data at the end of the string or []byte value of the key, data
+// having the same type. If the key is not found, data is stored with the
+// default expiration. The value is never modified in place, so the callers
+// which got it before are not affected.
+func (c *Cache) Append(key interface{}, data interface{}) error {
+	return c.concat(key, data, false)
+}
+
+// Prepend add data at the beginning of the string or []byte value of the
+// key, like Append.
+func (c *Cache) Prepend(key interface{}, data interface{}) error {
+	return c.concat(key, data, true)
+}
+
+func (c *Cache) concat(key interface{}, data interface{}, front bool) error {
+	return c.update(key, func(old interface{}, found bool) (interface{}, error) {
+		if !found {
+			switch data.(type) {
+			case string, []byte:
+				return data, nil
+			}
+			return nil, errCollectionType
+		}
+		switch v := old.(type) {
+		case string:
+			d, ok := data.(string)
+			if !ok {
+				return nil, errCollectionType
+			}
+			if front {
+				return d + v, nil
+			}
+			return v + d, nil
+		case []byte:
+			d, ok := data.([]byte)
+			if !ok {
+				return nil, errCollectionType
+			}
+			if front {
+				v, d = d, v
+			}
+			b := make([]byte, 0, len(v)+len(d))
+			return append(append(b, v...), d...), nil
+		}
+		return nil, errCollectionType
+	})
+}
+
+// ListPush add vals at the end of the list of the key, a []interface{}, and
+// return its new length. If the key is not found, a list is stored with
+// the default expiration. The list is copied, so it costs its length.
+func (c *Cache) ListPush(key interface{}, vals ...interface{}) (int, error) {
+	n := 0
+	err := c.update(key, func(old interface{}, found bool) (interface{}, error) {
+		var list []interface{}
+		if found {
+			var ok bool
+			if list, ok = old.([]interface{}); !ok {
+				return nil, errCollectionType
+			}
+		}
+		l := make([]interface{}, 0, len(list)+len(vals))
+		l = append(append(l, list...), vals...)
+		n = len(l)
+		return l, nil
+	})
+	return n, err
+}
+
+// ListRange return a copy of the elements of the list of the key from
+// start to stop excluded, like a slice expression, but clamped to the
+// length of the list. A stop less than 0 means the end of the list. It
+// returns nil if the key is not found.
+func (c *Cache) ListRange(key interface{}, start, stop int) ([]interface{}, error) {
+	val, found := c.Get(key)
+	if !found {
+		return nil, nil
+	}
+	list, ok := val.([]interface{})
+	if !ok {
+		return nil, errCollectionType
+	}
+	if stop < 0 || stop > len(list) {
+		stop = len(list)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start >= stop {
+		return nil, nil
+	}
+	return append([]interface{}(nil), list[start:stop]...), nil
+}
+
+// SetAdd add the members to the set of the key, a map[interface{}]struct{},
+// and return the number of members which were not in it. If the key is not
+// found, a set is stored with the default expiration. The set is copied, so
+// it costs its size.
+func (c *Cache) SetAdd(key interface{}, members ...interface{}) (int, error) {
+	added := 0
+	err := c.update(key, func(old interface{}, found bool) (interface{}, error) {
+		var set map[interface{}]struct{}
+		if found {
+			var ok bool
+			if set, ok = old.(map[interface{}]struct{}); !ok {
+				return nil, errCollectionType
+			}
+		}
+		s := make(map[interface{}]struct{}, len(set)+len(members))
+		for m := range set {
+			s[m] = struct{}{}
+		}
+		added = 0
+		for _, m := range members {
+			if _, ok := s[m]; !ok {
+				s[m] = struct{}{}
+				added++
+			}
+		}
+		return s, nil
+	})
+	return added, err
+}
+
+// SetMembers return the members of the set of the key in random order, or
+// nil if the key is not found.
+func (c *Cache) SetMembers(key interface{}) ([]interface{}, error) {
+	val, found := c.Get(key)
+	if !found {
+		return nil, nil
+	}
+	set, ok := val.(map[interface{}]struct{})
+	if !ok {
+		return nil, errCollectionType
+	}
+	members := make([]interface{}, 0, len(set))
+	for m := range set {
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+// update replace the value of the key by the value returned by f, called
+// with the current value and whether it was found, under the lock, so no
+// other write can come in between. The expiration of the key is kept. If
+// it is not found, the value is stored with the default expiration.
+func (c *Cache) update(key interface{}, f func(old interface{}, found bool) (interface{}, error)) error {
+	if !c.keyAllowed(key) {
+		return nil
+	}
+	c.Lock()
+	defer c.unlockAndNotify()
+	if err := c.access(key, OpSet); err != nil {
+		return err
+	}
+	item, ok := c.items[key]
+	if !ok || c.expired(item) {
+		val, err := f(nil, false)
+		if err != nil {
+			return err
+		}
+		if c.tombstoned(key) {
+			return nil
+		}
+		return c.set(key, val, 0)
+	}
+	val, err := f(item.Object, true)
+	if err != nil {
+		return err
+	}
+	if val, err = c.limitValue(val); err != nil {
+		return err
+	}
+	item = c.writable(key, item)
+	item.Object = val
+	item.written = c.now().UnixNano()
+	item.version = c.nextVersion()
+	c.watchEvent(EventSet, key, val)
+	c.publishInvalidation(key)
+	return nil
+}
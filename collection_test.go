@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAppend(t *testing.T) {
+	c := New(0, 0)
+	c.Append("s", "b")
+	c.Append("s", "c")
+	c.Prepend("s", "a")
+	if val, _ := c.Get("s"); val != "abc" {
+		t.Error("You get a wrong value", val)
+	}
+	c.Set("b", []byte("b"), time.Hour)
+	old, _ := c.Get("b")
+	c.Append("b", []byte("c"))
+	c.Prepend("b", []byte("a"))
+	if val, _ := c.Get("b"); string(val.([]byte)) != "abc" || string(old.([]byte)) != "b" {
+		t.Error("You get a wrong value", val, old)
+	}
+	if info, _ := c.GetItemInfo("b"); info.Expiration == nil {
+		t.Error("The expiration must be kept")
+	}
+	if c.Append("s", []byte("x")) == nil || c.Append("n", 1) == nil {
+		t.Error("Impossiable!")
+	}
+}
+
+func TestListPush(t *testing.T) {
+	c := New(0, 0)
+	if n, _ := c.ListPush("l", 1, 2); n != 2 {
+		t.Error("You get a wrong length", n)
+	}
+	if n, _ := c.ListPush("l", 3); n != 3 {
+		t.Error("You get a wrong length", n)
+	}
+	if l, _ := c.ListRange("l", 1, -1); len(l) != 2 || l[0] != 2 || l[1] != 3 {
+		t.Error("You get a wrong range", l)
+	}
+	if l, _ := c.ListRange("l", 2, 10); len(l) != 1 || l[0] != 3 {
+		t.Error("The range must be clamped", l)
+	}
+	if l, err := c.ListRange("x", 0, -1); l != nil || err != nil {
+		t.Error("Impossiable!")
+	}
+	c.Set("s", "s", 0)
+	if _, err := c.ListPush("s", 1); err == nil {
+		t.Error("The value is not a list")
+	}
+}
+
+func TestSetAdd(t *testing.T) {
+	c := New(0, 0)
+	if n, _ := c.SetAdd("s", "a", "b", "a"); n != 2 {
+		t.Error("You get a wrong number of added members", n)
+	}
+	if n, _ := c.SetAdd("s", "b", "c"); n != 1 {
+		t.Error("You get a wrong number of added members", n)
+	}
+	members, _ := c.SetMembers("s")
+	names := []string{}
+	for _, m := range members {
+		names = append(names, m.(string))
+	}
+	sort.Strings(names)
+	if len(names) != 3 || names[0] != "a" || names[2] != "c" {
+		t.Error("You get wrong members", names)
+	}
+}
+
+func TestListPushConcurrent(t *testing.T) {
+	c := New(0, 0)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				c.ListPush("l", i)
+			}
+		}()
+	}
+	wg.Wait()
+	if l, _ := c.ListRange("l", 0, -1); len(l) != 800 {
+		t.Error("The pushes must not be lost", len(l))
+	}
+}
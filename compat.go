@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// compatItem is the item of github.com/patrickmn/go-cache (formerly
+// pmylund/go-cache), whose expiration is in UnixNano and 0 means never.
+type compatItem struct {
+	Object     interface{}
+	Expiration int64
+}
+
+// compatItemV1 is the item of the older pmylund/go-cache releases.
+type compatItemV1 struct {
+	Object     interface{}
+	Expiration *time.Time
+}
+
+// SaveCompat write the items of the cache to w in the format of the Save
+// method of pmylund/go-cache, so the file can be loaded by that package.
+// Only the items with a string key are written, as that package does not
+// support other keys.
+func (c *Cache) SaveCompat(w io.Writer) (err error) {
+	enc := gob.NewEncoder(w)
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("Error registering item types with gob: %v", x)
+		}
+	}()
+	c.RLock()
+	items := make(map[string]compatItem, len(c.items))
+	for k, v := range c.items {
+		key, ok := k.(string)
+		if !ok || c.access(k, OpGet) != nil {
+			continue
+		}
+		registerType(v.Object)
+		item := compatItem{Object: v.Object}
+		if v.Expiration != nil {
+			item.Expiration = v.Expiration.UnixNano()
+		}
+		items[key] = item
+	}
+	c.RUnlock()
+	err = enc.Encode(&items)
+	return
+}
+
+// SaveFileCompat save the items of the cache to a file with SaveCompat.
+func (c *Cache) SaveFileCompat(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = c.SaveCompat(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadCompat add the items written by the Save method of pmylund/go-cache
+// to the cache, like Load. Both the current format and the one of the
+// releases storing the expiration as a *time.Time are supported.
+func (c *Cache) LoadCompat(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	loaded := map[interface{}]*Item{}
+	items := map[string]compatItem{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&items); err == nil {
+		for k, v := range items {
+			item := &Item{Object: v.Object}
+			if v.Expiration > 0 {
+				t := time.Unix(0, v.Expiration)
+				item.Expiration = &t
+			}
+			loaded[k] = item
+		}
+	} else {
+		itemsV1 := map[string]*compatItemV1{}
+		if errV1 := gob.NewDecoder(bytes.NewReader(data)).Decode(&itemsV1); errV1 != nil {
+			return err
+		}
+		for k, v := range itemsV1 {
+			loaded[k] = &Item{Object: v.Object, Expiration: v.Expiration}
+		}
+	}
+	return c.loadItems(loaded)
+}
+
+// LoadFileCompat load the items saved by the SaveFile method of
+// pmylund/go-cache.
+func (c *Cache) LoadFileCompat(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.LoadCompat(f)
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadCompat(t *testing.T) {
+	c := New(0, 0)
+	c.Set("a", 1, -1)
+	c.Set("b", "x", time.Hour)
+	c.Set(3, "not a string key", -1)
+	var buf bytes.Buffer
+	if err := c.SaveCompat(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// Decode as pmylund/go-cache does.
+	items := map[string]compatItem{}
+	if err := gob.NewDecoder(bytes.NewReader(buf.Bytes())).Decode(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items["a"].Expiration != 0 || items["b"].Expiration == 0 {
+		t.Error("You get wrong saved items", items)
+	}
+
+	c2 := New(0, 0)
+	if err := c2.LoadCompat(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := c2.Get("b"); val != "x" || c2.ItemCount() != 2 {
+		t.Error("You get wrong loaded items")
+	}
+}
+
+func TestLoadCompatV1(t *testing.T) {
+	exp := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	items := map[string]*compatItemV1{
+		"a": {Object: 1},
+		"b": {Object: 2, Expiration: &exp},
+		"c": {Object: 3, Expiration: &past},
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&items); err != nil {
+		t.Fatal(err)
+	}
+	c := New(0, 0)
+	if err := c.LoadCompat(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := c.Get("b"); val != 2 || c.ItemCount() != 2 {
+		t.Error("You get wrong loaded items")
+	}
+}
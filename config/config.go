@@ -0,0 +1,235 @@
+// Package config builds named caches from a declarative JSON file, so the
+// sizes, TTLs and policies can be tuned without changing code:
+//
+//	{
+//		"caches": {
+//			"sessions": {"policy": "cache", "size": 10000, "ttl": "30m", "cleanup_interval": "1m"},
+//			"pages": {"policy": "lru", "size": 1000, "ttl": "5m"},
+//			"users": {"policy": "s3fifo", "size": 5000}
+//		}
+//	}
+//
+// The policy "cache" builds a *cache.Cache, and the other policies, see
+// cache.Policies, build a cache.BoundedCache. The policy "none" builds a
+// cache.NopCache, which stores nothing, to disable a cache.
+//
+// FromFile lets environment variables override the parameters of a named
+// cache, to tune it at deploy time without a rebuild:
+//
+//	CACHE_<NAME>_SIZE=5000
+//	CACHE_<NAME>_TTL=10m
+//	CACHE_<NAME>_CLEANUP_INTERVAL=30s
+//
+// where <NAME> is the name of the cache in upper case, with the characters
+// other than letters and digits replaced by '_'.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+// Duration is a time.Duration written as a string like "1m30s" in the file.
+type Duration time.Duration
+
+// UnmarshalJSON parse a duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("The duration must be a string like \"1m30s\"")
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON write the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Spec describes one cache.
+type Spec struct {
+	// Policy is cache, none or one of cache.Policies: lru, lfu, 2q, clock,
+	// sieve, s3fifo, fifo, random or small.
+	Policy string `json:"policy"`
+	// Size is the max number of entries, 0 means no limit for the cache
+	// and lru policies. The other policies need a size.
+	Size int `json:"size"`
+	// TTL is the default expiration of the entries, for the cache and lru
+	// policies.
+	TTL Duration `json:"ttl"`
+	// CleanupInterval is the interval of the janitor deleting the expired
+	// items, for the cache policy.
+	CleanupInterval Duration `json:"cleanup_interval"`
+}
+
+// File is the content of a config file.
+type File struct {
+	Caches map[string]Spec `json:"caches"`
+}
+
+// FieldError is a validation error of a field of the config file.
+type FieldError struct {
+	// Field is the path of the field, like caches.pages.size.
+	Field string
+	Msg   string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Msg
+}
+
+// Caches are the caches built from a config file, by name.
+type Caches struct {
+	kv      map[string]*cache.Cache
+	bounded map[string]cache.BoundedCache
+}
+
+// Cache return the cache named name built with the cache policy.
+func (c *Caches) Cache(name string) (*cache.Cache, bool) {
+	kv, ok := c.kv[name]
+	return kv, ok
+}
+
+// Bounded return the cache named name built with another policy.
+func (c *Caches) Bounded(name string) (cache.BoundedCache, bool) {
+	b, ok := c.bounded[name]
+	return b, ok
+}
+
+// Names return the names of all the caches, sorted.
+func (c *Caches) Names() []string {
+	var names []string
+	for name := range c.kv {
+		names = append(names, name)
+	}
+	for name := range c.bounded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromFile read the config file at path and build its caches.
+func FromFile(path string) (*Caches, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := f.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	caches, err := Build(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return caches, nil
+}
+
+// Parse read a config file from r. Unknown fields are rejected, to catch
+// typos.
+func Parse(r io.Reader) (*File, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var f File
+	if err := dec.Decode(&f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Validate check every spec of f, returning a *FieldError for the first
+// wrong field.
+func (f *File) Validate() error {
+	names := make([]string, 0, len(f.Caches))
+	for name := range f.Caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := f.Caches[name].validate("caches." + name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s Spec) validate(path string) error {
+	switch s.Policy {
+	case "cache", "lru", "none":
+	case "lfu", "2q", "clock", "sieve", "s3fifo", "fifo", "random", "small":
+		if s.Size < 1 {
+			return &FieldError{path + ".size", "must greater than 0 for the policy " + s.Policy}
+		}
+		if s.TTL != 0 {
+			return &FieldError{path + ".ttl", "is not supported by the policy " + s.Policy}
+		}
+	case "":
+		return &FieldError{path + ".policy", "is required"}
+	default:
+		return &FieldError{path + ".policy", "unknown policy " + s.Policy}
+	}
+	if s.Size < 0 {
+		return &FieldError{path + ".size", "must no less than 0"}
+	}
+	if s.TTL < 0 {
+		return &FieldError{path + ".ttl", "must no less than 0"}
+	}
+	if s.CleanupInterval < 0 {
+		return &FieldError{path + ".cleanup_interval", "must no less than 0"}
+	}
+	if s.CleanupInterval != 0 && s.Policy != "cache" {
+		return &FieldError{path + ".cleanup_interval", "is only supported by the policy cache"}
+	}
+	return nil
+}
+
+// Build validate f and build its caches.
+func Build(f *File) (*Caches, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	caches := &Caches{
+		kv:      map[string]*cache.Cache{},
+		bounded: map[string]cache.BoundedCache{},
+	}
+	for name, s := range f.Caches {
+		if s.Policy == "cache" {
+			c := cache.New(time.Duration(s.TTL), time.Duration(s.CleanupInterval))
+			if err := c.SetMaxItems(s.Size, 0, cache.EvictNearestExpiry); err != nil {
+				return nil, &FieldError{"caches." + name + ".size", err.Error()}
+			}
+			caches.kv[name] = c
+			continue
+		}
+		b, err := newBounded(s)
+		if err != nil {
+			return nil, &FieldError{"caches." + name, err.Error()}
+		}
+		caches.bounded[name] = b
+	}
+	return caches, nil
+}
+
+func newBounded(s Spec) (cache.BoundedCache, error) {
+	if s.Policy == "lru" {
+		return cache.NewExpirableLRU(s.Size, time.Duration(s.TTL))
+	}
+	return cache.NewBounded(cache.Policy(s.Policy), s.Size)
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "caches.json")
+	ioutil.WriteFile(path, []byte(`{"caches": {
+		"sessions": {"policy": "cache", "size": 2, "ttl": "30m"},
+		"pages": {"policy": "lru", "size": 2, "ttl": "5m"},
+		"users": {"policy": "s3fifo", "size": 5},
+		"disabled": {"policy": "none"}
+	}}`), 0644)
+	caches, err := FromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := strings.Join(caches.Names(), ","); names != "disabled,pages,sessions,users" {
+		t.Errorf("You get wrong names %s", names)
+	}
+	sessions, ok := caches.Cache("sessions")
+	if !ok {
+		t.Fatal("The sessions cache must be built")
+	}
+	sessions.Set("a", 1, 0)
+	if info, _ := sessions.GetItemInfo("a"); info.Expiration == nil || info.Expiration.Sub(time.Now()) < 29*time.Minute {
+		t.Error("The default TTL must be 30m")
+	}
+	pages, ok := caches.Bounded("pages")
+	if !ok {
+		t.Fatal("The pages cache must be built")
+	}
+	pages.Add(1, 1)
+	pages.Add(2, 2)
+	pages.Add(3, 3)
+	if pages.Len() != 2 {
+		t.Error("The size of pages must be 2")
+	}
+	if _, ok := caches.Cache("pages"); ok {
+		t.Error("Impossiable!")
+	}
+	disabled, _ := caches.Bounded("disabled")
+	disabled.Add(1, 1)
+	if _, found := disabled.Get(1); found {
+		t.Error("The none policy must store nothing")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		json  string
+		field string
+	}{
+		{`{"caches": {"a": {"size": 1}}}`, "caches.a.policy"},
+		{`{"caches": {"a": {"policy": "arc"}}}`, "caches.a.policy"},
+		{`{"caches": {"a": {"policy": "sieve"}}}`, "caches.a.size"},
+		{`{"caches": {"a": {"policy": "lru", "size": -1}}}`, "caches.a.size"},
+		{`{"caches": {"a": {"policy": "clock", "size": 1, "ttl": "1m"}}}`, "caches.a.ttl"},
+		{`{"caches": {"a": {"policy": "lru", "cleanup_interval": "1m"}}}`, "caches.a.cleanup_interval"},
+	}
+	for _, test := range tests {
+		f, err := Parse(strings.NewReader(test.json))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Build(f)
+		if fe, ok := err.(*FieldError); !ok || fe.Field != test.field {
+			t.Errorf("%s: you get the error %v", test.json, err)
+		}
+	}
+	if _, err := Parse(strings.NewReader(`{"caches": {"a": {"polcy": "lru"}}}`)); err == nil {
+		t.Error("Unknown fields must be rejected")
+	}
+	if _, err := Parse(strings.NewReader(`{"caches": {"a": {"ttl": 5}}}`)); err == nil {
+		t.Error("The duration must be a string")
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix returns the prefix of the environment variables overriding the
+// cache named name, like CACHE_USER_SESSIONS_ for "user-sessions".
+func EnvPrefix(name string) string {
+	return "CACHE_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name) + "_"
+}
+
+// ApplyEnv override the specs of f by the variables found by lookup, which
+// is usually os.LookupEnv. A wrong value is returned as a *FieldError
+// whose Field is the variable.
+func (f *File) ApplyEnv(lookup func(key string) (string, bool)) error {
+	for name, s := range f.Caches {
+		prefix := EnvPrefix(name)
+		if v, ok := lookup(prefix + "SIZE"); ok {
+			size, err := strconv.Atoi(v)
+			if err != nil {
+				return &FieldError{prefix + "SIZE", "must be an integer"}
+			}
+			s.Size = size
+		}
+		if err := envDuration(lookup, prefix+"TTL", &s.TTL); err != nil {
+			return err
+		}
+		if err := envDuration(lookup, prefix+"CLEANUP_INTERVAL", &s.CleanupInterval); err != nil {
+			return err
+		}
+		f.Caches[name] = s
+	}
+	return nil
+}
+
+func envDuration(lookup func(key string) (string, bool), key string, d *Duration) error {
+	v, ok := lookup(key)
+	if !ok {
+		return nil
+	}
+	dur, err := time.ParseDuration(v)
+	if err != nil {
+		return &FieldError{key, "must be a duration like 1m30s"}
+	}
+	*d = Duration(dur)
+	return nil
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyEnv(t *testing.T) {
+	if prefix := EnvPrefix("user-sessions"); prefix != "CACHE_USER_SESSIONS_" {
+		t.Errorf("You get a wrong prefix %s", prefix)
+	}
+	env := map[string]string{
+		"CACHE_PAGES_SIZE": "10",
+		"CACHE_PAGES_TTL":  "1m",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+	f, _ := Parse(strings.NewReader(`{"caches": {"pages": {"policy": "lru", "size": 2}}}`))
+	if err := f.ApplyEnv(lookup); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.Caches["pages"]; s.Size != 10 || time.Duration(s.TTL) != time.Minute {
+		t.Errorf("You get a wrong spec %+v", s)
+	}
+	env["CACHE_PAGES_CLEANUP_INTERVAL"] = "soon"
+	if err, ok := f.ApplyEnv(lookup).(*FieldError); !ok || err.Field != "CACHE_PAGES_CLEANUP_INTERVAL" {
+		t.Error("The wrong variable must be reported")
+	}
+}
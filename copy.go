@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+)
+
+// SetCopier make the cache store a copy of the values made by f, and
+// return a copy of them to Get and the other reads, like GetOrSet, Range,
+// the transactions or the events of Watch, so the callers modifying a value
+// do not corrupt it for the other readers. GobCopy is a deep copy for the common
+// types. The values stored before are not copied. Set to nil to store and
+// return the values themselves, which is the default.
+func (c *Cache) SetCopier(f func(interface{}) interface{}) {
+	c.Lock()
+	c.copier = f
+	c.Unlock()
+}
+
+// copyValue return a copy of v made by the copier, or v if there is none.
+// The caller must hold the lock, the read lock is enough.
+func (c *Cache) copyValue(v interface{}) interface{} {
+	if c.copier == nil {
+		return v
+	}
+	return c.copier(v)
+}
+
+// GobCopy return a deep copy of v made by encoding it with gob. Like gob,
+// it only copies the exported fields of the structs, and returns v itself if
+// gob can not encode it, like a func or a channel. The values which can not
+// be modified, like numbers and strings, are returned as is.
+func GobCopy(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	t := reflect.TypeOf(v)
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32,
+		reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return v
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return v
+	}
+	p := reflect.New(t)
+	if err := gob.NewDecoder(&buf).DecodeValue(p); err != nil {
+		return v
+	}
+	return p.Elem().Interface()
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"testing"
+)
+
+type copyTestValue struct {
+	Name string
+	Tags []string
+}
+
+func TestSetCopier(t *testing.T) {
+	c := New(0, 0)
+	c.SetCopier(GobCopy)
+	v := &copyTestValue{Name: "a", Tags: []string{"x"}}
+	c.Set("a", v, 0)
+	v.Tags[0] = "changed by the writer"
+	got, _ := c.Get("a")
+	got.(*copyTestValue).Name = "changed by a reader"
+	again, _ := c.Get("a")
+	if cv := again.(*copyTestValue); cv.Name != "a" || cv.Tags[0] != "x" {
+		t.Error("The cached value must not be modified", cv)
+	}
+	c.Set("n", 1, 0)
+	if val, _ := c.Get("n"); val != 1 {
+		t.Error("Impossiable!")
+	}
+	c.SetCopier(nil)
+	c.Set("b", v, 0)
+	if val, _ := c.Get("b"); val != v {
+		t.Error("Now, the values are not copied")
+	}
+}
+
+func TestGobCopy(t *testing.T) {
+	m := map[string]int{"a": 1}
+	cp := GobCopy(m).(map[string]int)
+	cp["a"] = 2
+	if m["a"] != 1 {
+		t.Error("The map must be copied")
+	}
+	f := func() {}
+	if GobCopy(f) == nil || GobCopy(nil) != nil {
+		t.Error("Impossiable!")
+	}
+}
+
+func TestSetCopierReads(t *testing.T) {
+	c := New(0, 0)
+	c.SetCopier(GobCopy)
+	c.Set("a", &copyTestValue{Name: "a"}, 0)
+	got, loaded := c.GetOrSet("a", nil, 0)
+	if !loaded {
+		t.Fatal("Impossiable!")
+	}
+	got.(*copyTestValue).Name = "changed by GetOrSet"
+	c.Update(func(tx *Txn) error {
+		v, _ := tx.Get("a")
+		v.(*copyTestValue).Name = "changed by a transaction"
+		return nil
+	})
+	if v, _ := c.Get("a"); v.(*copyTestValue).Name != "a" {
+		t.Error("The cached value must not be modified", v)
+	}
+	ch, cancel := c.Watch("a")
+	defer cancel()
+	c.Set("a", &copyTestValue{Name: "a"}, 0)
+	(<-ch).Value.(*copyTestValue).Name = "changed by a watcher"
+	if v, _ := c.Get("a"); v.(*copyTestValue).Name != "a" {
+		t.Error("The cached value must not be modified", v)
+	}
+}
@@ -0,0 +1,31 @@
+package cache
+
+// SetDegraded switch the cache in or out of the degraded mode, meant for
+// the outages of the origin. While degraded:
+//
+//   - expired items are still returned by Get and kept by DeleteExpired, so
+//     the callers are served stale values instead of errors;
+//   - the eviction callback is not called, and the write-behind is paused,
+//     to spare the systems they write to.
+//
+// The items which expired during the outage are deleted by the first
+// DeleteExpired after it.
+func (c *Cache) SetDegraded(degraded bool) {
+	c.Lock()
+	c.degraded = degraded
+	c.wakeExpiryTimer()
+	c.Unlock()
+}
+
+// Degraded return true if the cache is in the degraded mode.
+func (c *Cache) Degraded() bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.degraded
+}
+
+// expired return true if the item must be treated as expired. The caller
+// must hold the lock.
+func (c *Cache) expired(item *Item) bool {
+	return !c.degraded && item.expiredAt(c.now())
+}
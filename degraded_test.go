@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDegraded(t *testing.T) {
+	c := New(0, 0)
+	calls := 0
+	c.OnEvicted(func(key, value interface{}) {
+		calls++
+	})
+	c.Set("a", 1, time.Millisecond)
+	c.Set("b", 2, -1)
+	c.SetDegraded(true)
+	if !c.Degraded() {
+		t.Error("Impossiable!")
+	}
+	time.Sleep(2 * time.Millisecond)
+	c.DeleteExpired()
+	if val, found := c.Get("a"); !found || val != 1 {
+		t.Error("The stale item must be served while degraded")
+	}
+	c.Delete("b")
+	if calls != 0 {
+		t.Error("The eviction callback must not be called while degraded")
+	}
+	c.SetDegraded(false)
+	if _, found := c.Get("a"); found {
+		t.Error("Now, the item is expired")
+	}
+	c.DeleteExpired()
+	if c.ItemCount() != 0 || calls != 1 {
+		t.Error("The expired item must be deleted after the outage")
+	}
+}
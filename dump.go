@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// opLog is a ring of the last operations of a cache.
+type opLog struct {
+	sync.Mutex
+	ops  []OpRecord
+	next int
+	full bool
+}
+
+// OpRecord is an operation recorded by RecordOps.
+type OpRecord struct {
+	At time.Time `json:"at"`
+	// Op is hit, miss, set or delete.
+	Op      string `json:"op"`
+	Key     string `json:"key"`
+	KeyHash uint64 `json:"key_hash"`
+	// Goroutine is the id of the goroutine which called the operation.
+	Goroutine uint64 `json:"goroutine"`
+}
+
+func (l *opLog) record(op string, key interface{}) {
+	r := OpRecord{
+		At:        time.Now(),
+		Op:        op,
+		Key:       fmt.Sprint(key),
+		KeyHash:   hashKey(key),
+		Goroutine: goroutineID(),
+	}
+	l.Lock()
+	l.ops[l.next] = r
+	l.next++
+	if l.next == len(l.ops) {
+		l.next, l.full = 0, true
+	}
+	l.Unlock()
+}
+
+// last return the operations from the oldest to the newest.
+func (l *opLog) last() []OpRecord {
+	l.Lock()
+	defer l.Unlock()
+	if !l.full {
+		return append([]OpRecord(nil), l.ops[:l.next]...)
+	}
+	return append(append([]OpRecord(nil), l.ops[l.next:]...), l.ops[:l.next]...)
+}
+
+// goroutineID parse the id of the current goroutine from its stack trace,
+// which begins with "goroutine 123 [".
+func goroutineID() uint64 {
+	var buf [32]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// RecordOps keep the last n Get (hit or miss), Set and Delete of the cache
+// for DumpForDebug and RecentOps. It costs a lock, a formatting of the key
+// and a stack trace for every operation. The n is 0 stops recording.
+func (c *Cache) RecordOps(n int) {
+	var log *opLog
+	if n > 0 {
+		log = &opLog{ops: make([]OpRecord, n)}
+	}
+	c.ops.Store(log)
+}
+
+// RecentOps return the operations recorded by RecordOps, oldest first, or
+// nil if they are not recorded.
+func (c *Cache) RecentOps() []OpRecord {
+	if log := c.opLog(); log != nil {
+		return log.last()
+	}
+	return nil
+}
+
+func (c *Cache) opLog() *opLog {
+	log, _ := c.ops.Load().(*opLog)
+	return log
+}
+
+// debugDump is the first line of a dump.
+type debugDump struct {
+	At    time.Time `json:"at"`
+	Items int       `json:"items"`
+	Stats Stats     `json:"stats"`
+}
+
+// debugItem is a line of a dump describing an item.
+type debugItem struct {
+	Key        string     `json:"key"`
+	Type       string     `json:"type"`
+	Size       int64      `json:"size"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+	Hits       int64      `json:"hits"`
+	State      string     `json:"state"`
+}
+
+// DumpForDebug write the state of the cache to w for a postmortem, as JSON
+// lines: a header with the time, the number of items and the stats, one
+// line per item with its key, the type name and estimated size of its
+// value, its expiration, hits and state, then one line per operation
+// recorded by RecordOps, oldest first. Hits are only counted with
+// TrackHotKeys or TrackNeverHit. The lock is held while the items are
+// written, so the dump is consistent.
+func (c *Cache) DumpForDebug(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	c.RLock()
+	err := enc.Encode(debugDump{At: time.Now(), Items: len(c.items), Stats: c.loadStats()})
+	for k, item := range c.items {
+		if err != nil {
+			break
+		}
+		err = enc.Encode(struct {
+			Item debugItem `json:"item"`
+		}{debugItem{
+			Key:        fmt.Sprint(k),
+			Type:       fmt.Sprintf("%T", item.Object),
+			Size:       sizeOf(k) + sizeOf(item.Object),
+			Expiration: item.Expiration,
+			Hits:       atomic.LoadInt64(&item.hits),
+			State:      c.state(item).String(),
+		}})
+	}
+	c.RUnlock()
+	if log := c.opLog(); log != nil {
+		for _, op := range log.last() {
+			if err != nil {
+				break
+			}
+			err = enc.Encode(struct {
+				Op OpRecord `json:"op"`
+			}{op})
+		}
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDumpForDebug(t *testing.T) {
+	c := New(0, 0)
+	c.RecordOps(2)
+	c.Set("a", "value", 0)
+	c.Get("a")
+	c.Get("b")
+	var buf bytes.Buffer
+	if err := c.DumpForDebug(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("You get a wrong dump:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[0], `"items":1`) || !strings.Contains(lines[1], `"key":"a","type":"string"`) {
+		t.Errorf("You get a wrong dump:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[2], `"op":"hit"`) || !strings.Contains(lines[3], `"op":"miss","key":"b"`) {
+		t.Errorf("The last 2 operations must be dumped:\n%s", buf.String())
+	}
+	c.RecordOps(0)
+	buf.Reset()
+	c.DumpForDebug(&buf)
+	if strings.Count(buf.String(), "\n") != 2 {
+		t.Error("Now, the operations are not recorded")
+	}
+}
+
+func TestRecentOps(t *testing.T) {
+	c := New(0, 0)
+	if c.RecentOps() != nil {
+		t.Error("The operations are not recorded by default")
+	}
+	c.RecordOps(2)
+	c.Set("a", 1, 0)
+	c.Delete("a")
+	c.Set("b", 2, 0)
+	ops := c.RecentOps()
+	if len(ops) != 2 || ops[0].Op != "delete" || ops[1].Op != "set" || ops[1].Key != "b" {
+		t.Fatalf("You get wrong operations: %v", ops)
+	}
+	if ops[0].KeyHash != hashKey("a") || ops[0].Goroutine == 0 {
+		t.Errorf("The key hash and the goroutine must be recorded: %v", ops[0])
+	}
+	done := make(chan uint64)
+	go func() {
+		c.Get("b")
+		done <- goroutineID()
+	}()
+	id := <-done
+	if ops := c.RecentOps(); ops[1].Goroutine != id || id == ops[0].Goroutine {
+		t.Error("The goroutine of the operation must be recorded")
+	}
+}
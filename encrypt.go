@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// ErrDecrypt is returned when an encrypted snapshot can not be decrypted,
+// because the key is wrong or the data was modified.
+var ErrDecrypt = errors.New("The snapshot can not be decrypted")
+
+// SaveEncrypted works like Save, but encrypts the snapshot with AES-GCM.
+// The key must be 16, 24 or 32 bytes long to select AES-128, AES-192 or
+// AES-256. The snapshot is authenticated, so LoadEncrypted detects any
+// modification.
+func (c *Cache) SaveEncrypted(w io.Writer, key []byte) error {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	_, err = w.Write(gcm.Seal(nonce, nonce, buf.Bytes(), nil))
+	return err
+}
+
+// SaveFileEncrypted save the items of the cache to a file with
+// SaveEncrypted.
+func (c *Cache) SaveFileEncrypted(path string, key []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = c.SaveEncrypted(f, key); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadEncrypted add the items written by SaveEncrypted to the cache, like
+// Load. It returns ErrDecrypt if the key is wrong or the data was modified.
+func (c *Cache) LoadEncrypted(r io.Reader, key []byte) error {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(data) < gcm.NonceSize() {
+		return ErrDecrypt
+	}
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return ErrDecrypt
+	}
+	return c.Load(bytes.NewReader(plain))
+}
+
+// LoadFileEncrypted load the items saved by SaveFileEncrypted.
+func (c *Cache) LoadFileEncrypted(path string, key []byte) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.LoadEncrypted(f, key)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadEncrypted(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	c := New(0, 0)
+	c.Set("token", "secret-value", 0)
+	var buf bytes.Buffer
+	if err := c.SaveEncrypted(&buf, []byte("short")); err == nil {
+		t.Error("The key size must be checked")
+	}
+	if err := c.SaveEncrypted(&buf, key); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("secret-value")) {
+		t.Error("The snapshot must not be plaintext")
+	}
+	data := buf.Bytes()
+
+	wrong := []byte("fedcba9876543210fedcba9876543210")
+	if err := New(0, 0).LoadEncrypted(bytes.NewReader(data), wrong); err != ErrDecrypt {
+		t.Error("Loading with a wrong key must fail")
+	}
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)-1] ^= 1
+	if err := New(0, 0).LoadEncrypted(bytes.NewReader(tampered), key); err != ErrDecrypt {
+		t.Error("Loading modified data must fail")
+	}
+	c2 := New(0, 0)
+	if err := c2.LoadEncrypted(bytes.NewReader(data), key); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := c2.Get("token"); val != "secret-value" {
+		t.Error("You get a wrong decrypted value")
+	}
+}
+
+func TestEncryptedSnapshotter(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	c := New(0, 0)
+	c.Set("key", "val", 0)
+	s, err := NewSnapshotter(c, path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+	if err := s.SetEncryptionKey([]byte("bad")); err == nil {
+		t.Error("The key size must be checked")
+	}
+	s.SetEncryptionKey(key)
+	if err := s.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if err := New(0, 0).LoadFile(path); err == nil {
+		t.Error("The snapshot must be encrypted")
+	}
+	c2 := New(0, 0)
+	if err := c2.LoadFileEncrypted(path, key); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := c2.Get("key"); val != "val" {
+		t.Error("You get a wrong decrypted value")
+	}
+}
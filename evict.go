@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// SampleEviction tells which of the sampled items a Cache evicts when it is
+// full.
+type SampleEviction int
+
+const (
+	// EvictNearestExpiry evicts the sampled item which expires first. Items
+	// without expiration are evicted last.
+	EvictNearestExpiry SampleEviction = iota
+	// EvictLeastRecentlyUsed evicts the sampled item which was not read for
+	// the longest time.
+	EvictLeastRecentlyUsed
+)
+
+// DefaultEvictSamples is the number of items sampled when samples is less
+// than 1.
+const DefaultEvictSamples = 5
+
+// SetMaxItems limit the number of items in the cache. When a new key is set
+// in a full cache, samples random items are picked and the one chosen by
+// mode is evicted, like Redis does. This approximates the eviction order
+// without maintaining it for every item. See SetFullPolicy to not evict.
+// The max is 0 means no limit.
+func (c *Cache) SetMaxItems(max, samples int, mode SampleEviction) error {
+	if max < 0 {
+		return errors.New("The max limit of items must no less than 0")
+	}
+	if samples < 1 {
+		samples = DefaultEvictSamples
+	}
+	c.Lock()
+	c.maxItems = max
+	c.evictSamples = samples
+	c.evictMode = mode
+	for max > 0 && len(c.items) > max {
+		c.evictSampled()
+	}
+	c.signalRoom()
+	c.unlockAndNotify()
+	return nil
+}
+
+// evictSampled evict one of evictSamples items. The caller must hold the
+// lock. Map iteration starts at a random position, which makes the first
+// items a random sample.
+func (c *Cache) evictSampled() {
+	var victim interface{}
+	var victimItem *Item
+	n := 0
+	for k, item := range c.items {
+		if victimItem == nil || c.evictBefore(item, victimItem) {
+			victim, victimItem = k, item
+		}
+		n++
+		if n >= c.evictSamples {
+			break
+		}
+	}
+	if victimItem != nil {
+		delete(c.items, victim)
+		atomic.AddUint64(&c.stats.evictions, 1)
+		c.recordRemoval(victim, victimItem)
+		c.removedAs(EventEvict, victim, victimItem)
+	}
+}
+
+// evictBefore return true if a should be evicted before b.
+func (c *Cache) evictBefore(a, b *Item) bool {
+	if c.evictMode == EvictLeastRecentlyUsed {
+		return atomic.LoadInt64(&a.accessed) < atomic.LoadInt64(&b.accessed)
+	}
+	if a.Expiration == nil {
+		return false
+	}
+	return b.Expiration == nil || a.Expiration.Before(*b.Expiration)
+}
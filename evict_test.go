@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetMaxItems(t *testing.T) {
+	c := New(0, 0)
+	if err := c.SetMaxItems(-1, 0, EvictNearestExpiry); err == nil {
+		t.Error("Impossiable!")
+	}
+	c.SetMaxItems(3, 3, EvictNearestExpiry)
+	c.Set("forever", 0, -1)
+	c.Set("soon", 1, time.Second)
+	c.Set("later", 2, time.Hour)
+	c.Set("new", 3, time.Hour)
+	if c.ItemCount() != 3 {
+		t.Error("The number of cache must be 3")
+	}
+	if _, found := c.Get("soon"); found {
+		t.Error("The item expiring first must be evicted")
+	}
+	c.Set("new", 4, time.Hour)
+	if c.ItemCount() != 3 {
+		t.Error("Replacing a key must not evict")
+	}
+	c.SetMaxItems(1, 3, EvictNearestExpiry)
+	if c.ItemCount() != 1 {
+		t.Error("The cache must be shrunk to the new limit")
+	}
+	if _, found := c.Get("forever"); !found {
+		t.Error("The item without expiration must be evicted last")
+	}
+}
+
+func TestSetMaxItemsLRU(t *testing.T) {
+	c := New(0, 0)
+	c.SetMaxItems(2, 2, EvictLeastRecentlyUsed)
+	c.Set("1", 1, 0)
+	time.Sleep(time.Millisecond)
+	c.Set("2", 2, 0)
+	time.Sleep(time.Millisecond)
+	c.Get("1")
+	c.Set("3", 3, 0)
+	if _, found := c.Get("2"); found {
+		t.Error("The least recently used item must be evicted")
+	}
+	if _, found := c.Get("1"); !found {
+		t.Error("The recently used item must be kept")
+	}
+}
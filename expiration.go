@@ -0,0 +1,284 @@
+package cache
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Expiration describe an item removed from the cache because it expired.
+type Expiration struct {
+	Key   interface{}
+	Value interface{}
+	At    time.Time
+}
+
+// SubscribeExpired return a channel receiving the expired items, and a
+// function to cancel the subscription. The items are delivered strictly in
+// the order of their expiration time, so the channel works as a lightweight
+// timer service: while there are subscribers, the items are kept in an
+// expiry heap, and every item is removed at its expiration, or at the end
+// of its stale window, see SetStaleWindow, without waiting for the janitor.
+// An item removed by a cleanup, see DeleteExpired, is delivered once the
+// items which expire before it are removed too, so an item kept by the
+// stale window, or while the cache is degraded, holds back the items which
+// expire after it. Items deleted or replaced before they expire are not
+// delivered. Events are queued without limit while the receiver is busy.
+func (c *Cache) SubscribeExpired() (<-chan Expiration, func()) {
+	sub := &expirySubscriber{
+		ch:   make(chan Expiration),
+		done: make(chan struct{}),
+	}
+	sub.cond = sync.NewCond(&sub.mu)
+	c.Lock()
+	c.expirySubs = append(c.expirySubs, sub)
+	if c.expiries == nil {
+		c.startExpiryTimer()
+	}
+	c.Unlock()
+	go sub.run()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			c.Lock()
+			for i, s := range c.expirySubs {
+				if s == sub {
+					c.expirySubs = append(c.expirySubs[:i], c.expirySubs[i+1:]...)
+					break
+				}
+			}
+			if len(c.expirySubs) == 0 {
+				c.stopExpiryTimer()
+			}
+			c.Unlock()
+			sub.close()
+		})
+	}
+	return sub.ch, cancel
+}
+
+// expiryEntry is an item in the expiry heap. It is stale once the key is
+// removed or its expiration is changed, and then skipped.
+type expiryEntry struct {
+	at  int64
+	key interface{}
+}
+
+// expiryHeap is a min-heap of the items by expiration.
+type expiryHeap []expiryEntry
+
+func (h expiryHeap) Len() int            { return len(h) }
+func (h expiryHeap) Less(i, j int) bool  { return h[i].at < h[j].at }
+func (h expiryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap) Push(x interface{}) { *h = append(*h, x.(expiryEntry)) }
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = expiryEntry{}
+	*h = old[:len(old)-1]
+	return e
+}
+
+// expiredHeap is a min-heap of the expired items waiting for the items
+// which expire before them.
+type expiredHeap []Expiration
+
+func (h expiredHeap) Len() int            { return len(h) }
+func (h expiredHeap) Less(i, j int) bool  { return h[i].At.Before(h[j].At) }
+func (h expiredHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *expiredHeap) Push(x interface{}) { *h = append(*h, x.(Expiration)) }
+func (h *expiredHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = Expiration{}
+	*h = old[:len(old)-1]
+	return e
+}
+
+// scheduleExpiry record the new expiration of a key in the timing wheel
+// and the expiry heap, if any. The caller must hold the lock.
+func (c *Cache) scheduleExpiry(key interface{}, t time.Time) {
+	if c.wheel != nil {
+		c.wheel.schedule(key, t)
+	}
+	if c.expiries == nil {
+		return
+	}
+	if len(*c.expiries) > 2*len(c.items)+64 {
+		c.rebuildExpiries()
+	}
+	heap.Push(c.expiries, expiryEntry{t.UnixNano(), key})
+	if (*c.expiries)[0].key == key {
+		c.wakeExpiryTimer()
+	}
+}
+
+// rebuildExpiries fill the expiry heap with the items which expire,
+// dropping the stale entries. The caller must hold the lock.
+func (c *Cache) rebuildExpiries() {
+	h := make(expiryHeap, 0, len(c.items))
+	for k, v := range c.items {
+		if v.Expiration != nil {
+			h = append(h, expiryEntry{v.Expiration.UnixNano(), k})
+		}
+	}
+	heap.Init(&h)
+	c.expiries = &h
+}
+
+// nextExpiry return the first item of the expiry heap, dropping the stale
+// entries, and false if no item expires. The caller must hold the lock.
+func (c *Cache) nextExpiry() (interface{}, *Item, bool) {
+	for c.expiries.Len() > 0 {
+		e := (*c.expiries)[0]
+		if item, ok := c.items[e.key]; ok && item.Expiration != nil && item.Expiration.UnixNano() == e.at {
+			return e.key, item, true
+		}
+		heap.Pop(c.expiries)
+	}
+	return nil, nil, false
+}
+
+// startExpiryTimer build the expiry heap and start the goroutine removing
+// the items at their expiration. The caller must hold the lock.
+func (c *Cache) startExpiryTimer() {
+	c.rebuildExpiries()
+	c.expiredQueue = &expiredHeap{}
+	c.expiryWake = make(chan struct{}, 1)
+	go c.runExpiryTimer(c.expiryWake)
+}
+
+// stopExpiryTimer drop the expiry heap and stop its goroutine. The caller
+// must hold the lock.
+func (c *Cache) stopExpiryTimer() {
+	c.wakeExpiryTimer()
+	c.expiries, c.expiredQueue, c.expiryWake = nil, nil, nil
+}
+
+// wakeExpiryTimer make the timer check the expiry heap again. The caller
+// must hold the lock.
+func (c *Cache) wakeExpiryTimer() {
+	select {
+	case c.expiryWake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Cache) runExpiryTimer(wake chan struct{}) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		c.Lock()
+		if c.expiryWake != wake {
+			c.Unlock()
+			return
+		}
+		var expired []Expiration
+		wait := time.Duration(-1)
+		for {
+			key, item, ok := c.nextExpiry()
+			if !ok || c.degraded {
+				break
+			}
+			if !c.deleteIfExpired(key, item, &expired) {
+				wait = item.Expiration.Add(c.staleWindow).Sub(c.now())
+				// The item is removable strictly after the end of its
+				// stale window.
+				if wait < time.Millisecond {
+					wait = time.Millisecond
+				}
+				break
+			}
+			heap.Pop(c.expiries)
+		}
+		c.notifyExpired(expired)
+		c.unlockAndNotify()
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if wait < 0 {
+			<-wake
+			continue
+		}
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-wake:
+		}
+	}
+}
+
+// notifyExpired queue the expired items for every subscriber, in the order
+// of their expiration, once the items which expire before them are removed
+// too. The caller must hold the lock, which keeps the cleanups from
+// interleaving.
+func (c *Cache) notifyExpired(expired []Expiration) {
+	if c.expiredQueue == nil {
+		return
+	}
+	for _, e := range expired {
+		heap.Push(c.expiredQueue, e)
+	}
+	_, next, ok := c.nextExpiry()
+	var ready []Expiration
+	for c.expiredQueue.Len() > 0 && (!ok || !(*c.expiredQueue)[0].At.After(*next.Expiration)) {
+		ready = append(ready, heap.Pop(c.expiredQueue).(Expiration))
+	}
+	if len(ready) == 0 {
+		return
+	}
+	for _, sub := range c.expirySubs {
+		sub.push(ready)
+	}
+}
+
+type expirySubscriber struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []Expiration
+	closed bool
+	ch     chan Expiration
+	done   chan struct{}
+}
+
+func (s *expirySubscriber) push(events []Expiration) {
+	s.mu.Lock()
+	s.queue = append(s.queue, events...)
+	s.mu.Unlock()
+	s.cond.Signal()
+}
+
+func (s *expirySubscriber) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	close(s.done)
+	s.cond.Signal()
+}
+
+func (s *expirySubscriber) run() {
+	defer close(s.ch)
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+		ev := s.queue[0]
+		s.queue[0] = Expiration{}
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+		select {
+		case s.ch <- ev:
+		case <-s.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscribeExpired(t *testing.T) {
+	c := New(0, 0)
+	events, cancel := c.SubscribeExpired()
+	defer cancel()
+	c.Set("b", 2, 20*time.Millisecond)
+	c.Set("a", 1, 10*time.Millisecond)
+	c.Set("c", 3, 30*time.Millisecond)
+	c.Set("forever", 0, -1)
+	c.Set("d", 4, 40*time.Millisecond)
+	c.Set("e", 5, 50*time.Millisecond)
+	// The items removed by a cleanup wait for the items expiring before
+	// them.
+	time.Sleep(5 * time.Millisecond)
+	c.Touch("a", 15*time.Millisecond)
+	c.Delete("e")
+	for _, want := range []string{"b", "a", "c", "d"} {
+		select {
+		case ev := <-events:
+			if ev.Key != want {
+				t.Errorf("You get %v, but %v expired first", ev.Key, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("The expiration of", want, "is not delivered")
+		}
+	}
+	if c.ItemCount() != 1 {
+		t.Error("The items must be removed at their expiration, without cleanup", c.ItemCount())
+	}
+	select {
+	case ev := <-events:
+		t.Error("A deleted item must not be delivered", ev.Key)
+	case <-time.After(60 * time.Millisecond):
+	}
+}
+
+func TestSubscribeExpiredStale(t *testing.T) {
+	c := New(0, 0)
+	c.SetStaleWindow(30 * time.Millisecond)
+	events, cancel := c.SubscribeExpired()
+	defer cancel()
+	c.Set("a", 1, 10*time.Millisecond)
+	c.Set("b", 2, 20*time.Millisecond)
+	time.Sleep(35 * time.Millisecond)
+	// The cleanup removes nothing: a is stale.
+	c.SetStaleWindow(0)
+	c.DeleteExpired()
+	for _, want := range []string{"a", "b"} {
+		select {
+		case ev := <-events:
+			if ev.Key != want {
+				t.Errorf("You get %v, but %v expired first", ev.Key, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("The expiration of", want, "is not delivered")
+		}
+	}
+}
+
+func TestSubscribeExpiredCancel(t *testing.T) {
+	c := New(0, 0)
+	events, cancel := c.SubscribeExpired()
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("The channel must be closed after cancel")
+	}
+	if len(c.expirySubs) != 0 {
+		t.Error("The subscriber must be removed")
+	}
+	cancel()
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"expvar"
+	"fmt"
+)
+
+// PublishExpvar publish the Stats of the cache under name with expvar, so
+// they are shown live by the /debug/vars endpoint. A name can be published
+// only once in a process.
+func (c *Cache) PublishExpvar(name string) error {
+	return publishStats(name, func() interface{} { return c.Stats() })
+}
+
+// PublishExpvar publish the Stats of the LRUCache under name with expvar,
+// like Cache.PublishExpvar.
+func (c *LRUCache) PublishExpvar(name string) error {
+	return publishStats(name, func() interface{} { return c.Stats() })
+}
+
+// PublishOpsExpvar publish the operations recorded by RecordOps under name
+// with expvar, so the last operations before an incident can be read from
+// the /debug/vars endpoint.
+func (c *Cache) PublishOpsExpvar(name string) error {
+	return publishStats(name, func() interface{} { return c.RecentOps() })
+}
+
+func publishStats(name string, stats expvar.Func) error {
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("The expvar %s is already published", name)
+	}
+	expvar.Publish(name, stats)
+	return nil
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	c := New(0, 0)
+	if err := c.PublishExpvar("test_cache"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PublishExpvar("test_cache"); err == nil {
+		t.Error("Impossiable!")
+	}
+	c.Set("a", 1, 0)
+	c.Get("a")
+	var s Stats
+	if err := json.Unmarshal([]byte(expvar.Get("test_cache").String()), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Hits != 1 || s.CurrentEntries != 1 {
+		t.Errorf("You get wrong stats %+v", s)
+	}
+}
+
+func TestPublishOpsExpvar(t *testing.T) {
+	c := New(0, 0)
+	c.RecordOps(10)
+	if err := c.PublishOpsExpvar("test_cache_ops"); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("a", 1, 0)
+	var ops []OpRecord
+	if err := json.Unmarshal([]byte(expvar.Get("test_cache_ops").String()), &ops); err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 1 || ops[0].Op != "set" || ops[0].Key != "a" {
+		t.Errorf("You get wrong operations %+v", ops)
+	}
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrStaleWrite is returned by SetFenced when the key was invalidated after
+// the fence token was taken.
+var ErrStaleWrite = errors.New("The write is older than the last invalidation of the key")
+
+// Fence return the current fence token. Take it before reading the origin,
+// and pass it to SetFenced when storing the computed value.
+func (c *Cache) Fence() uint64 {
+	c.RLock()
+	token := c.fenceSeq
+	c.RUnlock()
+	return token
+}
+
+// Invalidate delete a key-value pair and advance its fence, so values
+// computed from reads made before the invalidation are rejected by
+// SetFenced. The fence of a key is kept until Flush.
+func (c *Cache) Invalidate(key interface{}) {
+	if !c.keyAllowed(key) {
+		return
+	}
+	c.Lock()
+	if c.access(key, OpDelete) != nil {
+		c.Unlock()
+		return
+	}
+	if item, ok := c.items[key]; ok {
+		delete(c.items, key)
+		atomic.AddUint64(&c.stats.deletes, 1)
+		c.removed(key, item)
+	}
+	c.fenceSeq++
+	if c.fences == nil {
+		c.fences = map[interface{}]uint64{}
+	}
+	c.fences[key] = c.fenceSeq
+	c.unlockAndNotify()
+	c.publishInvalidation(key)
+}
+
+// SetFenced works like Set, but only if the key was not invalidated after
+// the token was returned by Fence. Otherwise it returns ErrStaleWrite. Like
+// SetSync, it returns ErrFull or ErrValueTooLarge if val is not stored.
+func (c *Cache) SetFenced(key interface{}, val interface{}, dur time.Duration, token uint64) error {
+	if !c.keyAllowed(key) {
+		return nil
+	}
+	c.Lock()
+	defer c.unlockAndNotify()
+	if err := c.access(key, OpSet); err != nil {
+		return err
+	}
+	if token < c.fences[key] {
+		return ErrStaleWrite
+	}
+	if c.tombstoned(key) {
+		return nil
+	}
+	return c.set(key, val, dur)
+}
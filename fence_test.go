@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestFence(t *testing.T) {
+	c := New(0, 0)
+	token := c.Fence()
+	// The origin is read here, then the key is invalidated concurrently.
+	c.Invalidate("key")
+	if err := c.SetFenced("key", "stale", 0, token); err != ErrStaleWrite {
+		t.Error("The stale write must be rejected")
+	}
+	if _, found := c.Get("key"); found {
+		t.Error("The stale value must not be stored")
+	}
+	token = c.Fence()
+	if err := c.SetFenced("key", "fresh", 0, token); err != nil {
+		t.Error(err)
+	}
+	if val, _ := c.Get("key"); val != "fresh" {
+		t.Error("The fresh value must be stored")
+	}
+	if err := c.SetFenced("other", "val", 0, 0); err != nil {
+		t.Error("A key never invalidated accepts any token")
+	}
+}
@@ -0,0 +1,98 @@
+// Package filecache caches the content of files by path, for serving static
+// assets or config files without reading the disk at every request. The
+// cache is limited by the total size of the contents, evicting the least
+// recently used files, and a file is read again when its modification time
+// or size changes.
+package filecache
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+// Cache is a goroutine-safe cache of file contents.
+type Cache struct {
+	lru           *cache.LRUCache
+	checkInterval time.Duration
+}
+
+// entry is the content of a file, never modified once cached.
+type entry struct {
+	data    []byte
+	modTime time.Time
+	size    int64
+	checked time.Time
+}
+
+// New create a Cache holding at most maxBytes of contents. A cached file is
+// checked for changes when read, at most every checkInterval; 0 checks at
+// every read.
+func New(maxBytes int64, checkInterval time.Duration) (*Cache, error) {
+	if maxBytes <= 0 {
+		return nil, errors.New("The max bytes must greater than 0")
+	}
+	lru, err := cache.NewWeightedLRU(maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{lru: lru, checkInterval: checkInterval}, nil
+}
+
+// ReadFile return the content of the file at path, from the cache if it did
+// not change. The returned slice must not be modified. A file larger than
+// the max bytes is read but not cached.
+func (c *Cache) ReadFile(path string) ([]byte, error) {
+	path = filepath.Clean(path)
+	now := time.Now()
+	v, found := c.lru.Get(path)
+	if found {
+		e := v.(*entry)
+		if now.Sub(e.checked) < c.checkInterval {
+			return e.data, nil
+		}
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		c.lru.Remove(path)
+		return nil, err
+	}
+	if found {
+		e := v.(*entry)
+		if fi.ModTime().Equal(e.modTime) && fi.Size() == e.size {
+			c.lru.AddWithWeight(path, &entry{data: e.data, modTime: e.modTime, size: e.size, checked: now}, weight(e))
+			return e.data, nil
+		}
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		c.lru.Remove(path)
+		return nil, err
+	}
+	e := &entry{data: data, modTime: fi.ModTime(), size: fi.Size(), checked: now}
+	c.lru.AddWithWeight(path, e, weight(e))
+	return data, nil
+}
+
+// weight is the size of the content, at least 1 so empty files count.
+func weight(e *entry) int64 {
+	if len(e.data) == 0 {
+		return 1
+	}
+	return int64(len(e.data))
+}
+
+// Invalidate drop the cached content of the file at path, for example when
+// a file watcher reports it changed.
+func (c *Cache) Invalidate(path string) {
+	c.lru.Remove(filepath.Clean(path))
+}
+
+// Size return the total size of the cached contents.
+func (c *Cache) Size() int64 {
+	return c.lru.Weight()
+}
@@ -0,0 +1,60 @@
+package filecache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filecache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a := filepath.Join(dir, "a.txt")
+	ioutil.WriteFile(a, []byte("hello"), 0644)
+
+	c, _ := New(8, 0)
+	if data, err := c.ReadFile(a); err != nil || string(data) != "hello" {
+		t.Fatal("You get a wrong content", string(data), err)
+	}
+	if c.Size() != 5 {
+		t.Error("The content must be cached")
+	}
+	ioutil.WriteFile(a, []byte("changed"), 0644)
+	os.Chtimes(a, time.Now().Add(time.Hour), time.Now().Add(time.Hour))
+	if data, _ := c.ReadFile(a); string(data) != "changed" {
+		t.Error("A modified file must be read again", string(data))
+	}
+
+	b := filepath.Join(dir, "b.txt")
+	ioutil.WriteFile(b, []byte("world"), 0644)
+	c.ReadFile(b)
+	if c.Size() != 5 {
+		t.Error("The least recently used file must be evicted", c.Size())
+	}
+	os.Remove(b)
+	if _, err := c.ReadFile(b); err == nil || c.Size() != 0 {
+		t.Error("A removed file must be dropped")
+	}
+}
+
+func TestCheckInterval(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "filecache")
+	defer os.RemoveAll(dir)
+	a := filepath.Join(dir, "a.txt")
+	ioutil.WriteFile(a, []byte("hello"), 0644)
+	c, _ := New(100, time.Hour)
+	c.ReadFile(a)
+	ioutil.WriteFile(a, []byte("changed"), 0644)
+	if data, _ := c.ReadFile(a); string(data) != "hello" {
+		t.Error("The file must not be checked before the interval")
+	}
+	c.Invalidate(a)
+	if data, _ := c.ReadFile(a); string(data) != "changed" {
+		t.Error("Impossiable!")
+	}
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"time"
+)
+
+// Flags are runtime toggles consulted by the cache on every operation, so
+// incident responders can change its behavior at once through a feature
+// flag system. The methods are called on hot paths, with the lock held:
+// they must be fast and must not use the cache.
+type Flags interface {
+	// Bypass makes every Get miss, so the callers go to the origin. Writes
+	// are still applied, to keep the cache up to date for when the bypass
+	// is turned off.
+	Bypass() bool
+	// MaxTTL caps the expiration of the items set from now, including the
+	// items which would never expire. It is 0 means no cap.
+	MaxTTL() time.Duration
+}
+
+// RefreshFlags is Flags which can also turn off the refreshes: while
+// DisableRefresh is true, the Refreshers of the cache do not refresh the
+// keys, and SetStaleWhileRevalidate is ignored, so the stale items miss.
+type RefreshFlags interface {
+	Flags
+	DisableRefresh() bool
+}
+
+// SetFlags set the runtime toggles of the cache. Set to nil to remove them.
+func (c *Cache) SetFlags(f Flags) {
+	c.Lock()
+	c.flags = f
+	c.Unlock()
+}
+
+// bypassed return true if Get must miss. The caller must hold the lock.
+func (c *Cache) bypassed() bool {
+	return c.flags != nil && c.flags.Bypass()
+}
+
+// refreshDisabled return true if the refreshes are turned off. The caller
+// must hold the lock.
+func (c *Cache) refreshDisabled() bool {
+	f, ok := c.flags.(RefreshFlags)
+	return ok && f.DisableRefresh()
+}
+
+// cappedTTL return the duration of an item set for dur, after the default
+// expiration, the TTL bounds and the MaxTTL flag are applied. The caller
+// must hold the lock.
+func (c *Cache) cappedTTL(dur time.Duration) time.Duration {
+	if dur == 0 {
+		dur = c.defaultExpiration
+	}
+	if dur > 0 && dur < c.minTTL {
+		dur = c.minTTL
+	}
+	if c.maxTTL > 0 && (dur <= 0 || dur > c.maxTTL) {
+		dur = c.maxTTL
+	}
+	if c.flags != nil {
+		if max := c.flags.MaxTTL(); max > 0 && (dur <= 0 || dur > max) {
+			dur = max
+		}
+	}
+	return dur
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testFlags struct {
+	bypass    bool
+	maxTTL    time.Duration
+	noRefresh int32
+}
+
+func (f *testFlags) Bypass() bool          { return f.bypass }
+func (f *testFlags) MaxTTL() time.Duration { return f.maxTTL }
+func (f *testFlags) DisableRefresh() bool  { return atomic.LoadInt32(&f.noRefresh) == 1 }
+
+func TestFlags(t *testing.T) {
+	c := New(0, 0)
+	flags := &testFlags{bypass: true}
+	c.SetFlags(flags)
+	c.Set("a", 1, -1)
+	if _, found := c.Get("a"); found {
+		t.Error("The cache is bypassed")
+	}
+	flags.bypass = false
+	if _, found := c.Get("a"); !found {
+		t.Error("The write must be applied during the bypass")
+	}
+	flags.maxTTL = time.Minute
+	c.Set("b", 2, -1)
+	c.Set("c", 3, time.Hour)
+	for _, key := range []string{"b", "c"} {
+		info, _ := c.GetItemInfo(key)
+		if info.Expiration == nil || info.Expiration.After(time.Now().Add(time.Minute)) {
+			t.Errorf("The TTL of %s must be capped", key)
+		}
+	}
+}
+
+func TestFlagsDisableRefresh(t *testing.T) {
+	c := New(0, 0)
+	flags := &testFlags{noRefresh: 1}
+	c.SetFlags(flags)
+	var loads int32
+	r, err := NewRefresher(c, RefreshPolicy{
+		Loader: func(key interface{}) (interface{}, error) {
+			return atomic.AddInt32(&loads, 1), nil
+		},
+		Ahead: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+	r.Register("a", time.Minute)
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&loads) != 0 {
+		t.Error("The refreshes are disabled")
+	}
+	atomic.StoreInt32(&flags.noRefresh, 0)
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&loads) == 0 {
+		t.Error("Now, the key must be refreshed")
+	}
+
+	c.SetStaleWindow(time.Minute)
+	c.SetStaleWhileRevalidate(func(key interface{}) (interface{}, error) { return 2, nil })
+	c.Set("b", 1, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	atomic.StoreInt32(&flags.noRefresh, 1)
+	if _, found := c.Get("b"); found {
+		t.Error("The stale item must miss while the refreshes are disabled")
+	}
+}
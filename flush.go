@@ -0,0 +1,29 @@
+package cache
+
+// FlushExpired is DeleteExpired: it deletes all the expired items and
+// returns their number.
+func (c *Cache) FlushExpired() int {
+	return c.DeleteExpired()
+}
+
+// SetFlushEvicts make Flush call the eviction callback for every item it
+// deletes, like Close does, so the resources accounted by the callback are
+// released. It is off by default. See OnEvicted.
+func (c *Cache) SetFlushEvicts(on bool) {
+	c.Lock()
+	c.flushEvicts = on
+	c.Unlock()
+}
+
+// FlushFunc works like Flush, then calls f with the key and value of every
+// item deleted, expired ones included, without the lock held. It returns
+// the number of items deleted.
+func (c *Cache) FlushFunc(f func(key, value interface{})) int {
+	items := c.flush()
+	if f != nil {
+		for k, v := range items {
+			f(k, v.Object)
+		}
+	}
+	return len(items)
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlushExpired(t *testing.T) {
+	c := New(0, 0)
+	c.Set("a", 1, time.Nanosecond)
+	c.Set("b", 2, time.Nanosecond)
+	c.Set("c", 3, -1)
+	time.Sleep(time.Millisecond)
+	if n := c.FlushExpired(); n != 2 || c.ItemCount() != 1 {
+		t.Error("You get a wrong number of expired items", n)
+	}
+}
+
+func TestFlushEvicts(t *testing.T) {
+	c := New(0, 0)
+	evicted := map[interface{}]interface{}{}
+	c.OnEvicted(func(k, v interface{}) {
+		evicted[k] = v
+	})
+	c.Set("a", 1, 0)
+	c.Flush()
+	if len(evicted) != 0 {
+		t.Error("The eviction callback is off by default")
+	}
+	c.SetFlushEvicts(true)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Flush()
+	if len(evicted) != 2 || evicted["b"] != 2 || c.ItemCount() != 0 {
+		t.Error("Now, the eviction callback must be called", evicted)
+	}
+}
+
+func TestFlushFunc(t *testing.T) {
+	c := New(0, 0)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	sum := 0
+	n := c.FlushFunc(func(k, v interface{}) {
+		// The lock is not held.
+		c.Set("flushed", true, 0)
+		sum += v.(int)
+	})
+	if n != 2 || sum != 3 {
+		t.Error("f must be called for every item", n, sum)
+	}
+	if _, found := c.Get("flushed"); !found {
+		t.Error("Impossiable!")
+	}
+}
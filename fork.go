@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"sync/atomic"
+)
+
+// Fork create a copy of the cache which shares its items: the map is copied
+// but not the items, which are only copied when one side modifies them in
+// place, like Increment does. Set, Delete and the other writes of either
+// side are not seen by the other one, so a fork is a cheap sandbox for
+// what-if computations. The fork has the default expiration, max items and
+// key guard of the cache, but no janitor, eviction callback or
+// subscription.
+func (c *Cache) Fork() *Cache {
+	c.RLock()
+	defer c.RUnlock()
+	f := New(c.defaultExpiration, 0)
+	f.maxItems = c.maxItems
+	f.evictSamples = c.evictSamples
+	f.evictMode = c.evictMode
+	if g := c.keyGuard.Load(); g != nil {
+		f.keyGuard.Store(g)
+	}
+	f.parent = c.parent
+	f.versionSeq = c.versionSeq
+	f.items = make(map[interface{}]*Item, len(c.items))
+	for k, v := range c.items {
+		atomic.StoreInt32(&v.shared, 1)
+		f.items[k] = v
+	}
+	return f
+}
+
+// writable return an item which may be modified in place, copying it if it
+// is shared with a fork. The caller must hold the lock.
+func (c *Cache) writable(key interface{}, item *Item) *Item {
+	if atomic.LoadInt32(&item.shared) == 0 {
+		return item
+	}
+	cp := &Item{
+		accessed:      atomic.LoadInt64(&item.accessed),
+		written:       item.written,
+		created:       item.created,
+		version:       item.version,
+		refreshing:    item.refreshing,
+		staleNotified: item.staleNotified,
+		Object:        item.Object,
+		Expiration:    item.Expiration,
+	}
+	c.items[key] = cp
+	return cp
+}
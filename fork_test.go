@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestFork(t *testing.T) {
+	c := New(0, 0)
+	c.Set("counter", 1, 0)
+	c.Set("key", "val", 0)
+	f := c.Fork()
+	if val, _ := f.Get("key"); val != "val" || f.ItemCount() != 2 {
+		t.Error("The fork must share the items")
+	}
+	f.Increment("counter", 10)
+	f.Set("key", "forked", 0)
+	f.Set("new", 1, 0)
+	if val, _ := c.Get("counter"); val != 1 {
+		t.Error("Modifying the fork must not change the cache", val)
+	}
+	if val, _ := c.Get("key"); val != "val" {
+		t.Error("Writing the fork must not change the cache")
+	}
+	if _, found := c.Get("new"); found {
+		t.Error("Writing the fork must not change the cache")
+	}
+	c.Decrement("counter", 1)
+	if val, _ := f.Get("counter"); val != 11 {
+		t.Error("Modifying the cache must not change the fork", val)
+	}
+	if val, _ := c.Get("counter"); val != 0 {
+		t.Error("You get a wrong value", val)
+	}
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrFull is returned when a new key is set in a full cache which does not
+// evict, see SetFullPolicy.
+var ErrFull = errors.New("The cache is full")
+
+// FullPolicy tells what a Cache limited by SetMaxItems does when a new key
+// is set while it is full.
+type FullPolicy int
+
+const (
+	// FullEvict evicts a sampled item, see SetMaxItems.
+	FullEvict FullPolicy = iota
+	// FullReject rejects the new key with ErrFull.
+	FullReject
+	// FullOverwriteOldest evicts the item written first, like a ring
+	// buffer. It scans all the items.
+	FullOverwriteOldest
+	// FullBlock makes SetContext wait for an item to be removed, deleted,
+	// expired or flushed. Set and SetSync wait without a deadline. The
+	// other writes of new keys are rejected, like FullReject.
+	FullBlock
+)
+
+// SetFullPolicy set what the cache does when it is full, for queue-like uses
+// which must not lose items silently. Expired items still take room until
+// they are deleted by the janitor or DeleteExpired.
+func (c *Cache) SetFullPolicy(p FullPolicy) {
+	c.Lock()
+	c.fullPolicy = p
+	// The waiters check the policy again.
+	c.signalRoom()
+	c.Unlock()
+}
+
+// full return true if the key is new and the cache is full. The caller must
+// hold the lock.
+func (c *Cache) full(key interface{}) bool {
+	if c.maxItems <= 0 || len(c.items) < c.maxItems {
+		return false
+	}
+	_, ok := c.items[key]
+	return !ok
+}
+
+// makeRoom remove an item from a full cache, or return ErrFull if the
+// FullPolicy does not evict. The caller must hold the lock.
+func (c *Cache) makeRoom() error {
+	switch c.fullPolicy {
+	case FullReject, FullBlock:
+		return ErrFull
+	case FullOverwriteOldest:
+		c.evictOldest()
+	default:
+		c.evictSampled()
+	}
+	return nil
+}
+
+// admit wait for room for a new key with FullBlock, or return ErrFull with
+// FullReject if the cache is full. The caller must hold the lock, which is
+// released while waiting and held again when it returns.
+func (c *Cache) admit(ctx context.Context, key interface{}) error {
+	for c.full(key) {
+		switch c.fullPolicy {
+		case FullReject:
+			return ErrFull
+		case FullBlock:
+		default:
+			return nil
+		}
+		if c.room == nil {
+			c.room = make(chan struct{})
+		}
+		room := c.room
+		c.Unlock()
+		select {
+		case <-room:
+		case <-ctx.Done():
+			c.Lock()
+			return ctx.Err()
+		}
+		c.Lock()
+	}
+	return nil
+}
+
+// signalRoom wake up the writers waiting for room. The caller must hold the
+// lock.
+func (c *Cache) signalRoom() {
+	if c.room != nil {
+		close(c.room)
+		c.room = nil
+	}
+}
+
+// evictOldest evict the item written first. The caller must hold the lock.
+func (c *Cache) evictOldest() {
+	var victim interface{}
+	var victimItem *Item
+	for k, item := range c.items {
+		if victimItem == nil || atomic.LoadInt64(&item.written) < atomic.LoadInt64(&victimItem.written) {
+			victim, victimItem = k, item
+		}
+	}
+	if victimItem != nil {
+		delete(c.items, victim)
+		atomic.AddUint64(&c.stats.evictions, 1)
+		c.recordRemoval(victim, victimItem)
+		c.removedAs(EventEvict, victim, victimItem)
+	}
+}
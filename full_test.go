@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFullReject(t *testing.T) {
+	c := New(0, 0)
+	c.SetMaxItems(2, 0, EvictNearestExpiry)
+	c.SetFullPolicy(FullReject)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	if err := c.SetSync("c", 3, 0); err != ErrFull {
+		t.Errorf("You get a wrong error %v", err)
+	}
+	if err := c.SetSync("a", 10, 0); err != nil {
+		t.Error("The existing keys can still be replaced")
+	}
+	if c.GetOrSet("c", 3, 0); c.ItemCount() != 2 {
+		t.Error("Impossiable!")
+	}
+}
+
+func TestFullOverwriteOldest(t *testing.T) {
+	c := New(0, 0)
+	c.SetMaxItems(2, 0, EvictNearestExpiry)
+	c.SetFullPolicy(FullOverwriteOldest)
+	c.Set("a", 1, 0)
+	time.Sleep(time.Millisecond)
+	c.Set("b", 2, time.Millisecond)
+	time.Sleep(time.Millisecond)
+	c.Set("c", 3, 0)
+	if _, found := c.Get("a"); found {
+		t.Error("The oldest item must be overwritten")
+	}
+	if c.ItemCount() != 2 {
+		t.Error("Impossiable!")
+	}
+}
+
+func TestFullBlock(t *testing.T) {
+	c := New(0, 0)
+	c.SetMaxItems(1, 0, EvictNearestExpiry)
+	c.SetFullPolicy(FullBlock)
+	c.Set("a", 1, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.SetContext(ctx, "b", 2, 0); err != context.DeadlineExceeded {
+		t.Errorf("You get a wrong error %v", err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- c.SetContext(context.Background(), "b", 2, 0)
+	}()
+	select {
+	case <-done:
+		t.Fatal("The writer must wait for room")
+	case <-time.After(10 * time.Millisecond):
+	}
+	c.Delete("a")
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, found := c.Get("b"); !found {
+		t.Error("Now, the key is set")
+	}
+	go func() {
+		done <- c.SetContext(context.Background(), "c", 3, 0)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	c.SetFullPolicy(FullEvict)
+	if err := <-done; err != nil || c.ItemCount() != 1 {
+		t.Error("The writer must be released when the policy changes")
+	}
+}
+
+func TestFullErrorsPropagated(t *testing.T) {
+	c := New(0, 0)
+	c.SetMaxItems(2, 0, EvictNearestExpiry)
+	c.SetFullPolicy(FullReject)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	if actual, loaded := c.GetOrSet("c", 3, 0); actual != nil || loaded {
+		t.Error("GetOrSet must not report the value as stored", actual)
+	}
+	if err := c.SetFenced("c", 3, 0, c.Fence()); err != ErrFull {
+		t.Error("SetFenced must return ErrFull", err)
+	}
+	if err := c.SetForce("c", 3, 0); err != ErrFull {
+		t.Error("SetForce must return ErrFull", err)
+	}
+	if s := c.Import([]Entry{{Key: "c", Value: 3}, {Key: "a", Value: 10}}, Overwrite); s != (ImportSummary{1, 0, 1}) {
+		t.Error("The rejected entry must be counted as failed", s)
+	}
+	src := New(0, 0)
+	src.Set("c", 3, 0)
+	var buf bytes.Buffer
+	src.Save(&buf)
+	if err := c.Load(&buf); err != ErrFull {
+		t.Error("Load must return ErrFull", err)
+	}
+	if err := c.ImportJSON(strings.NewReader(`[{"key":"c","value":3}]`)); err != ErrFull {
+		t.Error("ImportJSON must return ErrFull", err)
+	}
+	if err := c.SetChunked("big", []byte("abcdef"), 2, 0); err != ErrFull {
+		t.Error("SetChunked must return ErrFull", err)
+	}
+
+	c = New(0, 0)
+	c.SetMaxValueSize(2, ValueSizeReject)
+	c.Set("a", "x", 0)
+	err := c.Update(func(tx *Txn) error {
+		tx.Delete("a")
+		tx.Set("b", "y", 0)
+		tx.Set("c", "too large", 0)
+		return nil
+	})
+	if err != ErrValueTooLarge {
+		t.Error("Update must return ErrValueTooLarge", err)
+	}
+	if _, found := c.Get("a"); !found || c.ItemCount() != 1 {
+		t.Error("Nothing must be written")
+	}
+}
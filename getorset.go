@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// GetOrSet return the existing value of the key if it is found, like Get.
+// Otherwise it stores val for dur, like Set, and returns it. The loaded is
+// true if the value was found. Unlike a Get followed by a Set, no other
+// write can come in between. If val is not stored, because the cache is
+// full, see SetFullPolicy, or val is too large, see SetMaxValueSize, it
+// returns nil and false.
+func (c *Cache) GetOrSet(key interface{}, val interface{}, dur time.Duration) (actual interface{}, loaded bool) {
+	if !c.keyAllowed(key) {
+		return val, false
+	}
+	c.Lock()
+	if c.access(key, OpGet) != nil {
+		c.Unlock()
+		return val, false
+	}
+	if c.topK != nil {
+		c.topK.Record(key)
+	}
+	if item, ok := c.items[key]; ok && !c.expired(item) && !c.bypassed() {
+		c.recordHit(item)
+		// The value is copied with the lock held, see SetCopier.
+		v := c.copyValue(item.Object)
+		c.Unlock()
+		return v, true
+	}
+	if c.parent != nil && !c.bypassed() {
+		if v, found := c.parent.Get(key); found {
+			c.Unlock()
+			atomic.AddUint64(&c.stats.misses, 1)
+			return v, true
+		}
+	}
+	atomic.AddUint64(&c.stats.misses, 1)
+	if c.access(key, OpSet) == nil && !c.tombstoned(key) {
+		if err := c.set(key, val, dur); err != nil {
+			c.unlockAndNotify()
+			return nil, false
+		}
+	}
+	c.unlockAndNotify()
+	return val, false
+}
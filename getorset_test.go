@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGetOrSet(t *testing.T) {
+	c := New(0, 0)
+	if actual, loaded := c.GetOrSet("a", 1, 0); loaded || actual != 1 {
+		t.Error("The value must be stored")
+	}
+	if actual, loaded := c.GetOrSet("a", 2, 0); !loaded || actual != 1 {
+		t.Error("The existing value must be returned")
+	}
+	child := NewChild(c)
+	if actual, loaded := child.GetOrSet("a", 3, 0); !loaded || actual != 1 || child.ItemCount() != 0 {
+		t.Error("The value of the parent must be returned")
+	}
+
+	c = New(0, 0)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	stored := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, loaded := c.GetOrSet("key", i, 0); !loaded {
+				mu.Lock()
+				stored++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if stored != 1 {
+		t.Errorf("The value is stored %d times", stored)
+	}
+}
+
+func TestGetOrSetIncrement(t *testing.T) {
+	c := New(0, 0)
+	c.Set("n", 0, 0)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			c.Increment("n", 1)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			c.GetOrSet("n", 0, 0)
+		}
+	}()
+	wg.Wait()
+	if v, _ := c.Get("n"); v.(int) != 1000 {
+		t.Error("You get a wrong value", v)
+	}
+}
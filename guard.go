@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+)
+
+var (
+	// ErrKeyNotComparable is reported for keys which can not be used as a
+	// map key, like slices, maps and functions. Such keys make the cache
+	// panic when guards are off.
+	ErrKeyNotComparable = errors.New("The key is not comparable")
+	// ErrPointerKey is reported for pointer keys, and for structs and
+	// arrays holding pointers. They are compared by address, so an equal
+	// value built again misses the cache.
+	ErrPointerKey = errors.New("The key is compared by pointer")
+)
+
+type keyGuard struct {
+	reject bool
+	hook   func(key interface{}, err error)
+}
+
+// SetKeyGuard turn on the checking of keys on Get and Set. Keys which are
+// not comparable, or which are compared by pointer, are reported to the
+// hook (if not nil). If reject is true such keys are also refused: Set
+// ignores them and Get misses. Keys which are not comparable are always
+// refused by every method taking a key, instead of making the cache panic.
+// The hook may be called with the lock of the cache held, so it must not
+// use the cache.
+func (c *Cache) SetKeyGuard(reject bool, hook func(key interface{}, err error)) {
+	c.keyGuard.Store(&keyGuard{reject: reject, hook: hook})
+}
+
+// RemoveKeyGuard turn off the checking of keys.
+func (c *Cache) RemoveKeyGuard() {
+	c.keyGuard.Store((*keyGuard)(nil))
+}
+
+// keyAllowed tell if the key may be used, reporting it if it is bad. It
+// does not need the lock, so the methods call it before locking: a key
+// which is not comparable would panic in the map with the lock held.
+func (c *Cache) keyAllowed(key interface{}) bool {
+	g, _ := c.keyGuard.Load().(*keyGuard)
+	return g == nil || g.allow(key)
+}
+
+// allow report a bad key to the hook and tell if it may be used.
+func (g *keyGuard) allow(key interface{}) bool {
+	err := checkKey(key)
+	if err == nil {
+		return true
+	}
+	if g.hook != nil {
+		g.hook(key, err)
+	}
+	return !g.reject && err != ErrKeyNotComparable
+}
+
+// checkKey return an error if the key is likely to be misused.
+func checkKey(key interface{}) error {
+	if key == nil {
+		return nil
+	}
+	// The value is checked, not only the type: an interface field of a
+	// struct may hold a slice.
+	if !reflect.ValueOf(key).Comparable() {
+		return ErrKeyNotComparable
+	}
+	if hasPointer(reflect.TypeOf(key)) {
+		return ErrPointerKey
+	}
+	return nil
+}
+
+func hasPointer(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Chan, reflect.Interface:
+		return true
+	case reflect.Array:
+		return hasPointer(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasPointer(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyGuard(t *testing.T) {
+	type user struct {
+		ID int
+	}
+	type withPtr struct {
+		U *user
+	}
+	var reported []error
+	c := New(0, 0)
+	c.SetKeyGuard(false, func(key interface{}, err error) {
+		reported = append(reported, err)
+	})
+	c.Set(&user{1}, 1, 0)
+	c.Set(withPtr{&user{1}}, 2, 0)
+	c.Set([]int{1}, 3, 0)
+	c.Set(user{1}, 4, 0)
+	if len(reported) != 3 || reported[0] != ErrPointerKey || reported[1] != ErrPointerKey || reported[2] != ErrKeyNotComparable {
+		t.Error("You get wrong reports", reported)
+	}
+	if c.ItemCount() != 3 {
+		t.Error("Only the key which is not comparable must be refused")
+	}
+	if _, found := c.Get([]int{1}); found {
+		t.Error("The key which is not comparable must miss")
+	}
+
+	c = New(0, 0)
+	c.SetKeyGuard(true, nil)
+	c.Set(&user{1}, 1, 0)
+	c.Set("ok", 2, 0)
+	if c.ItemCount() != 1 {
+		t.Error("The pointer key must be refused")
+	}
+	c.RemoveKeyGuard()
+	c.Set(&user{1}, 1, 0)
+	if c.ItemCount() != 2 {
+		t.Error("The guard is off, the pointer key must be stored")
+	}
+}
+
+func TestKeyGuardEveryMethod(t *testing.T) {
+	c := New(0, 0)
+	c.SetKeyGuard(false, nil)
+	bad := []int{1}
+	nested := struct{ K interface{} }{bad}
+	for _, key := range []interface{}{bad, nested} {
+		c.Get(key)
+		c.Set(key, 1, 0)
+		c.Delete(key)
+		c.Touch(key, time.Minute)
+		c.Increment(key, 1)
+		c.Decrement(key, 1)
+		c.DeleteSoft(key, time.Minute)
+		c.SetForce(key, 1, 0)
+		c.Tombstoned(key)
+		c.Invalidate(key)
+		c.SetFenced(key, 1, 0, c.Fence())
+		c.GetOrSet(key, 1, 0)
+		c.GetItemInfo(key)
+		c.GetWithVersion(key)
+		c.SetIfVersion(key, 1, 0, 0)
+		c.SetRefreshing(key, true)
+		c.Append(key, "a")
+		c.ListPush(key, 1)
+		c.SetAdd(key, 1)
+		c.SetChunked(key, []byte("abc"), 2, 0)
+		c.GetChunked(key)
+		c.DeleteChunked(key)
+		c.GetOrLoad(key, 0, func() (interface{}, error) { return 1, nil })
+		_, cancel := c.Watch(key)
+		cancel()
+		c.Update(func(tx *Txn) error {
+			tx.Get(key)
+			tx.Set(key, 1, 0)
+			tx.Delete(key)
+			return nil
+		})
+		r := NewRateCounter(c)
+		r.IncrWithTTL(key, 1, time.Minute)
+		r.Allow(key, 1, time.Minute)
+		r.Count(key)
+	}
+	c.Set("ok", 1, 0)
+	if err := c.Increment("ok", 1); err != nil {
+		t.Error("Now, the cache must still work:", err)
+	}
+	if v, found := c.Get("ok"); !found || v.(int) != 2 {
+		t.Error("Now, the value must be 2:", v)
+	}
+	if c.ItemCount() != 1 {
+		t.Error("The keys which are not comparable must not be stored")
+	}
+}
+
+func TestKeyGuardIncrementMulti(t *testing.T) {
+	c := New(0, 0)
+	c.SetKeyGuard(true, nil)
+	p := new(int)
+	c.Set("a", 1, 0)
+	if err := c.IncrementMulti(map[interface{}]int64{"a": 1, p: 1}); err != ErrPointerKey {
+		t.Error("You get a wrong error", err)
+	}
+	if v, _ := c.Get("a"); v.(int) != 1 {
+		t.Error("Nothing must be changed", v)
+	}
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// Hasher hash the keys, to spread them over shards. The hash of two equal
+// keys must be equal.
+type Hasher interface {
+	Hash(key interface{}) uint64
+}
+
+// The built-in Hashers. DefaultHasher hashes the strings and the integers
+// directly and the other types through their fmt representation. The
+// others only accept one type of key, and panic on another one.
+var (
+	DefaultHasher Hasher = HasherFunc(hashKey)
+	StringHasher  Hasher = HasherFunc(func(key interface{}) uint64 { return hashString(key.(string)) })
+	IntHasher     Hasher = HasherFunc(func(key interface{}) uint64 { return mix64(uint64(key.(int))) })
+	FmtHasher     Hasher = HasherFunc(func(key interface{}) uint64 { return hashString(fmt.Sprintf("%T:%v", key, key)) })
+)
+
+// HasherFunc is a function used as a Hasher.
+type HasherFunc func(key interface{}) uint64
+
+// Hash return f(key).
+func (f HasherFunc) Hash(key interface{}) uint64 {
+	return f(key)
+}
+
+// hashKey return a 64 bits hash of a key. Strings and integers are hashed
+// directly, other types are hashed through their fmt representation.
+func hashKey(key interface{}) uint64 {
+	switch k := key.(type) {
+	case string:
+		return hashString(k)
+	case int:
+		return mix64(uint64(k))
+	case int8:
+		return mix64(uint64(k))
+	case int16:
+		return mix64(uint64(k))
+	case int32:
+		return mix64(uint64(k))
+	case int64:
+		return mix64(uint64(k))
+	case uint:
+		return mix64(uint64(k))
+	case uint8:
+		return mix64(uint64(k))
+	case uint16:
+		return mix64(uint64(k))
+	case uint32:
+		return mix64(uint64(k))
+	case uint64:
+		return mix64(k)
+	case uintptr:
+		return mix64(uint64(k))
+	case float64:
+		return hashFloat(k)
+	case float32:
+		return hashFloat(float64(k))
+	}
+	return hashString(fmt.Sprintf("%T:%v", key, key))
+}
+
+// hashFloat hash the bits of f, with -0 hashed like +0 since they are the
+// same key, and every NaN hashed alike.
+func hashFloat(f float64) uint64 {
+	if f == 0 {
+		f = 0
+	} else if math.IsNaN(f) {
+		f = math.NaN()
+	}
+	return mix64(math.Float64bits(f))
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return mix64(h.Sum64())
+}
+
+// mix64 is the finalizer of MurmurHash3, spreading the bits of x.
+func mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"math"
+	"testing"
+)
+
+type point struct{ x, y int }
+
+func TestHashers(t *testing.T) {
+	if DefaultHasher.Hash("a") != StringHasher.Hash("a") || DefaultHasher.Hash(1) != IntHasher.Hash(1) {
+		t.Error("The default hasher must hash the strings and ints directly")
+	}
+	if FmtHasher.Hash(point{1, 2}) != FmtHasher.Hash(point{1, 2}) || FmtHasher.Hash(point{1, 2}) == FmtHasher.Hash(point{2, 1}) {
+		t.Error("You get wrong hashes")
+	}
+	var h Hasher = HasherFunc(func(key interface{}) uint64 { return uint64(key.(point).x) })
+	if h.Hash(point{3, 4}) != 3 {
+		t.Error("Impossiable!")
+	}
+}
+
+func TestHashFloatZero(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	if hashKey(negZero) != hashKey(0.0) || hashKey(float32(negZero)) != hashKey(float32(0)) {
+		t.Error("-0 and +0 are the same key")
+	}
+	if hashKey(math.NaN()) != hashKey(math.Float64frombits(0x7ff8000000000002)) {
+		t.Error("The NaNs must be hashed alike")
+	}
+	if hashKey(1.5) == hashKey(2.5) {
+		t.Error("Impossiable!")
+	}
+}
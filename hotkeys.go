@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// KeyCount is a key with its number of hits.
+type KeyCount struct {
+	Key  interface{}
+	Hits int64
+}
+
+// TrackHotKeys start counting the hits and the last access of every item,
+// and the hits of the last window for HotKeys. It costs a few atomic
+// operations on every Get. The window is 0 stops the tracking.
+func (c *Cache) TrackHotKeys(window time.Duration) {
+	if window < 0 {
+		window = 0
+	}
+	c.Lock()
+	c.hotKeyWindow = window
+	c.Unlock()
+}
+
+// recordHotKey count a hit in the window of the item. A window starts with
+// the first hit after the previous one ended. The caller must hold the
+// lock.
+func (c *Cache) recordHotKey(item *Item) {
+	now := c.now().UnixNano()
+	start := atomic.LoadInt64(&item.windowStart)
+	if now-start >= int64(c.hotKeyWindow) && atomic.CompareAndSwapInt64(&item.windowStart, start, now) {
+		atomic.StoreInt64(&item.windowHits, 1)
+		return
+	}
+	atomic.AddInt64(&item.windowHits, 1)
+}
+
+// HotKeys return the n keys with the most hits in their last window, most
+// hit first. It scans every item. It returns nil if n <= 0.
+func (c *Cache) HotKeys(n int) []KeyCount {
+	if n <= 0 {
+		return nil
+	}
+	c.RLock()
+	window := int64(c.hotKeyWindow)
+	if window == 0 {
+		c.RUnlock()
+		return nil
+	}
+	now := c.now().UnixNano()
+	var keys []KeyCount
+	for k, item := range c.items {
+		if now-atomic.LoadInt64(&item.windowStart) >= window || c.expired(item) {
+			continue
+		}
+		keys = append(keys, KeyCount{Key: k, Hits: atomic.LoadInt64(&item.windowHits)})
+	}
+	c.RUnlock()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Hits > keys[j].Hits })
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHotKeys(t *testing.T) {
+	c := New(0, 0)
+	if c.HotKeys(1) != nil {
+		t.Error("The hot keys are not tracked")
+	}
+	c.TrackHotKeys(time.Hour)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, 0)
+	for i := 0; i < 3; i++ {
+		c.Get("b")
+	}
+	c.Get("a")
+	keys := c.HotKeys(2)
+	if len(keys) != 2 || keys[0].Key != "b" || keys[0].Hits != 3 || keys[1].Key != "a" {
+		t.Errorf("You get wrong hot keys %v", keys)
+	}
+	if c.HotKeys(0) != nil || c.HotKeys(-1) != nil {
+		t.Error("No hot keys are asked")
+	}
+	info, _ := c.GetItemInfo("b")
+	if info.Hits != 3 || time.Since(info.LastAccess) > time.Second {
+		t.Errorf("You get wrong access info %+v", info)
+	}
+
+	c.TrackHotKeys(50 * time.Millisecond)
+	time.Sleep(60 * time.Millisecond)
+	c.Get("a")
+	if keys := c.HotKeys(2); len(keys) != 1 || keys[0].Key != "a" || keys[0].Hits != 1 {
+		t.Errorf("Now, the window of b is over: %v", keys)
+	}
+}
@@ -0,0 +1,238 @@
+// Package httpcache is an HTTP middleware caching the responses to GET
+// requests in a cache.Cache, keyed by the URL and the request headers named
+// by the Vary header of the response. The freshness of a response follows
+// its Cache-Control header.
+//
+//	m := httpcache.New(cache.New(time.Minute, time.Minute), 0)
+//	http.ListenAndServe(":8080", m.Wrap(handler))
+package httpcache
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+// DefaultMaxBodySize is the largest body cached by default.
+const DefaultMaxBodySize = 1 << 20
+
+// Middleware cache the responses of a http.Handler. A response is cached
+// if its status is 200, 203, 301, 404 or 410, it has no Set-Cookie header,
+// and its Cache-Control does not say no-store, no-cache or private. It is
+// kept for the s-maxage or max-age of its Cache-Control, or for the
+// default TTL. A request with Cache-Control no-cache or max-age=0 skips the
+// cached response and replaces it, and one with no-store is not cached.
+type Middleware struct {
+	c           *cache.Cache
+	defaultTTL  time.Duration
+	maxBodySize int64
+}
+
+// variants is the entry of a URL, listing the request headers its
+// responses vary on.
+type variants struct {
+	vary []string
+}
+
+// response is a cached response.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+	stored time.Time
+}
+
+// New create a Middleware caching in c. The responses without max-age are
+// cached for defaultTTL, or not cached if it is 0.
+func New(c *cache.Cache, defaultTTL time.Duration) *Middleware {
+	return &Middleware{c: c, defaultTTL: defaultTTL, maxBodySize: DefaultMaxBodySize}
+}
+
+// SetMaxBodySize set the size of the largest body cached.
+func (m *Middleware) SetMaxBodySize(n int64) {
+	m.maxBodySize = n
+}
+
+// Wrap return a handler serving the cached responses of next, or calling
+// it and caching its response. It is a func(http.Handler) http.Handler.
+func (m *Middleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCC := parseCacheControl(r.Header.Get("Cache-Control"))
+		if r.Method != http.MethodGet || reqCC.has("no-store") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := baseKey(r.Host, r.URL.RequestURI())
+		if !reqCC.has("no-cache") && reqCC["max-age"] != "0" {
+			if resp, ok := m.lookup(key, r); ok {
+				serve(w, resp)
+				return
+			}
+		}
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK, max: m.maxBodySize}
+		next.ServeHTTP(rec, r)
+		m.store(key, r, rec)
+	})
+}
+
+// Invalidate delete the cached responses of an absolute URL, like
+// "http://example.com/path?q=1". The scheme is ignored.
+func (m *Middleware) Invalidate(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	m.c.Delete(baseKey(u.Host, u.RequestURI()))
+	return nil
+}
+
+func (m *Middleware) lookup(key string, r *http.Request) (*response, bool) {
+	val, _ := m.c.Get(key)
+	v, ok := val.(*variants)
+	if !ok {
+		return nil, false
+	}
+	val, _ = m.c.Get(variantKey(key, v.vary, r))
+	resp, ok := val.(*response)
+	return resp, ok
+}
+
+func (m *Middleware) store(key string, r *http.Request, rec *recorder) {
+	if rec.overflow || !cacheableStatus(rec.status) || rec.Header().Get("Set-Cookie") != "" {
+		return
+	}
+	cc := parseCacheControl(rec.Header().Get("Cache-Control"))
+	if cc.has("no-store") || cc.has("no-cache") || cc.has("private") {
+		return
+	}
+	ttl := m.defaultTTL
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if v, ok := cc[directive]; ok {
+			secs, err := strconv.Atoi(v)
+			if err != nil {
+				return
+			}
+			ttl = time.Duration(secs) * time.Second
+			break
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+	var vary []string
+	for _, v := range rec.Header()["Vary"] {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name == "*" {
+				return
+			} else if name != "" {
+				vary = append(vary, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	resp := &response{
+		status: rec.status,
+		header: rec.Header().Clone(),
+		body:   rec.body.Bytes(),
+		stored: time.Now(),
+	}
+	m.c.Set(key, &variants{vary: vary}, ttl)
+	m.c.Set(variantKey(key, vary, r), resp, ttl)
+}
+
+func serve(w http.ResponseWriter, resp *response) {
+	h := w.Header()
+	for k, v := range resp.header {
+		h[k] = v
+	}
+	h.Set("Age", strconv.Itoa(int(time.Since(resp.stored)/time.Second)))
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
+
+func baseKey(host, requestURI string) string {
+	return "httpcache GET " + host + requestURI
+}
+
+// variantKey append the values of the vary headers of the request to key,
+// each on a line.
+func variantKey(key string, vary []string, r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(key)
+	b.WriteString("\n")
+	for _, name := range vary {
+		b.WriteString(name)
+		b.WriteString(": ")
+		b.WriteString(strings.Join(r.Header[name], ", "))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func cacheableStatus(status int) bool {
+	switch status {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMovedPermanently,
+		http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return false
+}
+
+// cacheControl is the directives of a Cache-Control header, with their
+// value or "".
+type cacheControl map[string]string
+
+func parseCacheControl(header string) cacheControl {
+	cc := cacheControl{}
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value := part, ""
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			name, value = part[:i], strings.Trim(part[i+1:], `"`)
+		}
+		cc[strings.ToLower(name)] = value
+	}
+	return cc
+}
+
+func (cc cacheControl) has(directive string) bool {
+	_, ok := cc[directive]
+	return ok
+}
+
+// recorder write a response through and keep a copy of it.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	max         int64
+	overflow    bool
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	if !r.overflow {
+		if int64(r.body.Len()+len(p)) > r.max {
+			r.overflow = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(p)
+		}
+	}
+	return r.ResponseWriter.Write(p)
+}
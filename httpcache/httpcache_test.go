@@ -0,0 +1,90 @@
+package httpcache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/private":
+			w.Header().Set("Cache-Control", "private")
+		case "/vary":
+			w.Header().Set("Vary", "Accept-Language")
+		case "/short":
+			w.Header().Set("Cache-Control", "max-age=1")
+		}
+		fmt.Fprintf(w, "%s %d %s", r.URL.Path, calls, r.Header.Get("Accept-Language"))
+	})
+	m := New(cache.New(0, 0), time.Minute)
+	h := m.Wrap(handler)
+	get := func(path string, header ...string) string {
+		req := httptest.NewRequest("GET", "http://example.com"+path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	if get("/a") != "/a 1 " || get("/a") != "/a 1 " {
+		t.Error("The response must be cached")
+	}
+	if get("/a", "Cache-Control", "no-cache") != "/a 2 " || get("/a") != "/a 2 " {
+		t.Error("The response must be refreshed")
+	}
+	if get("/private") != "/private 3 " || get("/private") != "/private 4 " {
+		t.Error("The private responses must not be cached")
+	}
+	if get("/vary", "Accept-Language", "fr") != "/vary 5 fr" ||
+		get("/vary", "Accept-Language", "en") != "/vary 6 en" ||
+		get("/vary", "Accept-Language", "fr") != "/vary 5 fr" {
+		t.Error("The responses must vary on Accept-Language")
+	}
+	if err := m.Invalidate("http://example.com/a"); err != nil {
+		t.Fatal(err)
+	}
+	if get("/a") != "/a 7 " {
+		t.Error("Now, the response is invalidated")
+	}
+	get("/short")
+	time.Sleep(1100 * time.Millisecond)
+	if get("/short") != "/short 9 " {
+		t.Error("The max-age must be honored")
+	}
+	m.SetMaxBodySize(2)
+	if get("/big") != "/big 10 " || get("/big") != "/big 11 " {
+		t.Error("The large bodies must not be cached")
+	}
+	req := httptest.NewRequest("POST", "http://example.com/a", nil)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if calls != 12 {
+		t.Error("The POST requests must not be cached")
+	}
+}
+
+func TestAge(t *testing.T) {
+	m := New(cache.New(0, 0), time.Minute)
+	h := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "1")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != http.StatusNotFound || w.Header().Get("X-Test") != "1" {
+			t.Errorf("You get a wrong response %d %v", w.Code, w.Header())
+		}
+		if (i == 1) != (w.Header().Get("Age") != "") {
+			t.Error("Only the cached responses have an Age")
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"time"
+)
+
+// Entry is an item given to Import.
+type Entry struct {
+	Key        interface{} `json:"key"`
+	Value      interface{} `json:"value"`
+	Expiration *time.Time  `json:"expiration,omitempty"`
+	// Written is when the value was written, used by KeepNewer. The time of
+	// the import is used if it is zero.
+	Written time.Time `json:"written"`
+}
+
+// ConflictPolicy tells Import what to do with an entry whose key is already
+// in the cache.
+type ConflictPolicy int
+
+const (
+	// KeepExisting skips the entry.
+	KeepExisting ConflictPolicy = iota
+	// Overwrite replaces the existing item.
+	Overwrite
+	// KeepNewer replaces the existing item if the entry was written after
+	// it.
+	KeepNewer
+	// Merge replaces the existing item by the value returned by the merge
+	// function set by SetMergeFunc, or works like KeepNewer if there is none.
+	Merge
+)
+
+// MergeFunc combine the existing value a of a key with the incoming value b,
+// for example to union sets or take the max of counters. It must give the
+// same result whatever the order the writes arrive in, so the replicas
+// converge.
+type MergeFunc func(key, a, b interface{}) interface{}
+
+// SetMergeFunc set the function used by Import with the Merge policy. Set
+// to nil to remove it.
+func (c *Cache) SetMergeFunc(f MergeFunc) {
+	c.Lock()
+	c.merge = f
+	c.Unlock()
+}
+
+// ImportSummary tells how many entries Import applied, skipped and failed
+// to apply. The entries fail when they do not fit a full cache, see
+// SetFullPolicy, or the max value size, see SetMaxValueSize.
+type ImportSummary struct {
+	Applied int
+	Skipped int
+	Failed  int
+}
+
+// Import add entries to the cache, resolving the conflicts with existing
+// items by policy. Expired entries, and entries whose key is not
+// comparable, is refused by the key guard or the access check or has a
+// tombstone, are skipped.
+func (c *Cache) Import(entries []Entry, policy ConflictPolicy) ImportSummary {
+	var summary ImportSummary
+	c.Lock()
+	defer c.unlockAndNotify()
+	now := c.now()
+	for _, e := range entries {
+		item := &Item{Object: e.Value, Expiration: e.Expiration, written: now.UnixNano()}
+		if !e.Written.IsZero() {
+			item.written = e.Written.UnixNano()
+		}
+		if item.expiredAt(now) || checkKey(e.Key) == ErrKeyNotComparable || !c.keyAllowed(e.Key) || c.access(e.Key, OpSet) != nil || c.tombstoned(e.Key) {
+			summary.Skipped++
+			continue
+		}
+		if old, ok := c.items[e.Key]; ok && !old.expiredAt(now) {
+			switch policy {
+			case KeepExisting:
+				summary.Skipped++
+				continue
+			case Merge:
+				if c.merge != nil {
+					item = mergeItems(c.merge(e.Key, old.Object, item.Object), old, item)
+					break
+				}
+				fallthrough
+			case KeepNewer:
+				if item.written <= old.written {
+					summary.Skipped++
+					continue
+				}
+			}
+		}
+		if err := c.restore(e.Key, item); err != nil {
+			summary.Failed++
+			continue
+		}
+		summary.Applied++
+	}
+	return summary
+}
+
+// restore store an item imported or loaded, whose value must fit the max
+// value size like the values of Set. The caller must hold the lock.
+func (c *Cache) restore(key interface{}, item *Item) error {
+	val, err := c.limitValue(item.Object)
+	if err != nil {
+		return err
+	}
+	item.Object = val
+	if err := c.insert(key, item); err != nil {
+		return err
+	}
+	c.publishInvalidation(key)
+	return nil
+}
+
+// mergeItems return an item holding the merged value, which expires and was
+// written at the latest of a and b.
+func mergeItems(value interface{}, a, b *Item) *Item {
+	item := &Item{Object: value, Expiration: a.Expiration, written: a.written}
+	if a.Expiration != nil && (b.Expiration == nil || b.Expiration.After(*a.Expiration)) {
+		item.Expiration = b.Expiration
+	}
+	if b.written > item.written {
+		item.written = b.written
+	}
+	return item
+}
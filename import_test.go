@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestImport(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	entries := []Entry{
+		{Key: "old", Value: "imported", Written: past},
+		{Key: "new", Value: "imported", Written: time.Now().Add(time.Hour)},
+		{Key: "missing", Value: "imported"},
+		{Key: "expired", Value: "imported", Expiration: &past},
+	}
+	tests := []struct {
+		policy  ConflictPolicy
+		summary ImportSummary
+		old     interface{}
+		new     interface{}
+	}{
+		{KeepExisting, ImportSummary{1, 3, 0}, "existing", "existing"},
+		{Overwrite, ImportSummary{3, 1, 0}, "imported", "imported"},
+		{KeepNewer, ImportSummary{2, 2, 0}, "existing", "imported"},
+	}
+	for _, test := range tests {
+		c := New(0, 0)
+		c.Set("old", "existing", 0)
+		c.Set("new", "existing", 0)
+		summary := c.Import(entries, test.policy)
+		if summary != test.summary {
+			t.Errorf("Policy %d: you get a wrong summary %v", test.policy, summary)
+		}
+		if val, _ := c.Get("old"); val != test.old {
+			t.Errorf("Policy %d: you get a wrong value %v", test.policy, val)
+		}
+		if val, _ := c.Get("new"); val != test.new {
+			t.Errorf("Policy %d: you get a wrong value %v", test.policy, val)
+		}
+		if val, _ := c.Get("missing"); val != "imported" {
+			t.Errorf("Policy %d: the missing key must be imported", test.policy)
+		}
+	}
+}
+
+func TestImportMerge(t *testing.T) {
+	c := New(0, 0)
+	c.Set("counter", 5, -1)
+	entries := []Entry{{Key: "counter", Value: 3, Written: time.Now().Add(time.Hour)}, {Key: "other", Value: 7}}
+	c.Import(entries, Merge)
+	if val, _ := c.Get("counter"); val != 3 {
+		t.Error("Without merge function, the newer value must be kept")
+	}
+	c.SetMergeFunc(func(key, a, b interface{}) interface{} {
+		if a.(int) > b.(int) {
+			return a
+		}
+		return b
+	})
+	c.Set("counter", 5, -1)
+	summary := c.Import(entries, Merge)
+	if val, _ := c.Get("counter"); val != 5 || summary.Applied != 2 {
+		t.Error("The max of the counters must be kept")
+	}
+}
+
+func TestImportKeepNewerZeroWritten(t *testing.T) {
+	c := New(0, 0)
+	c.Set("a", "existing", 0)
+	time.Sleep(time.Millisecond)
+	summary := c.Import([]Entry{{Key: "a", Value: "imported"}}, KeepNewer)
+	if val, _ := c.Get("a"); val != "imported" || summary.Applied != 1 {
+		t.Error("The entry without write time is written at the import, after the existing item", val)
+	}
+	info, _ := c.GetItemInfo("a")
+	if info.Written.Before(time.Now().Add(-time.Second)) {
+		t.Error("The write time must be the time of the import", info.Written)
+	}
+}
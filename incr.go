@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"fmt"
+)
+
+// IncrementMulti add a number to every key-value pair of deltas under one
+// lock, so flushing many counters does not take the lock for each. The
+// deltas are applied all or none: if a key is not found or its value is
+// not an integer, nothing is changed and an error is returned. A key
+// refused by the key guard, see SetKeyGuard, makes it return the error of
+// the guard.
+func (c *Cache) IncrementMulti(deltas map[interface{}]int64) error {
+	for key := range deltas {
+		if !c.keyAllowed(key) {
+			return checkKey(key)
+		}
+	}
+	c.Lock()
+	defer c.Unlock()
+	for key := range deltas {
+		if err := c.access(key, OpSet); err != nil {
+			return err
+		}
+	}
+	results := make(map[interface{}]interface{}, len(deltas))
+	for key, x := range deltas {
+		val, ok := c.items[key]
+		if !ok || c.expired(val) {
+			return fmt.Errorf("Item %v not found", key)
+		}
+		sum, ok := addInt(val.Object, x)
+		if !ok {
+			return fmt.Errorf("The value type of item %v error", key)
+		}
+		results[key] = sum
+	}
+	now := c.now().UnixNano()
+	for key, sum := range results {
+		val := c.writable(key, c.items[key])
+		val.Object = sum
+		val.written = now
+		val.version = c.nextVersion()
+		c.watchEvent(EventSet, key, sum)
+		c.publishInvalidation(key)
+	}
+	return nil
+}
+
+// addInt return v plus x if v is an integer.
+func addInt(v interface{}, x int64) (interface{}, bool) {
+	switch n := v.(type) {
+	case int:
+		return n + int(x), true
+	case int8:
+		return n + int8(x), true
+	case int16:
+		return n + int16(x), true
+	case int32:
+		return n + int32(x), true
+	case int64:
+		return n + x, true
+	case uint:
+		return n + uint(x), true
+	case uint8:
+		return n + uint8(x), true
+	case uint16:
+		return n + uint16(x), true
+	case uint32:
+		return n + uint32(x), true
+	case uint64:
+		return n + uint64(x), true
+	case uintptr:
+		return n + uintptr(x), true
+	}
+	return nil, false
+}
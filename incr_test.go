@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestIncrementMulti(t *testing.T) {
+	c := New(0, 0)
+	c.Set("a", 1, 0)
+	c.Set("b", uint8(1), 0)
+	c.Set("c", "text", 0)
+	if err := c.IncrementMulti(map[interface{}]int64{"a": 2, "b": -1}); err != nil {
+		t.Fatal(err)
+	}
+	if a, _ := c.Get("a"); a != 3 {
+		t.Error("You get a wrong value", a)
+	}
+	if b, _ := c.Get("b"); b != uint8(0) {
+		t.Error("You get a wrong value", b)
+	}
+	if err := c.IncrementMulti(map[interface{}]int64{"a": 1, "c": 1}); err == nil {
+		t.Error("Impossiable!")
+	}
+	if err := c.IncrementMulti(map[interface{}]int64{"a": 1, "missing": 1}); err == nil {
+		t.Error("Impossiable!")
+	}
+	if a, _ := c.Get("a"); a != 3 {
+		t.Error("Nothing must be changed on error", a)
+	}
+}
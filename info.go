@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ItemInfo describe an item of the cache.
+type ItemInfo struct {
+	Value      interface{}
+	Expiration *time.Time
+	// Written is when the value was last written, by Set, Increment or
+	// Decrement, or when it was loaded. Replicas can use it to resolve
+	// conflicting writes, see Import with KeepNewer.
+	Written time.Time
+	// Created is when the key was stored in the cache. Unlike Written, it
+	// is kept when the value is replaced, until the key is removed or
+	// expired.
+	Created time.Time
+	// Version is the version of the value, see GetWithVersion.
+	Version uint64
+	// Hits is the number of Get which found the item, and LastAccess the
+	// time of the last of them. They are only tracked with TrackHotKeys.
+	Hits       int64
+	LastAccess time.Time
+	// Cost is the estimated size of the value in bytes, see EstimateSize.
+	Cost int64
+	// State is the stage of the lifecycle of the item.
+	State ItemState
+}
+
+// GetItem is GetItemInfo.
+func (c *Cache) GetItem(key interface{}) (ItemInfo, bool) {
+	return c.GetItemInfo(key)
+}
+
+// GetItemInfo return the value of an item with its metadata, and a bool
+// indicating whether the key was found, to find out why an item is still in
+// the cache or already gone. Unlike Get, it returns the stale items, see
+// SetStaleWindow. It does not count as a hit.
+func (c *Cache) GetItemInfo(key interface{}) (ItemInfo, bool) {
+	if !c.keyAllowed(key) {
+		return ItemInfo{}, false
+	}
+	c.RLock()
+	defer c.RUnlock()
+	if c.access(key, OpGet) != nil {
+		return ItemInfo{}, false
+	}
+	item, ok := c.items[key]
+	if !ok || c.removable(item) {
+		return ItemInfo{}, false
+	}
+	info := ItemInfo{
+		Value:      c.copyValue(item.Object),
+		Expiration: item.Expiration,
+		Written:    time.Unix(0, item.written),
+		Created:    time.Unix(0, item.created),
+		Version:    item.version,
+		Cost:       sizeOf(item.Object),
+		State:      c.state(item),
+	}
+	if c.hotKeyWindow > 0 {
+		info.Hits = atomic.LoadInt64(&item.hits)
+		info.LastAccess = time.Unix(0, atomic.LoadInt64(&item.accessed))
+	}
+	return info, true
+}
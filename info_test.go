@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetItemInfo(t *testing.T) {
+	c := New(0, 0)
+	if _, found := c.GetItemInfo("key"); found {
+		t.Error("You should not get")
+	}
+	before := time.Now()
+	c.Set("key", 1, time.Hour)
+	info, found := c.GetItemInfo("key")
+	if !found || info.Value != 1 || info.Expiration == nil {
+		t.Error("You get wrong info", info)
+	}
+	if info.Written.Before(before) || info.Written.After(time.Now()) {
+		t.Error("You get a wrong write time", info.Written)
+	}
+	time.Sleep(time.Millisecond)
+	c.Increment("key", 1)
+	if info2, _ := c.GetItemInfo("key"); !info2.Written.After(info.Written) {
+		t.Error("Increment must update the write time")
+	}
+	written := time.Now().Add(-time.Hour)
+	c.Import([]Entry{{Key: "imported", Value: 2, Written: written}}, Overwrite)
+	if info, _ := c.GetItemInfo("imported"); !info.Written.Equal(written) {
+		t.Error("Import must keep the write time", info.Written)
+	}
+}
+
+func TestGetItem(t *testing.T) {
+	c := New(0, 0)
+	clk := &fixedClock{now: time.Unix(1000, 0)}
+	c.SetClock(clk)
+	c.Set("key", "value", time.Hour)
+	clk.now = clk.now.Add(time.Minute)
+	c.Set("key", "new value", time.Hour)
+	info, found := c.GetItem("key")
+	if !found || info.Value != "new value" {
+		t.Error("You get wrong info", info)
+	}
+	if !info.Created.Equal(time.Unix(1000, 0)) || !info.Written.Equal(clk.now) {
+		t.Error("The creation time must be kept by Set", info.Created, info.Written)
+	}
+	if info.Cost != estimateSize("new value") {
+		t.Error("You get a wrong cost", info.Cost)
+	}
+	c.Delete("key")
+	c.Set("key", 1, 0)
+	if info, _ := c.GetItem("key"); !info.Created.Equal(clk.now) {
+		t.Error("Now, the key is created again", info.Created)
+	}
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Transport carries the messages of an InvalidationBus between the
+// processes sharing it, like a Redis channel, see the redis subpackage, or
+// UDP, see UDPTransport.
+type Transport interface {
+	// Publish send a message to the subscribers of the other processes. It
+	// may also be delivered to this one, which the bus ignores.
+	Publish(msg []byte) error
+	// Subscribe call f with the messages published by every process, in
+	// order, until the transport is closed, then return.
+	Subscribe(f func(msg []byte)) error
+	// Close the transport.
+	Close() error
+}
+
+// InvalidationBus keep the caches of several processes roughly coherent:
+// the keys set or deleted in a cache are published to its peers, which
+// delete them locally, so they load the new value at the next Get. Every
+// write publishes: the sets, deletes, Invalidate, DeleteSoft, increments,
+// collection updates, transactions and imports, but not the values loaded
+// from the Store or by Warm, nor the expired and evicted keys.
+// Keys are sent formatted with fmt.Sprint and deleted by the peers as
+// strings, so the bus only works for string keys. Keys are published
+// asynchronously, so the concurrent writes of a key in two processes may
+// delete it in both.
+type InvalidationBus struct {
+	c          *Cache
+	t          Transport
+	id         []byte
+	queue      chan string
+	published  chan struct{}
+	subscribed chan struct{}
+	dropped    uint64
+
+	// closing guards the queue against the publishers.
+	closing sync.RWMutex
+	closed  bool
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+// invalidationQueueSize is the number of keys waiting to be published
+// before the next ones are dropped.
+const invalidationQueueSize = 1024
+
+// NewInvalidationBus attach c to the peers sharing t. The keys are
+// published in the background, in order.
+func NewInvalidationBus(c *Cache, t Transport) (*InvalidationBus, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	b := &InvalidationBus{
+		c:          c,
+		t:          t,
+		id:         []byte(hex.EncodeToString(id)),
+		queue:      make(chan string, invalidationQueueSize),
+		published:  make(chan struct{}),
+		subscribed: make(chan struct{}),
+	}
+	c.Lock()
+	if old, _ := c.bus.Load().(*InvalidationBus); old != nil {
+		c.Unlock()
+		return nil, errors.New("The cache already has an invalidation bus")
+	}
+	c.bus.Store(b)
+	c.Unlock()
+	go b.publish()
+	go b.subscribe()
+	return b, nil
+}
+
+// Dropped return the number of keys not published because the queue was
+// full.
+func (b *InvalidationBus) Dropped() uint64 {
+	return atomic.LoadUint64(&b.dropped)
+}
+
+// LastError return the error of the last publish or of the subscription,
+// or nil.
+func (b *InvalidationBus) LastError() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastErr
+}
+
+// Close detach the cache, publish the queued keys and close the transport.
+func (b *InvalidationBus) Close() error {
+	b.c.bus.Store((*InvalidationBus)(nil))
+	b.closing.Lock()
+	if b.closed {
+		b.closing.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.queue)
+	b.closing.Unlock()
+	<-b.published
+	err := b.t.Close()
+	<-b.subscribed
+	return err
+}
+
+func (b *InvalidationBus) setErr(err error) {
+	b.mu.Lock()
+	b.lastErr = err
+	b.mu.Unlock()
+}
+
+func (b *InvalidationBus) enqueue(key string) {
+	b.closing.RLock()
+	defer b.closing.RUnlock()
+	if b.closed {
+		return
+	}
+	select {
+	case b.queue <- key:
+	default:
+		atomic.AddUint64(&b.dropped, 1)
+	}
+}
+
+func (b *InvalidationBus) publish() {
+	defer close(b.published)
+	for key := range b.queue {
+		// The message is the id of the publisher and the key, separated by
+		// a newline.
+		msg := append(append(append([]byte(nil), b.id...), '\n'), key...)
+		if err := b.t.Publish(msg); err != nil {
+			b.setErr(err)
+		}
+	}
+}
+
+func (b *InvalidationBus) subscribe() {
+	defer close(b.subscribed)
+	err := b.t.Subscribe(func(msg []byte) {
+		i := bytes.IndexByte(msg, '\n')
+		if i < 0 || bytes.Equal(msg[:i], b.id) {
+			return
+		}
+		b.c.invalidateLocal(string(msg[i+1:]))
+	})
+	if err != nil {
+		b.setErr(err)
+	}
+}
+
+// publishInvalidation publish a key set or deleted, if the cache has a bus.
+// It does not block, so it can be called with the lock held.
+func (c *Cache) publishInvalidation(key interface{}) {
+	if b, _ := c.bus.Load().(*InvalidationBus); b != nil {
+		b.enqueue(fmt.Sprint(key))
+	}
+}
+
+// invalidateLocal delete a key invalidated by a peer, without publishing it
+// or deleting it from the Store.
+func (c *Cache) invalidateLocal(key interface{}) {
+	c.Lock()
+	if item, ok := c.items[key]; ok {
+		delete(c.items, key)
+		atomic.AddUint64(&c.stats.deletes, 1)
+		c.removed(key, item)
+	}
+	c.unlockAndNotify()
+}
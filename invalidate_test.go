@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// testHub is a Transport delivering the messages to all the subscribers of
+// the hub in memory.
+type testHub struct {
+	sync.Mutex
+	subs []chan []byte
+}
+
+type testTransport struct {
+	hub  *testHub
+	msgs chan []byte
+}
+
+func (h *testHub) transport() *testTransport {
+	t := &testTransport{hub: h, msgs: make(chan []byte, 100)}
+	h.Lock()
+	h.subs = append(h.subs, t.msgs)
+	h.Unlock()
+	return t
+}
+
+func (t *testTransport) Publish(msg []byte) error {
+	t.hub.Lock()
+	defer t.hub.Unlock()
+	for _, sub := range t.hub.subs {
+		sub <- msg
+	}
+	return nil
+}
+
+func (t *testTransport) Subscribe(f func([]byte)) error {
+	for msg := range t.msgs {
+		f(msg)
+	}
+	return nil
+}
+
+func (t *testTransport) Close() error {
+	t.hub.Lock()
+	defer t.hub.Unlock()
+	for i, sub := range t.hub.subs {
+		if sub == t.msgs {
+			t.hub.subs = append(t.hub.subs[:i], t.hub.subs[i+1:]...)
+			close(sub)
+		}
+	}
+	return nil
+}
+
+func TestInvalidationBus(t *testing.T) {
+	hub := &testHub{}
+	a, b := New(0, 0), New(0, 0)
+	busA, err := NewInvalidationBus(a, hub.transport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	busB, _ := NewInvalidationBus(b, hub.transport())
+	if _, err := NewInvalidationBus(a, hub.transport()); err == nil {
+		t.Error("Impossiable!")
+	}
+	b.Set("k", "old", 0)
+	time.Sleep(10 * time.Millisecond)
+	a.Set("k", "new", 0)
+	time.Sleep(10 * time.Millisecond)
+	if _, found := b.Get("k"); found {
+		t.Error("The key set by a peer must be deleted")
+	}
+	if val, _ := a.Get("k"); val != "new" {
+		t.Error("The publisher must keep its value")
+	}
+	busA.Close()
+	a.Set("x", 1, 0)
+	b.Set("x", 2, 0)
+	b.Delete("k")
+	time.Sleep(10 * time.Millisecond)
+	if _, found := a.Get("x"); !found {
+		t.Error("Now, the cache is detached from the bus")
+	}
+	if busB.Close() != nil || busB.Dropped() != 0 {
+		t.Error("Impossiable!")
+	}
+}
+
+func TestInvalidationBusWrites(t *testing.T) {
+	hub := &testHub{}
+	a, b := New(0, 0), New(0, 0)
+	busA, _ := NewInvalidationBus(a, hub.transport())
+	defer busA.Close()
+	busB, _ := NewInvalidationBus(b, hub.transport())
+	defer busB.Close()
+	a.Set("n", 1, 0)
+	a.Set("k", "a", 0)
+	a.Set("loaded", "a", 0)
+	time.Sleep(10 * time.Millisecond)
+	// The keys loaded by b are not published, so a keeps them.
+	b.SetStore(&mapStore{data: map[interface{}]interface{}{"n": 1, "k": "b", "loaded": "b"}})
+	b.Get("n")
+	b.Get("k")
+	time.Sleep(10 * time.Millisecond)
+	if err := a.Increment("n", 1); err != nil {
+		t.Fatal(err)
+	}
+	a.Invalidate("k")
+	b.Get("loaded")
+	time.Sleep(10 * time.Millisecond)
+	if _, found := b.GetItemInfo("n"); found {
+		t.Error("The key incremented by a peer must be deleted")
+	}
+	if _, found := b.GetItemInfo("k"); found {
+		t.Error("The key invalidated by a peer must be deleted")
+	}
+	if _, found := a.Get("loaded"); !found {
+		t.Error("The values loaded from the Store must not be published")
+	}
+}
@@ -0,0 +1,256 @@
+package cache
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// janitor delete the expired items of a cache periodically.
+type janitor struct {
+	interval time.Duration
+	stop     chan struct{}
+	once     sync.Once
+	wg       sync.WaitGroup
+	// paced is the generation of the GC pacing, 0 when it is off. gc
+	// receives a signal after every GC cycle while it is on.
+	paced int32
+	gc    chan struct{}
+	// reset receives the new intervals, and trigger the requests of an
+	// immediate cleanup. The cleanups are skipped while paused is 1.
+	reset   chan time.Duration
+	trigger chan struct{}
+	paused  int32
+	// clocks receives the clocks set by SetClock.
+	clocks chan clockChange
+	// failures is the number of cleanups failed in a row, and backoff the
+	// number of ticks to skip before the next one. They are only used by
+	// the janitor goroutine.
+	failures int
+	backoff  int
+}
+
+type clockChange struct {
+	clock Clock
+	done  chan struct{}
+}
+
+func newJanitor(interval time.Duration) *janitor {
+	j := &janitor{
+		interval: interval,
+		stop:     make(chan struct{}),
+		gc:       make(chan struct{}, 1),
+		reset:    make(chan time.Duration),
+		trigger:  make(chan struct{}, 1),
+		clocks:   make(chan clockChange),
+	}
+	j.wg.Add(1)
+	return j
+}
+
+// run the cleanups at every tick of ticker, which is made by the clock of
+// the cache before starting the janitor.
+func (j *janitor) run(c *Cache, ticker Ticker) {
+	defer j.wg.Done()
+	interval := j.interval
+	defer func() {
+		ticker.Stop()
+	}()
+	due := false
+	for {
+		select {
+		case <-ticker.C():
+			if atomic.LoadInt32(&j.paused) == 1 {
+				continue
+			}
+			// When paced, a cleanup waits for the next GC cycle, but no
+			// longer than one more interval.
+			if j.backoff > 0 {
+				j.backoff--
+				continue
+			}
+			if atomic.LoadInt32(&j.paced) == 0 || due {
+				j.cleanup(c)
+				due = false
+			} else {
+				due = true
+			}
+		case <-j.gc:
+			if due && atomic.LoadInt32(&j.paused) == 0 {
+				j.cleanup(c)
+				due = false
+			}
+		case <-j.trigger:
+			j.cleanup(c)
+			due = false
+		case interval = <-j.reset:
+			ticker.Reset(interval)
+		case change := <-j.clocks:
+			ticker.Stop()
+			ticker = change.clock.NewTicker(interval)
+			close(change.done)
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+// janitorMaxBackoff is the max number of ticks skipped after failed
+// cleanups.
+const janitorMaxBackoff = 64
+
+// cleanup run a cleanup, recovering a panic so it does not kill the
+// process. It is reported to the error handler, and the next cleanups are
+// skipped for a number of ticks doubling with every failure in a row.
+func (j *janitor) cleanup(c *Cache) {
+	err := c.recoverCleanup()
+	if err == nil {
+		j.failures, j.backoff = 0, 0
+		return
+	}
+	// The failures are capped so the shift does not overflow.
+	if j.failures++; j.failures > 32 {
+		j.failures = 32
+	}
+	j.backoff = 1<<uint(j.failures-1) - 1
+	if j.backoff > janitorMaxBackoff {
+		j.backoff = janitorMaxBackoff
+	}
+	c.reportError(err)
+}
+
+// Stop the janitor and wait for its last cleanup to finish.
+func (j *janitor) Stop() {
+	j.once.Do(func() {
+		close(j.stop)
+	})
+	j.wg.Wait()
+}
+
+// gcSentinel is garbage collected at every GC cycle: its finalizer signals
+// the janitor and sets itself again. The pointer keeps it out of the tiny
+// allocator, whose objects may never be finalized.
+type gcSentinel struct {
+	j   *janitor
+	gen int32
+}
+
+func (j *janitor) setPacing(on bool) {
+	for {
+		old := atomic.LoadInt32(&j.paced)
+		if !on {
+			if atomic.CompareAndSwapInt32(&j.paced, old, 0) {
+				return
+			}
+			continue
+		}
+		if old != 0 {
+			return
+		}
+		// Every activation gets a new generation, so the sentinel of a
+		// previous one stops when it finds it changed.
+		gen := int32(time.Now().UnixNano()&0x7fffffff) | 1
+		if atomic.CompareAndSwapInt32(&j.paced, 0, gen) {
+			runtime.SetFinalizer(&gcSentinel{j: j, gen: gen}, onGC)
+			return
+		}
+	}
+}
+
+func onGC(s *gcSentinel) {
+	if atomic.LoadInt32(&s.j.paced) != s.gen {
+		return
+	}
+	select {
+	case <-s.j.stop:
+		return
+	default:
+	}
+	select {
+	case s.j.gc <- struct{}{}:
+	default:
+	}
+	runtime.SetFinalizer(s, onGC)
+}
+
+// SetGCPacing make the janitor run its cleanups right after a GC cycle,
+// instead of on its own schedule, so the scan of the items and the GC do
+// not stack up. A cleanup waits at most one more cleanup interval for a GC
+// cycle. It returns an error if the cache has no janitor.
+func (c *Cache) SetGCPacing(on bool) error {
+	j := c.getJanitor()
+	if j == nil {
+		return errNoJanitor
+	}
+	j.setPacing(on)
+	return nil
+}
+
+var errNoJanitor = errors.New("The cache has no cleanup interval")
+
+func (c *Cache) getJanitor() *janitor {
+	c.RLock()
+	defer c.RUnlock()
+	return c.janitor
+}
+
+// SetCleanupInterval change the interval of the cleanups of the expired
+// items, or start them if the cache was created without cleanup interval.
+func (c *Cache) SetCleanupInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("The cleanup interval must greater than 0, see PauseCleanup")
+	}
+	c.Lock()
+	j := c.janitor
+	if j == nil {
+		c.janitor = newJanitor(interval)
+		go c.janitor.run(c, c.timeSource().NewTicker(interval))
+		c.Unlock()
+		return nil
+	}
+	c.Unlock()
+	select {
+	case j.reset <- interval:
+	case <-j.stop:
+	}
+	return nil
+}
+
+// PauseCleanup stop the periodic cleanups until ResumeCleanup, for example
+// during a latency sensitive period. It returns an error if the cache has no
+// cleanup interval.
+func (c *Cache) PauseCleanup() error {
+	return c.setPaused(1)
+}
+
+// ResumeCleanup restart the periodic cleanups stopped by PauseCleanup.
+func (c *Cache) ResumeCleanup() error {
+	return c.setPaused(0)
+}
+
+func (c *Cache) setPaused(paused int32) error {
+	j := c.getJanitor()
+	if j == nil {
+		return errNoJanitor
+	}
+	atomic.StoreInt32(&j.paused, paused)
+	return nil
+}
+
+// TriggerCleanup make the janitor run a cleanup now, even if it is paused,
+// without waiting for it. It returns an error if the cache has no cleanup
+// interval; call DeleteExpired then.
+func (c *Cache) TriggerCleanup() error {
+	j := c.getJanitor()
+	if j == nil {
+		return errNoJanitor
+	}
+	select {
+	case j.trigger <- struct{}{}:
+	default:
+		// A cleanup is already requested.
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestGCPacing(t *testing.T) {
+	if err := New(0, 0).SetGCPacing(true); err == nil {
+		t.Error("Impossiable!")
+	}
+	c := New(0, 50*time.Millisecond)
+	defer c.Close()
+	if err := c.SetGCPacing(true); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("a", 1, time.Millisecond)
+	time.Sleep(70 * time.Millisecond)
+	if c.ItemCount() != 1 {
+		t.Error("The cleanup must wait for a GC cycle")
+	}
+	runtime.GC()
+	time.Sleep(10 * time.Millisecond)
+	if c.ItemCount() != 0 {
+		t.Error("The cleanup must run after the GC cycle")
+	}
+
+	c.Set("b", 1, time.Millisecond)
+	time.Sleep(150 * time.Millisecond)
+	if c.ItemCount() != 0 {
+		t.Error("The cleanup must not wait more than one interval")
+	}
+}
+
+func TestCleanupControl(t *testing.T) {
+	c := New(0, 0)
+	defer c.Close()
+	if c.PauseCleanup() == nil || c.TriggerCleanup() == nil {
+		t.Error("Impossiable!")
+	}
+	c.Set("a", 1, time.Millisecond)
+	if err := c.SetCleanupInterval(10 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if c.ItemCount() != 0 {
+		t.Error("The cleanups must start")
+	}
+	c.PauseCleanup()
+	c.Set("b", 1, time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if c.ItemCount() != 1 {
+		t.Error("The cleanups must be paused")
+	}
+	c.TriggerCleanup()
+	time.Sleep(10 * time.Millisecond)
+	if c.ItemCount() != 0 {
+		t.Error("The triggered cleanup must run while paused")
+	}
+	c.ResumeCleanup()
+	c.SetCleanupInterval(time.Hour)
+	c.Set("c", 1, time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if c.ItemCount() != 1 {
+		t.Error("Now, the interval is an hour")
+	}
+	c.SetCleanupInterval(5 * time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if c.ItemCount() != 0 {
+		t.Error("The cleanups must be resumed")
+	}
+}
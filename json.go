@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+type jsonItem struct {
+	Key        interface{} `json:"key"`
+	Value      interface{} `json:"value"`
+	Expiration *time.Time  `json:"expiration,omitempty"`
+}
+
+// ExportJSON write the items of the cache to w as a JSON array of objects
+// with a "key", a "value" and, for items which expire, an absolute
+// "expiration" timestamp in RFC 3339 format. Keys and values must be
+// encodable by encoding/json.
+func (c *Cache) ExportJSON(w io.Writer) error {
+	c.RLock()
+	items := make([]jsonItem, 0, len(c.items))
+	for k, v := range c.items {
+		if c.access(k, OpGet) != nil {
+			continue
+		}
+		items = append(items, jsonItem{Key: k, Value: v.Object, Expiration: v.Expiration})
+	}
+	c.RUnlock()
+	return json.NewEncoder(w).Encode(items)
+}
+
+// ImportJSON add the items written by ExportJSON to the cache, like Load.
+// Keys and values are decoded as the generic encoding/json types, so
+// numbers become float64 and objects map[string]interface{}. As such maps
+// can not be keys, an item whose key is an object or an array makes it
+// return an error without importing anything. If an item does not fit,
+// see SetFullPolicy and SetMaxValueSize, the others are imported and the
+// first error is returned.
+func (c *Cache) ImportJSON(r io.Reader) (err error) {
+	var items []jsonItem
+	if err = json.NewDecoder(r).Decode(&items); err != nil {
+		return err
+	}
+	for _, it := range items {
+		if err = decodedKeyError(it.Key); err != nil {
+			return err
+		}
+	}
+	c.Lock()
+	defer c.unlockAndNotify()
+	now := c.now()
+	for _, it := range items {
+		item := &Item{Object: it.Value, Expiration: it.Expiration}
+		if item.expiredAt(now) || !c.keyAllowed(it.Key) || c.access(it.Key, OpSet) != nil {
+			continue
+		}
+		if old, ok := c.items[it.Key]; ok && !old.expiredAt(now) {
+			continue
+		}
+		if ierr := c.restore(it.Key, item); err == nil {
+			err = ierr
+		}
+	}
+	return err
+}
+
+// decodedKeyError return an error if a decoded key can not be a key of the
+// cache, like an object decoded as a map.
+func decodedKeyError(key interface{}) error {
+	if checkKey(key) == ErrKeyNotComparable {
+		return fmt.Errorf("The key %v can not be imported: %v", key, ErrKeyNotComparable)
+	}
+	return nil
+}
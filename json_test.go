@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportImportJSON(t *testing.T) {
+	c := New(0, 0)
+	c.Set("a", "x", -1)
+	c.Set("b", 2, time.Hour)
+	var buf bytes.Buffer
+	if err := c.ExportJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"key":"b","value":2,"expiration":"`) {
+		t.Error("You get a wrong JSON", buf.String())
+	}
+
+	c2 := New(0, 0)
+	if err := c2.ImportJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := c2.Get("a"); val != "x" {
+		t.Error("You get a wrong imported value")
+	}
+	if val, _ := c2.Get("b"); val != float64(2) {
+		t.Error("Numbers are imported as float64", val)
+	}
+	c2.RLock()
+	exp := c2.items["b"].Expiration
+	c2.RUnlock()
+	if exp == nil || exp.Before(time.Now().Add(59*time.Minute)) {
+		t.Error("The expiration must be kept")
+	}
+
+	err := c2.ImportJSON(strings.NewReader(`[{"key":"old","value":1,"expiration":"2000-01-01T00:00:00Z"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found := c2.Get("old"); found || c2.ItemCount() != 2 {
+		t.Error("The expired item must not be imported")
+	}
+}
+
+func TestImportJSONStructKey(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	c := New(0, 0)
+	c.Set(point{1, 2}, "a", 0)
+	c.Set("b", "b", 0)
+	var buf bytes.Buffer
+	if err := c.ExportJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	c2 := New(0, 0)
+	if err := c2.ImportJSON(bytes.NewReader(data)); err == nil {
+		t.Error("The struct key decoded as a map must be an error")
+	}
+	if c2.ItemCount() != 0 {
+		t.Error("Nothing must be imported")
+	}
+	if err := c2.LoadCodec(bytes.NewReader(data), JSONCodec{}); err == nil {
+		t.Error("LoadCodec must return the error too")
+	}
+	if s := c2.Import([]Entry{{Key: map[string]interface{}{}, Value: 1}}, Overwrite); s.Skipped != 1 {
+		t.Error("Import must skip the key which is not comparable", s)
+	}
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"sync"
+)
+
+// keyLockStripes is the number of the locks of LockKey shared by all the
+// keys.
+const keyLockStripes = 256
+
+// LockKey lock the key for the caller, and return the function to unlock
+// it, so a read-modify-write of the key, like a Get then a Set, is not
+// interleaved with the one of another goroutine:
+//
+//	unlock := c.LockKey(key)
+//	defer unlock()
+//
+// Only the callers of LockKey and TryLockKey are serialized, the other
+// methods of the cache do not wait for the lock. The keys share a fixed
+// number of locks, so a goroutine must not lock two keys at once: they may
+// share a lock, which would deadlock.
+func (c *Cache) LockKey(key interface{}) (unlock func()) {
+	mu := c.keyLock(key)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// TryLockKey works like LockKey, but returns false instead of waiting if
+// the key is locked, or shares its lock with a locked key.
+func (c *Cache) TryLockKey(key interface{}) (unlock func(), ok bool) {
+	mu := c.keyLock(key)
+	if !mu.TryLock() {
+		return nil, false
+	}
+	return mu.Unlock, true
+}
+
+func (c *Cache) keyLock(key interface{}) *sync.Mutex {
+	c.RLock()
+	locks := c.keyLocks
+	c.RUnlock()
+	if locks == nil {
+		c.Lock()
+		if c.keyLocks == nil {
+			c.keyLocks = new([keyLockStripes]sync.Mutex)
+		}
+		locks = c.keyLocks
+		c.Unlock()
+	}
+	return &locks[hashKey(key)%keyLockStripes]
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLockKey(t *testing.T) {
+	c := New(0, 0)
+	c.Set("a", 0, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := c.LockKey("a")
+			defer unlock()
+			val, _ := c.Get("a")
+			c.Set("a", val.(int)+1, 0)
+		}()
+	}
+	wg.Wait()
+	if val, _ := c.Get("a"); val != 50 {
+		t.Error("The updates of the key must be serialized", val)
+	}
+	unlock := c.LockKey("a")
+	if _, ok := c.TryLockKey("a"); ok {
+		t.Error("Impossiable!")
+	}
+	unlock()
+	unlock, ok := c.TryLockKey("a")
+	if !ok {
+		t.Error("Now, the key is unlocked")
+	}
+	unlock()
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+)
+
+// LFUCache is a goroutine-safe cache which evicts the least frequently used
+// entry when full. Entries with the same frequency are evicted in LRU order.
+// All operations are O(1).
+type LFUCache struct {
+	sync.Mutex
+	maxEntries int
+	items      map[interface{}]*list.Element
+	// freqList holds *lfuBucket in increasing frequency order.
+	freqList *list.List
+}
+
+type lfuBucket struct {
+	freq    int
+	entries *list.List
+}
+
+type lfuEntry struct {
+	key    interface{}
+	value  interface{}
+	bucket *list.Element
+}
+
+// NewLFU create a LFUCache with max size. The size is 0 means no limit.
+func NewLFU(size int) (*LFUCache, error) {
+	if size < 0 {
+		return nil, errors.New("The size of LFU Cache must no less than 0")
+	}
+	lfu := &LFUCache{
+		maxEntries: size,
+		items:      make(map[interface{}]*list.Element, size),
+		freqList:   list.New(),
+	}
+	return lfu, nil
+}
+
+// Add a new key-value pair to the LFUCache. Updating an existing key counts
+// as an access.
+func (c *LFUCache) Add(key interface{}, value interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if ele, hit := c.items[key]; hit {
+		ele.Value.(*lfuEntry).value = value
+		c.increment(ele)
+		return
+	}
+	if c.maxEntries > 0 && len(c.items) >= c.maxEntries {
+		c.removeLeastFrequent()
+	}
+	front := c.freqList.Front()
+	if front == nil || front.Value.(*lfuBucket).freq != 1 {
+		front = c.freqList.PushFront(&lfuBucket{freq: 1, entries: list.New()})
+	}
+	ent := &lfuEntry{key: key, value: value, bucket: front}
+	c.items[key] = front.Value.(*lfuBucket).entries.PushFront(ent)
+}
+
+// Get a value from the LFUCache. And a bool indicating
+// whether found or not.
+func (c *LFUCache) Get(key interface{}) (interface{}, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if ele, hit := c.items[key]; hit {
+		c.increment(ele)
+		return c.items[key].Value.(*lfuEntry).value, true
+	}
+	return nil, false
+}
+
+// Remove a key-value pair in LFUCache. If the key is not existed,
+// nothing will happen.
+func (c *LFUCache) Remove(key interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if ele, hit := c.items[key]; hit {
+		c.removeElement(ele)
+	}
+}
+
+// Return the number of key-value pair in LFUCache.
+func (c *LFUCache) Len() int {
+	c.Lock()
+	length := len(c.items)
+	c.Unlock()
+	return length
+}
+
+// Delete all entry in the LFUCache. But the max size will hold.
+func (c *LFUCache) Clear() {
+	c.Lock()
+	c.freqList = list.New()
+	c.items = make(map[interface{}]*list.Element, c.maxEntries)
+	c.Unlock()
+}
+
+// Resize the max limit. If the cache holds more entries than the new limit,
+// the least frequently used ones are evicted.
+func (c *LFUCache) SetMaxEntries(max int) error {
+	if max < 0 {
+		return errors.New("The max limit of entryies must no less than 0")
+	}
+	c.Lock()
+	c.maxEntries = max
+	for max > 0 && len(c.items) > max {
+		c.removeLeastFrequent()
+	}
+	c.Unlock()
+	return nil
+}
+
+// increment move the entry of ele to the bucket of the next frequency.
+func (c *LFUCache) increment(ele *list.Element) {
+	ent := ele.Value.(*lfuEntry)
+	cur := ent.bucket
+	bucket := cur.Value.(*lfuBucket)
+	next := cur.Next()
+	if next == nil || next.Value.(*lfuBucket).freq != bucket.freq+1 {
+		next = c.freqList.InsertAfter(&lfuBucket{freq: bucket.freq + 1, entries: list.New()}, cur)
+	}
+	bucket.entries.Remove(ele)
+	if bucket.entries.Len() == 0 {
+		c.freqList.Remove(cur)
+	}
+	ent.bucket = next
+	c.items[ent.key] = next.Value.(*lfuBucket).entries.PushFront(ent)
+}
+
+func (c *LFUCache) removeElement(ele *list.Element) {
+	ent := ele.Value.(*lfuEntry)
+	bucket := ent.bucket.Value.(*lfuBucket)
+	bucket.entries.Remove(ele)
+	if bucket.entries.Len() == 0 {
+		c.freqList.Remove(ent.bucket)
+	}
+	delete(c.items, ent.key)
+}
+
+func (c *LFUCache) removeLeastFrequent() {
+	front := c.freqList.Front()
+	if front == nil {
+		return
+	}
+	if ele := front.Value.(*lfuBucket).entries.Back(); ele != nil {
+		c.removeElement(ele)
+	}
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestLFUCache(t *testing.T) {
+	_, err := NewLFU(-1)
+	if err == nil {
+		t.Error("Impossiable!")
+	}
+	var lfu BoundedCache
+	lfu, err = NewLFU(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lfu.Add("1", 111)
+	lfu.Add("2", 222)
+	lfu.Get("1")
+	lfu.Get("1")
+	lfu.Get("2")
+	lfu.Add("3", 333)
+	if lfu.Len() != 2 {
+		t.Error("Now, there is only two values in cache")
+	}
+	if _, hit := lfu.Get("2"); hit {
+		t.Error("The least frequently used value must be removed")
+	}
+	if val, hit := lfu.Get("1"); !hit || val != 111 {
+		t.Error("The most frequently used value must be kept")
+	}
+	lfu.Remove("1")
+	if _, hit := lfu.Get("1"); hit {
+		t.Error("The value must be removed")
+	}
+	lfu.Clear()
+	if lfu.Len() != 0 {
+		t.Error("Now, the lfu cache is cleared")
+	}
+	lfu.SetMaxEntries(0)
+	for i := 0; i < 10; i++ {
+		lfu.Add(i, i)
+	}
+	if lfu.Len() != 10 {
+		t.Error("The lfu cache has no limit")
+	}
+	lfu.SetMaxEntries(5)
+	if lfu.Len() != 5 {
+		t.Error("The lfu cache must be shrunk to the new limit")
+	}
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"time"
+)
+
+// ItemState is the stage of the lifecycle of an item.
+type ItemState int
+
+const (
+	// StateFresh is an item which is not expired.
+	StateFresh ItemState = iota
+	// StateStale is an expired item still kept for the stale window, see
+	// SetStaleWindow. Get does not return it.
+	StateStale
+	// StateRefreshing is an item whose new value is being loaded, see
+	// SetRefreshing.
+	StateRefreshing
+	// StateExpired is an item past its stale window, which is removed by the
+	// next DeleteExpired.
+	StateExpired
+)
+
+func (s ItemState) String() string {
+	switch s {
+	case StateFresh:
+		return "fresh"
+	case StateStale:
+		return "stale"
+	case StateRefreshing:
+		return "refreshing"
+	case StateExpired:
+		return "expired"
+	}
+	return "unknown"
+}
+
+type stateChange struct {
+	key   interface{}
+	state ItemState
+}
+
+// SetStaleWindow keep the expired items for window before DeleteExpired
+// removes them. Meanwhile they are stale: Get ignores them, but
+// GetItemInfo still returns them, so callers can serve or refresh them by
+// their own policy.
+func (c *Cache) SetStaleWindow(window time.Duration) {
+	if window < 0 {
+		window = 0
+	}
+	c.Lock()
+	c.staleWindow = window
+	c.wakeExpiryTimer()
+	c.Unlock()
+}
+
+// SetRefreshing mark the item of the key as being refreshed or not, so the
+// other callers can see it is taken care of. The next Set of the key
+// clears the mark. It returns false if the key is not found or past its
+// stale window.
+func (c *Cache) SetRefreshing(key interface{}, refreshing bool) bool {
+	if !c.keyAllowed(key) {
+		return false
+	}
+	c.Lock()
+	item, ok := c.items[key]
+	if !ok || c.removable(item) || c.access(key, OpSet) != nil {
+		c.unlockAndNotify()
+		return false
+	}
+	if item.refreshing != refreshing {
+		item = c.writable(key, item)
+		item.refreshing = refreshing
+		c.stateChanged(key, c.state(item))
+	}
+	c.unlockAndNotify()
+	return true
+}
+
+// OnStateChange set a function called when an item changes state: when it
+// is marked refreshing or not by SetRefreshing, found stale or removed as
+// expired by DeleteExpired, and when a stale or refreshing item is set
+// again, which makes it fresh. Like OnEvicted, it is called without the
+// lock held. Set to nil to disable.
+func (c *Cache) OnStateChange(f func(key interface{}, state ItemState)) {
+	c.Lock()
+	c.onStateChange = f
+	c.Unlock()
+}
+
+// state return the state of an item. The caller must hold the lock.
+func (c *Cache) state(item *Item) ItemState {
+	if c.removable(item) {
+		return StateExpired
+	}
+	if item.refreshing {
+		return StateRefreshing
+	}
+	if c.expired(item) {
+		return StateStale
+	}
+	return StateFresh
+}
+
+// removable return true if the item is past its stale window. The caller
+// must hold the lock.
+func (c *Cache) removable(item *Item) bool {
+	if c.degraded || item.Expiration == nil {
+		return false
+	}
+	return item.Expiration.Add(c.staleWindow).Before(c.now())
+}
+
+// stateChanged queue a state change for the callback. The caller must hold
+// the lock and release it by unlockAndNotify.
+func (c *Cache) stateChanged(key interface{}, state ItemState) {
+	if c.onStateChange != nil {
+		c.stateChanges = append(c.stateChanges, stateChange{key, state})
+	}
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLifecycle(t *testing.T) {
+	c := New(0, 0)
+	var states []ItemState
+	c.OnStateChange(func(key interface{}, state ItemState) {
+		states = append(states, state)
+	})
+	c.SetStaleWindow(50 * time.Millisecond)
+	c.Set("a", 1, 10*time.Millisecond)
+	if info, _ := c.GetItemInfo("a"); info.State != StateFresh {
+		t.Errorf("You get a wrong state %v", info.State)
+	}
+	time.Sleep(20 * time.Millisecond)
+	c.DeleteExpired()
+	c.DeleteExpired()
+	if _, found := c.Get("a"); found {
+		t.Error("Get must not return a stale item")
+	}
+	if info, found := c.GetItemInfo("a"); !found || info.State != StateStale {
+		t.Errorf("You get a wrong state %v", info.State)
+	}
+	if !c.SetRefreshing("a", true) || c.SetRefreshing("missing", true) {
+		t.Error("Impossiable!")
+	}
+	if info, _ := c.GetItemInfo("a"); info.State != StateRefreshing {
+		t.Errorf("You get a wrong state %v", info.State)
+	}
+	c.Set("a", 2, 10*time.Millisecond)
+	time.Sleep(70 * time.Millisecond)
+	c.DeleteExpired()
+	if c.ItemCount() != 0 {
+		t.Error("Now, the item is past its stale window")
+	}
+	want := []ItemState{StateStale, StateRefreshing, StateFresh, StateExpired}
+	if len(states) != len(want) {
+		t.Fatalf("You get wrong state changes %v", states)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Errorf("You get wrong state changes %v", states)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// loadCall is a call of a loader in flight, shared by the loads of the
+// same key.
+type loadCall struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+// GetOrLoad return the value of the key if it is found. Otherwise it calls
+// loader, stores the value it returns for dur, like Set, and returns it. An
+// error of the loader is returned as is, and nothing is stored. Concurrent
+// GetOrLoad of a key missing from the cache share one call of the loader,
+// and all of them get its result. See SetNegativeTTL to cache the errors,
+// and SetKeyFilter to not call the loader for the keys which do not exist.
+func (c *Cache) GetOrLoad(key interface{}, dur time.Duration, loader func() (interface{}, error)) (interface{}, error) {
+	return c.GetOrLoadContext(context.Background(), key, dur, func(context.Context) (interface{}, error) {
+		return loader()
+	})
+}
+
+// GetOrLoadContext works like GetOrLoad, passing ctx to the access check
+// and to loader. If ctx is done while waiting for the call of the loader
+// of another GetOrLoadContext, it returns the error of ctx, and the loader
+// goes on for the others. The errors of ctx returned by the loader are not
+// cached as Negative values.
+func (c *Cache) GetOrLoadContext(ctx context.Context, key interface{}, dur time.Duration, loader func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if val, err := c.get(ctx, key); err == nil {
+		if n, ok := val.(Negative); ok {
+			return nil, n.error()
+		}
+		return val, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.RLock()
+	filter := c.keyFilter
+	c.RUnlock()
+	if filter != nil && !filter.MayContain(key) {
+		return nil, ErrNotFound
+	}
+	return c.load(ctx, key, func() (interface{}, time.Duration, error) {
+		val, err := loader(ctx)
+		return val, dur, err
+	})
+}
+
+// load call loader once for the concurrent loads of the key, and store the
+// value it returns for the duration it returns, or its error as a Negative
+// value for the negative TTL. The loads waiting for the call of another
+// stop when ctx is done.
+func (c *Cache) load(ctx context.Context, key interface{}, loader func() (interface{}, time.Duration, error)) (interface{}, error) {
+	if !c.keyAllowed(key) {
+		val, _, err := loader()
+		return val, err
+	}
+	c.Lock()
+	if err := c.checkAccess(ctx, key, OpGet); err != nil {
+		c.Unlock()
+		return nil, err
+	}
+	if call, ok := c.loads[key]; ok {
+		c.Unlock()
+		select {
+		case <-call.done:
+			c.RLock()
+			defer c.RUnlock()
+			return c.copyValue(call.val), call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &loadCall{done: make(chan struct{})}
+	if c.loads == nil {
+		c.loads = map[interface{}]*loadCall{}
+	}
+	c.loads[key] = call
+	c.Unlock()
+
+	// If the loader panics, the waiters get an error and the panic goes on
+	// in the caller, so the key is not blocked forever.
+	defer func() {
+		if x := recover(); x != nil {
+			c.Lock()
+			delete(c.loads, key)
+			c.Unlock()
+			call.val, call.err = nil, fmt.Errorf("The loader panicked: %v", x)
+			close(call.done)
+			panic(x)
+		}
+	}()
+	var dur time.Duration
+	call.val, dur, call.err = loader()
+
+	c.Lock()
+	delete(c.loads, key)
+	if c.checkAccess(ctx, key, OpSet) == nil && !c.tombstoned(key) {
+		if call.err == nil {
+			c.fill(key, call.val, dur)
+		} else if c.negativeTTL != 0 && call.err != context.Canceled && call.err != context.DeadlineExceeded {
+			c.fill(key, Negative{call.err}, c.negativeTTL)
+		}
+	}
+	c.unlockAndNotify()
+	close(call.done)
+	return call.val, call.err
+}
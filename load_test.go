@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	c := New(0, 0)
+	calls := 0
+	loader := func() (interface{}, error) {
+		calls++
+		return "loaded", nil
+	}
+	for i := 0; i < 2; i++ {
+		if val, err := c.GetOrLoad("a", 0, loader); err != nil || val != "loaded" {
+			t.Error("You get a wrong value", val, err)
+		}
+	}
+	if calls != 1 {
+		t.Error("The loader must be called once")
+	}
+	fail := errors.New("origin down")
+	if _, err := c.GetOrLoad("b", 0, func() (interface{}, error) { return nil, fail }); err != fail {
+		t.Error("The error of the loader must be returned")
+	}
+	if _, found := c.Get("b"); found {
+		t.Error("Impossiable!")
+	}
+}
+
+func TestGetOrLoadSingleflight(t *testing.T) {
+	c := New(0, 0)
+	var calls int32
+	release := make(chan struct{})
+	loader := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return nil, errors.New("origin down")
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.GetOrLoad("a", 0, loader)
+			errs <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("The loader is called %d times", calls)
+	}
+	for err := range errs {
+		if err == nil {
+			t.Error("The error must be shared")
+		}
+	}
+}
+
+func TestGetOrLoadContext(t *testing.T) {
+	c := New(0, 0)
+	c.SetNegativeTTL(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetOrLoadContext(ctx, "a", 0, func(context.Context) (interface{}, error) {
+		return 1, nil
+	}); err != context.Canceled {
+		t.Error("You get a wrong error", err)
+	}
+	if err := c.SetContext(ctx, "a", 1, 0); err != context.Canceled || c.ItemCount() != 0 {
+		t.Error("A done context must stop the write", err)
+	}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	go c.GetOrLoadContext(context.Background(), "b", 0, func(context.Context) (interface{}, error) {
+		close(started)
+		<-release
+		return 2, nil
+	})
+	<-started
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetOrLoadContext(ctx, "b", 0, nil); err != context.DeadlineExceeded {
+		t.Error("The wait for the loader must stop at the deadline", err)
+	}
+	close(release)
+
+	if _, err := c.GetOrLoadContext(context.Background(), "c", 0, func(ctx context.Context) (interface{}, error) {
+		return nil, context.Canceled
+	}); err != context.Canceled {
+		t.Error("You get a wrong error", err)
+	}
+	if _, found := c.Get("c"); found {
+		t.Error("The errors of the context must not be cached")
+	}
+}
+
+func TestGetOrLoadPanic(t *testing.T) {
+	c := New(0, 0)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	waited := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("The panic must go on in the caller")
+			}
+		}()
+		c.GetOrLoad("a", 0, func() (interface{}, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+	go func() {
+		_, err := c.GetOrLoad("a", 0, func() (interface{}, error) { return 1, nil })
+		waited <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	select {
+	case err := <-waited:
+		if err == nil {
+			t.Error("The waiter must get an error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The waiter is blocked")
+	}
+	if val, err := c.GetOrLoad("a", 0, func() (interface{}, error) { return 2, nil }); err != nil || val != 2 {
+		t.Error("Now, the key must be loaded again", val, err)
+	}
+}
@@ -0,0 +1,187 @@
+// Package lru provide a goroutine-safe LRU cache with typed keys and
+// values, so the values are not boxed in an interface{} and Get needs no
+// type assertion. See the LRUCache of go-cache for the expiration, the
+// weights and the admission policies.
+package lru
+
+import (
+	"errors"
+	"sync"
+)
+
+// LRU is a goroutine-safe LRU cache of values of type V by keys of type K.
+type LRU[K comparable, V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[K]*node[K, V]
+	// root is the sentinel of the list of the entries, the most recently
+	// used first: root.next is the newest and root.prev the oldest.
+	root node[K, V]
+}
+
+type node[K comparable, V any] struct {
+	prev, next *node[K, V]
+	key        K
+	value      V
+}
+
+// New create a LRU with max size. The size is 0 means no limit.
+func New[K comparable, V any](size int) (*LRU[K, V], error) {
+	if size < 0 {
+		return nil, errors.New("The size of LRU Cache must no less than 0")
+	}
+	c := &LRU[K, V]{
+		maxEntries: size,
+		items:      make(map[K]*node[K, V], size),
+	}
+	c.root.next, c.root.prev = &c.root, &c.root
+	return c, nil
+}
+
+// Add a new key-value pair, or replace the value of an existing key, as
+// the most recently used entry. It returns true if the oldest entry was
+// evicted to make room.
+func (c *LRU[K, V]) Add(key K, value V) (evicted bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n, hit := c.items[key]; hit {
+		n.value = value
+		c.moveToFront(n)
+		return false
+	}
+	n := &node[K, V]{key: key, value: value}
+	c.items[key] = n
+	c.pushFront(n)
+	if c.maxEntries > 0 && len(c.items) > c.maxEntries {
+		c.removeOldest()
+		return true
+	}
+	return false
+}
+
+// Get return the value of the key and make it the most recently used, and
+// a bool indicating whether it was found.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n, hit := c.items[key]; hit {
+		c.moveToFront(n)
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Peek return the value of the key like Get, without updating the recency
+// of the entry.
+func (c *LRU[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n, hit := c.items[key]; hit {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains return true if the key is in the LRU, without updating the
+// recency of the entry.
+func (c *LRU[K, V]) Contains(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, hit := c.items[key]
+	return hit
+}
+
+// Remove a key-value pair, and return true if the key was found.
+func (c *LRU[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n, hit := c.items[key]
+	if hit {
+		c.remove(n)
+	}
+	return hit
+}
+
+// RemoveOldest remove the least recently used entry and return it. The ok
+// is false if the LRU is empty.
+func (c *LRU[K, V]) RemoveOldest() (key K, value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n := c.root.prev; n != &c.root {
+		c.remove(n)
+		return n.key, n.value, true
+	}
+	return key, value, false
+}
+
+// Keys return the keys of the LRU, the most recently used first.
+func (c *LRU[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]K, 0, len(c.items))
+	for n := c.root.next; n != &c.root; n = n.next {
+		keys = append(keys, n.key)
+	}
+	return keys
+}
+
+// Len return the number of entries.
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Clear delete all the entries. But the max size will hold.
+func (c *LRU[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[K]*node[K, V], c.maxEntries)
+	c.root.next, c.root.prev = &c.root, &c.root
+}
+
+// Resize change the max size, evicting the oldest entries which do not fit
+// any more, and return their number. The size is 0 means no limit.
+func (c *LRU[K, V]) Resize(size int) (evicted int, err error) {
+	if size < 0 {
+		return 0, errors.New("The size of LRU Cache must no less than 0")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxEntries = size
+	for size > 0 && len(c.items) > size {
+		c.removeOldest()
+		evicted++
+	}
+	return evicted, nil
+}
+
+func (c *LRU[K, V]) pushFront(n *node[K, V]) {
+	n.prev, n.next = &c.root, c.root.next
+	n.prev.next, n.next.prev = n, n
+}
+
+func (c *LRU[K, V]) unlink(n *node[K, V]) {
+	n.prev.next, n.next.prev = n.next, n.prev
+	n.prev, n.next = nil, nil
+}
+
+func (c *LRU[K, V]) moveToFront(n *node[K, V]) {
+	if c.root.next != n {
+		c.unlink(n)
+		c.pushFront(n)
+	}
+}
+
+func (c *LRU[K, V]) remove(n *node[K, V]) {
+	c.unlink(n)
+	delete(c.items, n.key)
+}
+
+func (c *LRU[K, V]) removeOldest() {
+	if n := c.root.prev; n != &c.root {
+		c.remove(n)
+	}
+}
@@ -0,0 +1,86 @@
+package lru
+
+import (
+	"testing"
+)
+
+func TestLRU(t *testing.T) {
+	if _, err := New[string, int](-1); err == nil {
+		t.Error("Impossiable!")
+	}
+	c, err := New[string, int](2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add("a", 1)
+	c.Add("b", 2)
+	if val, found := c.Get("a"); !found || val != 1 {
+		t.Error("You get a wrong value", val)
+	}
+	if !c.Add("c", 3) || c.Contains("b") {
+		t.Error("The least recently used entry must be evicted")
+	}
+	if val, found := c.Get("b"); found || val != 0 {
+		t.Error("You should get the zero value", val)
+	}
+	c.Peek("a")
+	if keys := c.Keys(); len(keys) != 2 || keys[0] != "c" || keys[1] != "a" {
+		t.Error("Peek must not change the order", keys)
+	}
+	if c.Add("a", 10) {
+		t.Error("Impossiable!")
+	}
+	if val, _ := c.Peek("a"); val != 10 {
+		t.Error("The value must be replaced", val)
+	}
+	if !c.Remove("a") || c.Remove("a") || c.Len() != 1 {
+		t.Error("You get a wrong len", c.Len())
+	}
+	if key, val, ok := c.RemoveOldest(); !ok || key != "c" || val != 3 {
+		t.Error("You get a wrong oldest entry", key, val)
+	}
+	if _, _, ok := c.RemoveOldest(); ok {
+		t.Error("Now, the LRU is empty")
+	}
+}
+
+func TestLRUResize(t *testing.T) {
+	c, _ := New[int, string](0)
+	for i := 0; i < 10; i++ {
+		c.Add(i, "v")
+	}
+	if evicted, _ := c.Resize(4); evicted != 6 || c.Len() != 4 || !c.Contains(9) || c.Contains(5) {
+		t.Error("The oldest entries must be evicted", evicted)
+	}
+	c.Add(10, "v")
+	if c.Len() != 4 {
+		t.Error("The new size must hold", c.Len())
+	}
+	if _, err := c.Resize(-1); err == nil {
+		t.Error("Impossiable!")
+	}
+	c.Clear()
+	if c.Len() != 0 || len(c.Keys()) != 0 {
+		t.Error("Now, the LRU is cleared")
+	}
+}
+
+func BenchmarkLRUAdd(b *testing.B) {
+	c, _ := New[int, int](1024)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.Add(i, i)
+	}
+}
+
+func BenchmarkLRUGet(b *testing.B) {
+	c, _ := New[int, int](1024)
+	for i := 0; i < 1024; i++ {
+		c.Add(i, i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(i & 1023)
+	}
+}
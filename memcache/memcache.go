@@ -0,0 +1,317 @@
+// Package memcache is a cache.Interface backed by a memcached server, so it
+// can be the L2 of a cache.TieredCache, or the store of a read-through or
+// write-through cache.Cache with Store. It speaks the text protocol over a
+// single connection, without a client dependency, and supports the
+// compare-and-swap of memcached with GetCAS and CompareAndSwap.
+package memcache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+// ErrCASConflict is returned by CompareAndSwap when the value was modified
+// since GetCAS.
+var ErrCASConflict = errors.New("The value was modified since it was read")
+
+// ErrBadKey is returned for the keys which are longer than 250 bytes or
+// contain spaces or control characters once formatted.
+var ErrBadKey = errors.New("The key is invalid for memcached")
+
+// maxRelativeExpiration is the longest expiration memcached takes as a
+// number of seconds, longer ones are taken as a unix time.
+const maxRelativeExpiration = 30 * 24 * time.Hour
+
+// Cache is a goroutine-safe cache of values in memcached. Keys are formatted
+// with fmt.Sprint and values are encoded by a cache.Codec, so with the
+// cache.GobCodec their concrete types must be registered with gob.Register.
+// The commands are sent one at a time over the connection.
+type Cache struct {
+	mu                sync.Mutex
+	conn              io.ReadWriter
+	r                 *bufio.Reader
+	w                 *bufio.Writer
+	codec             cache.Codec
+	prefix            string
+	defaultExpiration time.Duration
+	lastErr           error
+}
+
+// New create a Cache over a connection to a memcached server, like a
+// net.Conn. Keys are prefixed with prefix in memcached. Values set with a
+// dur of 0 expire after defaultExpiration, which is less than 1 means never.
+func New(conn io.ReadWriter, codec cache.Codec, prefix string, defaultExpiration time.Duration) *Cache {
+	return &Cache{
+		conn:              conn,
+		r:                 bufio.NewReader(conn),
+		w:                 bufio.NewWriter(conn),
+		codec:             codec,
+		prefix:            prefix,
+		defaultExpiration: defaultExpiration,
+	}
+}
+
+// Dial connect to the memcached server at addr and create a Cache over the
+// connection, like New.
+func Dial(addr string, codec cache.Codec, prefix string, defaultExpiration time.Duration) (*Cache, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return New(conn, codec, prefix, defaultExpiration), nil
+}
+
+// Close the connection if it is an io.Closer.
+func (c *Cache) Close() error {
+	if closer, ok := c.conn.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Get a value. A miss and an error both return false, see LastError.
+func (c *Cache) Get(key interface{}) (interface{}, bool) {
+	val, err := c.Fetch(key)
+	if err == cache.ErrNotFound {
+		c.setErr(nil)
+		return nil, false
+	}
+	c.setErr(err)
+	return val, err == nil
+}
+
+// Fetch a value, or cache.ErrNotFound if the key is not found.
+func (c *Cache) Fetch(key interface{}) (interface{}, error) {
+	val, _, err := c.get("get", key)
+	return val, err
+}
+
+// GetCAS return a value with its CAS token for CompareAndSwap, or
+// cache.ErrNotFound if the key is not found.
+func (c *Cache) GetCAS(key interface{}) (interface{}, uint64, error) {
+	return c.get("gets", key)
+}
+
+// Set a value. If the dur is 0, the default expiration is used, and if it
+// is less than 0 the value never expires. Memcached counts the expiration
+// in seconds, so dur is rounded up to a second. The error is kept for
+// LastError.
+func (c *Cache) Set(key interface{}, val interface{}, dur time.Duration) {
+	c.setErr(c.SetSync(key, val, dur))
+}
+
+// SetSync works like Set, but returns the error.
+func (c *Cache) SetSync(key interface{}, val interface{}, dur time.Duration) error {
+	_, err := c.store("set", key, val, dur, "")
+	return err
+}
+
+// CompareAndSwap set a value only if it was not modified since GetCAS
+// returned cas, or return ErrCASConflict. It returns cache.ErrNotFound if
+// the key was deleted or expired meanwhile.
+func (c *Cache) CompareAndSwap(key interface{}, val interface{}, dur time.Duration, cas uint64) error {
+	reply, err := c.store("cas", key, val, dur, " "+strconv.FormatUint(cas, 10))
+	switch {
+	case err != nil:
+		return err
+	case reply == "EXISTS":
+		return ErrCASConflict
+	case reply == "NOT_FOUND":
+		return cache.ErrNotFound
+	}
+	return nil
+}
+
+// Delete a key. The error is kept for LastError.
+func (c *Cache) Delete(key interface{}) {
+	c.setErr(c.DeleteSync(key))
+}
+
+// DeleteSync works like Delete, but returns the error.
+func (c *Cache) DeleteSync(key interface{}) error {
+	k, err := c.key(key)
+	if err != nil {
+		return err
+	}
+	reply, err := c.do("delete "+k+"\r\n", nil)
+	if err == nil && reply != "DELETED" && reply != "NOT_FOUND" {
+		err = replyError(reply)
+	}
+	return err
+}
+
+// LastError return the error of the last Get, Set or Delete, or nil if it
+// succeeded.
+func (c *Cache) LastError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastErr
+}
+
+// Store return the Cache as a cache.WritableStore, so a cache.Cache can
+// read and write through it with SetStore. Memcached does not tell the
+// remaining TTL of a value, so the values loaded are kept in the
+// cache.Cache for its default expiration.
+func (c *Cache) Store() cache.WritableStore {
+	return store{c}
+}
+
+type store struct {
+	c *Cache
+}
+
+func (s store) Load(key interface{}) (interface{}, time.Duration, error) {
+	val, err := s.c.Fetch(key)
+	return val, 0, err
+}
+
+func (s store) Save(key interface{}, val interface{}, ttl time.Duration) error {
+	return s.c.SetSync(key, val, ttl)
+}
+
+func (s store) Delete(key interface{}) error {
+	return s.c.DeleteSync(key)
+}
+
+func (c *Cache) key(key interface{}) (string, error) {
+	k := c.prefix + fmt.Sprint(key)
+	if len(k) == 0 || len(k) > 250 {
+		return "", ErrBadKey
+	}
+	for i := 0; i < len(k); i++ {
+		if k[i] <= ' ' || k[i] == 0x7f {
+			return "", ErrBadKey
+		}
+	}
+	return k, nil
+}
+
+func (c *Cache) setErr(err error) {
+	c.mu.Lock()
+	c.lastErr = err
+	c.mu.Unlock()
+}
+
+// exptime convert a dur of Set to the expiration time of memcached.
+func (c *Cache) exptime(dur time.Duration) int64 {
+	if dur == 0 {
+		dur = c.defaultExpiration
+	}
+	if dur <= 0 {
+		return 0
+	}
+	if dur > maxRelativeExpiration {
+		return time.Now().Add(dur).Unix()
+	}
+	return int64((dur + time.Second - 1) / time.Second)
+}
+
+// get run get or gets for a key.
+func (c *Cache) get(cmd string, key interface{}) (interface{}, uint64, error) {
+	k, err := c.key(key)
+	if err != nil {
+		return nil, 0, err
+	}
+	var data []byte
+	var cas uint64
+	_, err = c.do(cmd+" "+k+"\r\n", func(line string, r *bufio.Reader) (string, error) {
+		for strings.HasPrefix(line, "VALUE ") {
+			// VALUE <key> <flags> <bytes> [<cas>]
+			fields := strings.Fields(line)
+			if len(fields) < 4 {
+				return "", replyError(line)
+			}
+			n, err := strconv.Atoi(fields[3])
+			if err != nil {
+				return "", replyError(line)
+			}
+			if len(fields) > 4 {
+				if cas, err = strconv.ParseUint(fields[4], 10, 64); err != nil {
+					return "", replyError(line)
+				}
+			}
+			data = make([]byte, n+2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return "", err
+			}
+			data = data[:n]
+			if line, err = readLine(r); err != nil {
+				return "", err
+			}
+		}
+		if line != "END" {
+			return "", replyError(line)
+		}
+		return line, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if data == nil {
+		return nil, 0, cache.ErrNotFound
+	}
+	var val interface{}
+	if err := c.codec.Unmarshal(data, &val); err != nil {
+		return nil, 0, err
+	}
+	return val, cas, nil
+}
+
+// store run set or cas for a key, with the cas token in extra, and return
+// the reply.
+func (c *Cache) store(cmd string, key interface{}, val interface{}, dur time.Duration, extra string) (string, error) {
+	k, err := c.key(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := c.codec.Marshal(&val)
+	if err != nil {
+		return "", err
+	}
+	req := fmt.Sprintf("%s %s 0 %d %d%s\r\n%s\r\n", cmd, k, c.exptime(dur), len(data), extra, data)
+	reply, err := c.do(req, nil)
+	if err == nil && reply != "STORED" && reply != "EXISTS" && reply != "NOT_FOUND" {
+		err = replyError(reply)
+	}
+	return reply, err
+}
+
+// do send a request and read the first line of the reply, then the rest of
+// it with read if it is not nil.
+func (c *Cache) do(req string, read func(line string, r *bufio.Reader) (string, error)) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.w.WriteString(req); err != nil {
+		return "", err
+	}
+	if err := c.w.Flush(); err != nil {
+		return "", err
+	}
+	line, err := readLine(c.r)
+	if err != nil || read == nil {
+		return line, err
+	}
+	return read(line, c.r)
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+// replyError turn an unexpected reply into an error.
+func replyError(reply string) error {
+	return fmt.Errorf("Unexpected reply %q from the server", reply)
+}
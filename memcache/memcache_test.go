@@ -0,0 +1,167 @@
+package memcache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+type testItem struct {
+	data    string
+	exptime int64
+	cas     uint64
+}
+
+// serve answer the commands of the tests on conn from an in-memory map,
+// recording the expiration times instead of applying them.
+func serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	items := map[string]*testItem{}
+	var cas uint64
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return
+		}
+		f := strings.Fields(line)
+		switch f[0] {
+		case "get", "gets":
+			if it, ok := items[f[1]]; ok {
+				fmt.Fprintf(conn, "VALUE %s 0 %d", f[1], len(it.data))
+				if f[0] == "gets" {
+					fmt.Fprintf(conn, " %d", it.cas)
+				}
+				fmt.Fprintf(conn, "\r\n%s\r\n", it.data)
+			}
+			fmt.Fprint(conn, "END\r\n")
+		case "set", "cas":
+			exptime, _ := strconv.ParseInt(f[3], 10, 64)
+			n, _ := strconv.Atoi(f[4])
+			data := make([]byte, n+2)
+			io.ReadFull(r, data)
+			it, ok := items[f[1]]
+			if f[0] == "cas" {
+				token, _ := strconv.ParseUint(f[5], 10, 64)
+				if !ok {
+					fmt.Fprint(conn, "NOT_FOUND\r\n")
+					continue
+				}
+				if it.cas != token {
+					fmt.Fprint(conn, "EXISTS\r\n")
+					continue
+				}
+			}
+			cas++
+			items[f[1]] = &testItem{string(data[:n]), exptime, cas}
+			fmt.Fprint(conn, "STORED\r\n")
+		case "delete":
+			if _, ok := items[f[1]]; ok {
+				delete(items, f[1])
+				fmt.Fprint(conn, "DELETED\r\n")
+			} else {
+				fmt.Fprint(conn, "NOT_FOUND\r\n")
+			}
+		case "exptime":
+			if it, ok := items[f[1]]; ok {
+				fmt.Fprintf(conn, "%d\r\n", it.exptime)
+			} else {
+				fmt.Fprint(conn, "ERROR\r\n")
+			}
+		default:
+			fmt.Fprint(conn, "ERROR\r\n")
+		}
+	}
+}
+
+func newTestCache(defaultExpiration time.Duration) *Cache {
+	client, server := net.Pipe()
+	go serve(server)
+	return New(client, cache.JSONCodec{}, "test:", defaultExpiration)
+}
+
+// exptime return the expiration time the test server recorded for key.
+func exptime(c *Cache, key string) int64 {
+	line, _ := c.do("exptime test:"+key+"\r\n", nil)
+	n, _ := strconv.ParseInt(line, 10, 64)
+	return n
+}
+
+func TestCache(t *testing.T) {
+	c := newTestCache(time.Hour)
+	defer c.Close()
+	var _ cache.Interface = c
+	c.Set("a", "value", 0)
+	c.Set("b", 1.5, -1)
+	c.Set("c", true, 1500*time.Millisecond)
+	c.Set("d", "", 60*24*time.Hour)
+	if c.LastError() != nil {
+		t.Fatal(c.LastError())
+	}
+	if val, found := c.Get("a"); !found || val != "value" {
+		t.Errorf("You get a wrong value %v", val)
+	}
+	if val, found := c.Get("d"); !found || val != "" {
+		t.Errorf("You get a wrong value %v", val)
+	}
+	if exptime(c, "a") != 3600 || exptime(c, "b") != 0 || exptime(c, "c") != 2 {
+		t.Error("The TTL must be converted to seconds")
+	}
+	if exp := exptime(c, "d"); exp < time.Now().Unix() {
+		t.Errorf("A long TTL must be converted to a unix time, not %d", exp)
+	}
+	c.Delete("a")
+	if _, err := c.Fetch("a"); err != cache.ErrNotFound {
+		t.Error("Now, the key is deleted")
+	}
+	if err := c.SetSync("bad key", 1, 0); err != ErrBadKey {
+		t.Error("The keys with spaces must be rejected")
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	c := newTestCache(0)
+	defer c.Close()
+	c.Set("a", 1, 0)
+	val, cas, err := c.GetCAS("a")
+	if err != nil || val != 1.0 {
+		t.Fatalf("You get a wrong value %v, %v", val, err)
+	}
+	if err := c.CompareAndSwap("a", 2, 0, cas); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CompareAndSwap("a", 3, 0, cas); err != ErrCASConflict {
+		t.Errorf("You get a wrong error %v", err)
+	}
+	if val, _ := c.Get("a"); val != 2.0 {
+		t.Errorf("You get a wrong value %v", val)
+	}
+	c.Delete("a")
+	if err := c.CompareAndSwap("a", 3, 0, cas); err != cache.ErrNotFound {
+		t.Errorf("You get a wrong error %v", err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	m := newTestCache(0)
+	defer m.Close()
+	c := cache.New(time.Minute, 0)
+	c.SetStore(m.Store())
+	if err := c.SetSync("a", "value", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if val, found := m.Get("a"); !found || val != "value" {
+		t.Error("The value must be written through")
+	}
+	m.Set("b", "loaded", 0)
+	if val, found := c.Get("b"); !found || val != "loaded" {
+		t.Error("The value must be read through")
+	}
+}
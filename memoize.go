@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// MemoizePolicy configure a function memoized by Memoize.
+type MemoizePolicy struct {
+	// TTL is how long the results are cached, as the dur of Set.
+	TTL time.Duration
+	// ErrorTTL is how long the errors are cached, so a failing backend is
+	// not called again for every key. The errors are not cached if it is
+	// 0.
+	ErrorTTL time.Duration
+}
+
+// memoKey is the key of a result of a memoized function in the cache, so
+// several functions can share a cache.
+type memoKey struct {
+	fn  *int
+	key interface{}
+}
+
+// Memoize return a function calling f once per key and returning its result
+// from c until it expires, see MemoizePolicy. The concurrent calls for a key
+// missing from the cache share one call of f, like GetOrLoad.
+func Memoize(c *Cache, f func(key interface{}) (interface{}, error), p MemoizePolicy) func(key interface{}) (interface{}, error) {
+	fn := new(int)
+	return func(key interface{}) (interface{}, error) {
+		k := memoKey{fn, key}
+		val, found := c.Get(k)
+		if !found {
+			var err error
+			val, err = c.load(context.Background(), k, func() (interface{}, time.Duration, error) {
+				val, err := f(key)
+				if err != nil && p.ErrorTTL != 0 {
+					return Negative{err}, p.ErrorTTL, nil
+				}
+				return val, p.TTL, err
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if n, ok := val.(Negative); ok {
+			return nil, n.error()
+		}
+		return val, nil
+	}
+}
+
+// MemoizeFunc is Memoize for a function of typed keys and values.
+func MemoizeFunc[K comparable, V any](c *Cache, f func(key K) (V, error), p MemoizePolicy) func(key K) (V, error) {
+	memoized := Memoize(c, func(key interface{}) (interface{}, error) {
+		return f(key.(K))
+	}, p)
+	return func(key K) (V, error) {
+		val, err := memoized(key)
+		v, _ := val.(V)
+		return v, err
+	}
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoize(t *testing.T) {
+	c := New(0, 0)
+	var calls int32
+	f := Memoize(c, func(key interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(time.Millisecond)
+		if key == "bad" {
+			return nil, errors.New("Bad key")
+		}
+		return key.(string) + "!", nil
+	}, MemoizePolicy{TTL: time.Minute})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if val, err := f("a"); err != nil || val != "a!" {
+				t.Error("You get a wrong result", val, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("The function must be called once, not %d times", calls)
+	}
+	f("bad")
+	if _, err := f("bad"); err == nil || calls != 3 {
+		t.Error("The errors must not be cached")
+	}
+	c.Set("a", "other", 0)
+	if val, _ := f("a"); val != "a!" {
+		t.Error("The results must not collide with the keys of the cache")
+	}
+}
+
+func TestMemoizeErrors(t *testing.T) {
+	calls := 0
+	f := MemoizeFunc(New(0, 0), func(key int) (string, error) {
+		calls++
+		if key < 0 {
+			return "", errors.New("Negative key")
+		}
+		return strconv.Itoa(key), nil
+	}, MemoizePolicy{TTL: time.Minute, ErrorTTL: time.Millisecond})
+	if val, err := f(1); err != nil || val != "1" {
+		t.Error("You get a wrong result", val, err)
+	}
+	f(-1)
+	if _, err := f(-1); err == nil || calls != 2 {
+		t.Error("The error must be cached")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if f(-1); calls != 3 {
+		t.Error("Now, the error is expired")
+	}
+}
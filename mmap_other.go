@@ -0,0 +1,16 @@
+//go:build !unix
+
+package cache
+
+import (
+	"errors"
+	"os"
+)
+
+func mmap(f *os.File, size int) ([]byte, error) {
+	return nil, errors.New("The memory mapping is not supported on this platform")
+}
+
+func munmap(data []byte) error {
+	return nil
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// Negative is the value of a key cached as missing, so the backend is not
+// asked again for it until it expires. Get returns it as the value of the
+// key, and Fetch and GetOrLoad return its error.
+type Negative struct {
+	// Err is the error of the lookup, ErrNotFound if it is nil.
+	Err error
+}
+
+func (n Negative) error() error {
+	if n.Err == nil {
+		return ErrNotFound
+	}
+	return n.Err
+}
+
+// GobEncode encode the text of the error, as most errors have no exported
+// field for gob, so Save and the Snapshotter keep the Negative values.
+func (n Negative) GobEncode() ([]byte, error) {
+	if n.Err == nil {
+		return nil, nil
+	}
+	return []byte(n.Err.Error()), nil
+}
+
+// GobDecode decode a Negative encoded by GobEncode. Its error is ErrNotFound
+// if it had the same text, otherwise a new error with the text.
+func (n *Negative) GobDecode(data []byte) error {
+	switch text := string(data); {
+	case text == "":
+		n.Err = nil
+	case text == ErrNotFound.Error():
+		n.Err = ErrNotFound
+	default:
+		n.Err = errors.New(text)
+	}
+	return nil
+}
+
+// SetNegative cache a key as missing for dur, like Set, with the error err
+// of its lookup, which may be nil.
+func (c *Cache) SetNegative(key interface{}, err error, dur time.Duration) {
+	c.Set(key, Negative{err}, dur)
+}
+
+// SetNegativeTTL make the errors of the Store and of the loaders of
+// GetOrLoad cached as Negative values for dur, which is typically shorter
+// than the TTL of the values. The dur is 0 means the errors are not cached.
+func (c *Cache) SetNegativeTTL(dur time.Duration) {
+	c.Lock()
+	c.negativeTTL = dur
+	c.Unlock()
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSetNegative(t *testing.T) {
+	c := New(0, 0)
+	c.SetNegative("a", nil, time.Minute)
+	if val, found := c.Get("a"); !found || val != (Negative{}) {
+		t.Error("Get must return the negative marker")
+	}
+	if _, err := c.Fetch("a"); err != ErrNotFound {
+		t.Errorf("You get a wrong error %v", err)
+	}
+	errGone := errors.New("Gone")
+	c.SetNegative("b", errGone, time.Minute)
+	if _, err := c.GetOrLoad("b", 0, func() (interface{}, error) { return 1, nil }); err != errGone {
+		t.Error("GetOrLoad must return the cached error")
+	}
+}
+
+func TestNegativeTTL(t *testing.T) {
+	s := &mapStore{data: map[interface{}]interface{}{}}
+	c := New(0, 0)
+	c.SetStore(s)
+	c.SetNegativeTTL(time.Millisecond)
+	c.Get("a")
+	if _, err := c.Fetch("a"); err != ErrNotFound || s.loads != 1 {
+		t.Errorf("The miss of the store must be cached, %d loads", s.loads)
+	}
+	time.Sleep(2 * time.Millisecond)
+	c.Get("a")
+	if s.loads != 2 {
+		t.Error("Now, the negative entry is expired")
+	}
+	calls := 0
+	for i := 0; i < 2; i++ {
+		c.GetOrLoad("b", 0, func() (interface{}, error) {
+			calls++
+			return nil, errors.New("Down")
+		})
+	}
+	if calls != 1 {
+		t.Error("The error of the loader must be cached")
+	}
+}
+
+func TestSaveNegative(t *testing.T) {
+	c := New(0, 0)
+	c.SetNegative("a", ErrNotFound, time.Minute)
+	c.SetNegative("b", errors.New("The backend is down"), time.Minute)
+	c.SetNegative("c", nil, time.Minute)
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	c2 := New(0, 0)
+	if err := c2.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c2.Fetch("a"); err != ErrNotFound {
+		t.Error("You get a wrong error", err)
+	}
+	if _, err := c2.Fetch("b"); err == nil || err.Error() != "The backend is down" {
+		t.Error("You get a wrong error", err)
+	}
+	if val, _ := c2.Get("c"); val != (Negative{}) {
+		t.Error("You get a wrong value", val)
+	}
+}
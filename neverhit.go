@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// NeverHitReport tells how many of the items which expired or were evicted
+// were never read, to find out what is cached for nothing.
+type NeverHitReport struct {
+	// Removed is the number of items which expired or were evicted.
+	Removed uint64
+	// NeverHit is the number of them which were never found by Get.
+	NeverHit uint64
+	// Rate is NeverHit divided by Removed.
+	Rate float64
+	// TopPrefixes are the key prefixes with the most never-hit items.
+	TopPrefixes []PrefixCount
+}
+
+// PrefixCount is the number of never-hit items of a key prefix.
+type PrefixCount struct {
+	Prefix string
+	Count  uint64
+}
+
+type neverHitStats struct {
+	prefix   func(key interface{}) string
+	removed  uint64
+	neverHit uint64
+	prefixes map[string]uint64
+}
+
+// TrackNeverHit start counting the hits of every item, to report the items
+// which expire or get evicted without having been read. The prefix function
+// groups keys in the report; if it is nil, string keys are grouped by what
+// comes before their first ':' and other keys by type. Tracking again
+// resets the report.
+func (c *Cache) TrackNeverHit(prefix func(key interface{}) string) {
+	if prefix == nil {
+		prefix = defaultKeyPrefix
+	}
+	c.Lock()
+	c.neverHit = &neverHitStats{
+		prefix:   prefix,
+		prefixes: map[string]uint64{},
+	}
+	c.Unlock()
+}
+
+// NeverHit return the report of the never-hit items since TrackNeverHit,
+// with the topN prefixes having the most of them.
+func (c *Cache) NeverHit(topN int) NeverHitReport {
+	c.RLock()
+	defer c.RUnlock()
+	var report NeverHitReport
+	stats := c.neverHit
+	if stats == nil {
+		return report
+	}
+	report.Removed = stats.removed
+	report.NeverHit = stats.neverHit
+	if stats.removed > 0 {
+		report.Rate = float64(stats.neverHit) / float64(stats.removed)
+	}
+	for prefix, count := range stats.prefixes {
+		report.TopPrefixes = append(report.TopPrefixes, PrefixCount{Prefix: prefix, Count: count})
+	}
+	sort.Slice(report.TopPrefixes, func(i, j int) bool {
+		a, b := report.TopPrefixes[i], report.TopPrefixes[j]
+		return a.Count > b.Count || (a.Count == b.Count && a.Prefix < b.Prefix)
+	})
+	if topN >= 0 && len(report.TopPrefixes) > topN {
+		report.TopPrefixes = report.TopPrefixes[:topN]
+	}
+	return report
+}
+
+// recordRemoval count an item which expired or was evicted. The caller must
+// hold the lock.
+func (c *Cache) recordRemoval(key interface{}, item *Item) {
+	stats := c.neverHit
+	if stats == nil {
+		return
+	}
+	stats.removed++
+	if atomic.LoadInt64(&item.hits) == 0 {
+		stats.neverHit++
+		stats.prefixes[stats.prefix(key)]++
+	}
+}
+
+func defaultKeyPrefix(key interface{}) string {
+	if s, ok := key.(string); ok {
+		if i := strings.IndexByte(s, ':'); i >= 0 {
+			return s[:i]
+		}
+		return s
+	}
+	return fmt.Sprintf("%T", key)
+}
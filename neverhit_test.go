@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNeverHit(t *testing.T) {
+	c := New(0, 0)
+	if report := c.NeverHit(10); report.Removed != 0 {
+		t.Error("Nothing is tracked yet")
+	}
+	c.TrackNeverHit(nil)
+	c.Set("user:1", 1, 10*time.Millisecond)
+	c.Set("user:2", 2, 10*time.Millisecond)
+	c.Set("page:1", 3, 10*time.Millisecond)
+	c.Set("page:2", 4, 10*time.Millisecond)
+	c.Set(42, 5, 10*time.Millisecond)
+	c.Get("page:1")
+	c.Get("page:2")
+	time.Sleep(20 * time.Millisecond)
+	c.DeleteExpired()
+	report := c.NeverHit(1)
+	if report.Removed != 5 || report.NeverHit != 3 {
+		t.Error("You get a wrong report", report)
+	}
+	if report.Rate != 0.6 {
+		t.Error("You get a wrong rate", report.Rate)
+	}
+	if len(report.TopPrefixes) != 1 || report.TopPrefixes[0] != (PrefixCount{"user", 2}) {
+		t.Error("You get wrong top prefixes", report.TopPrefixes)
+	}
+}
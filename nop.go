@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"time"
+)
+
+// NopCache is a cache which stores nothing: Get always misses. It satisfies
+// Interface and BoundedCache, so the caching can be disabled by the
+// configuration, see PolicyNone, without nil checks in the callers.
+type NopCache struct{}
+
+// Get always return nil and false.
+func (NopCache) Get(key interface{}) (interface{}, bool) {
+	return nil, false
+}
+
+// Set does nothing.
+func (NopCache) Set(key interface{}, val interface{}, dur time.Duration) {}
+
+// Delete does nothing.
+func (NopCache) Delete(key interface{}) {}
+
+// Add does nothing.
+func (NopCache) Add(key interface{}, value interface{}) {}
+
+// Remove does nothing.
+func (NopCache) Remove(key interface{}) {}
+
+// Len always return 0.
+func (NopCache) Len() int {
+	return 0
+}
+
+// Clear does nothing.
+func (NopCache) Clear() {}
+
+// SetMaxEntries does nothing, and accepts any limit.
+func (NopCache) SetMaxEntries(max int) error {
+	return nil
+}
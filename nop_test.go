@@ -0,0 +1,21 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestNopCache(t *testing.T) {
+	var c Interface = NopCache{}
+	c.Set("a", 1, 0)
+	if _, found := c.Get("a"); found {
+		t.Error("Impossiable!")
+	}
+	b, err := NewBounded(PolicyNone, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Add("a", 1)
+	if _, found := b.Get("a"); found || b.Len() != 0 {
+		t.Error("The none policy must store nothing")
+	}
+}
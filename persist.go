@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Save write the items of the cache, with their expiration, to w using
+// gob. The concrete types of keys and values are registered with gob, so
+// Load can decode them in a process which registered them too.
+func (c *Cache) Save(w io.Writer) (err error) {
+	enc := gob.NewEncoder(w)
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("Error registering item types with gob: %v", x)
+		}
+	}()
+	c.RLock()
+	defer c.RUnlock()
+	items := c.items
+	if c.accessCheck != nil {
+		items = make(map[interface{}]*Item, len(c.items))
+		for k, v := range c.items {
+			if c.access(k, OpGet) == nil {
+				items[k] = v
+			}
+		}
+	}
+	for k, v := range items {
+		registerType(k)
+		registerType(v.Object)
+	}
+	err = enc.Encode(&items)
+	return
+}
+
+// registerType register the concrete type of v with gob, unless v is nil,
+// which has no type and is encoded as is.
+func registerType(v interface{}) {
+	if v != nil {
+		gob.Register(v)
+	}
+}
+
+// SaveFile save the items of the cache to a file, creating it if it does
+// not exist and overwriting it otherwise.
+func (c *Cache) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = c.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load add the items written by Save to the cache, keeping their
+// expiration. Items already expired are skipped, and existing items which
+// are not expired are not replaced. If an item does not fit, see
+// SetFullPolicy and SetMaxValueSize, the others are loaded and the first
+// error is returned.
+func (c *Cache) Load(r io.Reader) error {
+	dec := gob.NewDecoder(r)
+	items := map[interface{}]*Item{}
+	if err := dec.Decode(&items); err != nil {
+		return err
+	}
+	return c.loadItems(items)
+}
+
+// loadItems add the loaded items which are not expired and whose key is not
+// in the cache already, and return the first error of the items which do
+// not fit.
+func (c *Cache) loadItems(items map[interface{}]*Item) (err error) {
+	c.Lock()
+	defer c.unlockAndNotify()
+	now := c.now()
+	for k, v := range items {
+		if v.expiredAt(now) || !c.keyAllowed(k) || c.access(k, OpSet) != nil {
+			continue
+		}
+		if old, ok := c.items[k]; ok && !old.expiredAt(now) {
+			continue
+		}
+		if ierr := c.restore(k, v); err == nil {
+			err = ierr
+		}
+	}
+	return err
+}
+
+// LoadFile load the items saved by SaveFile.
+func (c *Cache) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
+
+// lruSavedEntry is an entry of a LRUCache written by Save.
+type lruSavedEntry struct {
+	Key        interface{}
+	Value      interface{}
+	Expiration *time.Time
+	Weight     int64
+}
+
+// Save write the entries of the LRUCache to w using gob, the most recently
+// used first, so Load restores their order as well. The concrete types of
+// keys and values are registered with gob, like Cache.Save.
+func (c *LRUCache) Save(w io.Writer) (err error) {
+	enc := gob.NewEncoder(w)
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("Error registering item types with gob: %v", x)
+		}
+	}()
+	c.RLock()
+	entries := make([]lruSavedEntry, 0, c.cacheList.Len())
+	for e := c.cacheList.Front(); e != nil; e = e.Next() {
+		ent := e.Value.(*entry)
+		registerType(ent.key)
+		registerType(ent.value)
+		entries = append(entries, lruSavedEntry{ent.key, ent.value, ent.expiration, ent.weight})
+	}
+	c.RUnlock()
+	err = enc.Encode(entries)
+	return
+}
+
+// Load add the entries written by Save to the LRUCache, in their order but
+// as less recently used than the entries already in it, which are kept.
+// The expired entries are skipped, and the least recently used ones which
+// do not fit the max size or weight are not loaded.
+func (c *LRUCache) Load(r io.Reader) error {
+	var entries []lruSavedEntry
+	if err := gob.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+	now := time.Now()
+	c.Lock()
+	defer c.Unlock()
+	for _, saved := range entries {
+		if c.maxEntries > 0 && c.cacheList.Len() >= c.maxEntries {
+			break
+		}
+		ent := &entry{key: saved.Key, value: saved.Value, expiration: saved.Expiration, weight: saved.Weight}
+		if _, ok := c.items[ent.key]; ok || ent.expired(now) {
+			continue
+		}
+		if c.maxWeight > 0 && c.weight+ent.weight > c.maxWeight {
+			continue
+		}
+		c.items[ent.key] = c.cacheList.PushBack(ent)
+		c.weight += ent.weight
+	}
+	c.stats.observe(c.cacheList.Len(), c.weight)
+	return nil
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type savedValue struct {
+	Name string
+}
+
+func TestSaveLoad(t *testing.T) {
+	c := New(0, 0)
+	c.Set("a", 1, -1)
+	c.Set(2, savedValue{"b"}, time.Hour)
+	c.Set("expired", 3, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	c2 := New(0, 0)
+	c2.Set("a", 0, -1)
+	if err := c2.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := c2.Get("a"); val != 0 {
+		t.Error("Load must not replace an existing item")
+	}
+	if val, found := c2.Get(2); !found || val != (savedValue{"b"}) {
+		t.Error("You get a wrong loaded value", val)
+	}
+	if c2.ItemCount() != 2 {
+		t.Error("The expired item must not be loaded")
+	}
+	c2.RLock()
+	exp := c2.items[2].Expiration
+	c2.RUnlock()
+	if exp == nil || exp.Before(time.Now().Add(59*time.Minute)) {
+		t.Error("The expiration must be kept")
+	}
+}
+
+func TestSaveLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+	c := New(0, 0)
+	c.Set("key", "val", 0)
+	if err := c.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	c2 := New(0, 0)
+	if err := c2.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := c2.Get("key"); val != "val" {
+		t.Error("You get a wrong loaded value")
+	}
+	if err := c2.LoadFile(path + ".missing"); !os.IsNotExist(err) {
+		t.Error("Loading a missing file must fail")
+	}
+}
+
+func TestLRUSaveLoad(t *testing.T) {
+	lru, _ := NewLRU(0)
+	lru.Add("a", 1)
+	lru.Add("b", savedValue{"b"})
+	lru.AddWithTTL("expired", 3, time.Nanosecond)
+	lru.Add("c", 3)
+	lru.Get("a")
+	var buf bytes.Buffer
+	if err := lru.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+
+	restored, _ := NewLRU(3)
+	restored.Add("c", "kept")
+	if err := restored.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Len() != 3 {
+		t.Error("You get a wrong len", restored.Len())
+	}
+	if val, _ := restored.Peek("c"); val != "kept" {
+		t.Error("The existing entries must be kept", val)
+	}
+	if val, _ := restored.Peek("b"); val != (savedValue{"b"}) {
+		t.Error("You get a wrong value", val)
+	}
+	// The order is c, then the saved order a, b.
+	restored.Add("d", 4)
+	if restored.Contains("b") || !restored.Contains("a") {
+		t.Error("The least recently used entry must be evicted first")
+	}
+}
+
+func TestSaveLoadNil(t *testing.T) {
+	c := New(0, 0)
+	c.Set("a", nil, 0)
+	c.Set(nil, 1, 0)
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	c2 := New(0, 0)
+	if err := c2.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if val, found := c2.Get("a"); !found || val != nil {
+		t.Error("The nil value must be loaded", val, found)
+	}
+	if val, found := c2.Get(nil); !found || val != 1 {
+		t.Error("The nil key must be loaded", val, found)
+	}
+
+	l, _ := NewLRU(0)
+	l.Add("a", nil)
+	buf.Reset()
+	if err := l.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := c.SaveCodec(&buf, GobCodec{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"math/rand"
+	"sync"
+)
+
+// EvictionPolicy decide which entry a PolicyCache evicts when it is full.
+// The methods are called with the lock of the cache held, so an
+// implementation used by a single cache needs no locking of its own.
+type EvictionPolicy interface {
+	// RecordInsert is called when a new key is added.
+	RecordInsert(key interface{})
+	// RecordAccess is called when an existing key is read or updated.
+	RecordAccess(key interface{})
+	// Remove is called when a key leaves the cache for any reason.
+	Remove(key interface{})
+	// Victim return the key to evict, and false if there is no key.
+	Victim() (interface{}, bool)
+}
+
+// PolicyCache is a goroutine-safe cache holding a limited number of entries
+// and evicting them in the order chosen by an EvictionPolicy.
+type PolicyCache struct {
+	sync.Mutex
+	maxEntries int
+	items      map[interface{}]interface{}
+	policy     EvictionPolicy
+}
+
+// NewWithPolicy create a PolicyCache with max size which evicts entries
+// chosen by policy. The size is 0 means no limit. The policy must not be
+// shared with another cache.
+func NewWithPolicy(size int, policy EvictionPolicy) (*PolicyCache, error) {
+	if size < 0 {
+		return nil, errors.New("The size of Policy Cache must no less than 0")
+	}
+	if policy == nil {
+		return nil, errors.New("The eviction policy must not be nil")
+	}
+	c := &PolicyCache{
+		maxEntries: size,
+		items:      make(map[interface{}]interface{}, size),
+		policy:     policy,
+	}
+	return c, nil
+}
+
+// Add a new key-value pair to the PolicyCache.
+func (c *PolicyCache) Add(key interface{}, value interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if _, hit := c.items[key]; hit {
+		c.items[key] = value
+		c.policy.RecordAccess(key)
+		return
+	}
+	if c.maxEntries > 0 && len(c.items) >= c.maxEntries {
+		c.evict()
+	}
+	c.items[key] = value
+	c.policy.RecordInsert(key)
+}
+
+// Get a value from the PolicyCache. And a bool indicating
+// whether found or not.
+func (c *PolicyCache) Get(key interface{}) (interface{}, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if value, hit := c.items[key]; hit {
+		c.policy.RecordAccess(key)
+		return value, true
+	}
+	return nil, false
+}
+
+// Remove a key-value pair in PolicyCache. If the key is not existed,
+// nothing will happen.
+func (c *PolicyCache) Remove(key interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if _, hit := c.items[key]; hit {
+		delete(c.items, key)
+		c.policy.Remove(key)
+	}
+}
+
+// Return the number of key-value pair in PolicyCache.
+func (c *PolicyCache) Len() int {
+	c.Lock()
+	length := len(c.items)
+	c.Unlock()
+	return length
+}
+
+// Delete all entry in the PolicyCache. But the max size will hold.
+func (c *PolicyCache) Clear() {
+	c.Lock()
+	for key := range c.items {
+		c.policy.Remove(key)
+	}
+	c.items = make(map[interface{}]interface{}, c.maxEntries)
+	c.Unlock()
+}
+
+// Resize the max limit. If the cache holds more entries than the new limit,
+// the policy chooses which ones are evicted.
+func (c *PolicyCache) SetMaxEntries(max int) error {
+	if max < 0 {
+		return errors.New("The max limit of entryies must no less than 0")
+	}
+	c.Lock()
+	c.maxEntries = max
+	for max > 0 && len(c.items) > max {
+		c.evict()
+	}
+	c.Unlock()
+	return nil
+}
+
+func (c *PolicyCache) evict() {
+	if key, ok := c.policy.Victim(); ok {
+		delete(c.items, key)
+		c.policy.Remove(key)
+	}
+}
+
+// NewLRUPolicy return an EvictionPolicy evicting the least recently used key.
+func NewLRUPolicy() EvictionPolicy {
+	return &listPolicy{moveOnAccess: true, elements: map[interface{}]*list.Element{}, order: list.New()}
+}
+
+// NewFIFOPolicy return an EvictionPolicy evicting the oldest inserted key.
+func NewFIFOPolicy() EvictionPolicy {
+	return &listPolicy{elements: map[interface{}]*list.Element{}, order: list.New()}
+}
+
+// NewLFUPolicy return an EvictionPolicy evicting the least frequently used
+// key, the least recently used one among keys of the same frequency.
+func NewLFUPolicy() EvictionPolicy {
+	lfu, _ := NewLFU(0)
+	return &lfuPolicy{lfu: lfu}
+}
+
+// NewRandomPolicy return an EvictionPolicy evicting a random key.
+func NewRandomPolicy() EvictionPolicy {
+	return &randomPolicy{index: map[interface{}]int{}}
+}
+
+// listPolicy keeps the keys in a list, the victim at the back. It is the
+// LRU policy if accesses move keys to the front, the FIFO policy otherwise.
+type listPolicy struct {
+	moveOnAccess bool
+	elements     map[interface{}]*list.Element
+	order        *list.List
+}
+
+func (p *listPolicy) RecordInsert(key interface{}) {
+	p.elements[key] = p.order.PushFront(key)
+}
+
+func (p *listPolicy) RecordAccess(key interface{}) {
+	if ele, ok := p.elements[key]; ok && p.moveOnAccess {
+		p.order.MoveToFront(ele)
+	}
+}
+
+func (p *listPolicy) Remove(key interface{}) {
+	if ele, ok := p.elements[key]; ok {
+		p.order.Remove(ele)
+		delete(p.elements, key)
+	}
+}
+
+func (p *listPolicy) Victim() (interface{}, bool) {
+	if ele := p.order.Back(); ele != nil {
+		return ele.Value, true
+	}
+	return nil, false
+}
+
+// lfuPolicy reuses the frequency buckets of an unbounded LFUCache without
+// values.
+type lfuPolicy struct {
+	lfu *LFUCache
+}
+
+func (p *lfuPolicy) RecordInsert(key interface{}) {
+	p.lfu.Add(key, nil)
+}
+
+func (p *lfuPolicy) RecordAccess(key interface{}) {
+	p.lfu.Get(key)
+}
+
+func (p *lfuPolicy) Remove(key interface{}) {
+	p.lfu.Remove(key)
+}
+
+func (p *lfuPolicy) Victim() (interface{}, bool) {
+	p.lfu.Lock()
+	defer p.lfu.Unlock()
+	if front := p.lfu.freqList.Front(); front != nil {
+		return front.Value.(*lfuBucket).entries.Back().Value.(*lfuEntry).key, true
+	}
+	return nil, false
+}
+
+type randomPolicy struct {
+	keys  []interface{}
+	index map[interface{}]int
+}
+
+func (p *randomPolicy) RecordInsert(key interface{}) {
+	p.index[key] = len(p.keys)
+	p.keys = append(p.keys, key)
+}
+
+func (p *randomPolicy) RecordAccess(key interface{}) {}
+
+func (p *randomPolicy) Remove(key interface{}) {
+	idx, ok := p.index[key]
+	if !ok {
+		return
+	}
+	last := len(p.keys) - 1
+	p.keys[idx] = p.keys[last]
+	p.index[p.keys[idx]] = idx
+	p.keys[last] = nil
+	p.keys = p.keys[:last]
+	delete(p.index, key)
+}
+
+func (p *randomPolicy) Victim() (interface{}, bool) {
+	if len(p.keys) == 0 {
+		return nil, false
+	}
+	return p.keys[rand.Intn(len(p.keys))], true
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestPolicyCache(t *testing.T) {
+	if _, err := NewWithPolicy(-1, NewLRUPolicy()); err == nil {
+		t.Error("Impossiable!")
+	}
+	if _, err := NewWithPolicy(1, nil); err == nil {
+		t.Error("The policy must be checked")
+	}
+	tests := []struct {
+		name    string
+		policy  EvictionPolicy
+		evicted string
+	}{
+		{"LRU", NewLRUPolicy(), "2"},
+		{"FIFO", NewFIFOPolicy(), "1"},
+		{"LFU", NewLFUPolicy(), "2"},
+	}
+	for _, test := range tests {
+		var c BoundedCache
+		c, err := NewWithPolicy(2, test.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Add("1", 111)
+		c.Add("2", 222)
+		c.Get("1")
+		c.Add("3", 333)
+		if _, hit := c.Get(test.evicted); hit {
+			t.Errorf("%s: the key %s must be evicted", test.name, test.evicted)
+		}
+		if c.Len() != 2 {
+			t.Errorf("%s: now, the len of cache must be 2", test.name)
+		}
+		c.Remove("3")
+		c.Clear()
+		if c.Len() != 0 {
+			t.Errorf("%s: now, the cache is cleared", test.name)
+		}
+	}
+}
+
+func TestRandomPolicy(t *testing.T) {
+	c, _ := NewWithPolicy(10, NewRandomPolicy())
+	for i := 0; i < 100; i++ {
+		c.Add(i, i)
+	}
+	if c.Len() != 10 {
+		t.Error("Now, the len of cache must be 10")
+	}
+	c.SetMaxEntries(3)
+	if c.Len() != 3 {
+		t.Error("The cache must be shrunk to the new limit")
+	}
+	for i := 0; i < 100; i++ {
+		c.Remove(i)
+	}
+	if c.Len() != 0 {
+		t.Error("All values must be removed")
+	}
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"time"
+)
+
+// RateCounter counts events by key in fixed windows of time, stored in a
+// Cache as int64 values expiring at the end of their window. Unlike a Get
+// followed by an Increment or a Set, the count is read and updated under
+// one lock, so concurrent callers can not lose updates, for example to rate
+// limit an API.
+type RateCounter struct {
+	c *Cache
+}
+
+// NewRateCounter create a RateCounter storing its counts in c. The keys of
+// the counters should not be used for other values, which are replaced.
+func NewRateCounter(c *Cache) *RateCounter {
+	return &RateCounter{c: c}
+}
+
+// IncrWithTTL add delta to the count of the key and return it. If the key
+// is not found or expired, a new window starts: the count is delta and
+// expires after window. The next calls do not extend the window.
+func (r *RateCounter) IncrWithTTL(key interface{}, delta int64, window time.Duration) int64 {
+	if !r.c.keyAllowed(key) {
+		return delta
+	}
+	r.c.Lock()
+	if r.c.access(key, OpSet) != nil {
+		r.c.Unlock()
+		return delta
+	}
+	n := r.c.incrWithTTL(key, delta, window)
+	r.c.unlockAndNotify()
+	return n
+}
+
+// Allow count an event of the key and return true if there were less than
+// limit events in its current window, which starts with the first event
+// and lasts window. The events which are not allowed are not counted.
+func (r *RateCounter) Allow(key interface{}, limit int64, window time.Duration) bool {
+	c := r.c
+	if !c.keyAllowed(key) {
+		return false
+	}
+	c.Lock()
+	defer c.unlockAndNotify()
+	if c.access(key, OpSet) != nil || c.windowCount(key) >= limit {
+		return false
+	}
+	c.incrWithTTL(key, 1, window)
+	return true
+}
+
+// Count return the count of the key in its current window, 0 if it is not
+// found or expired.
+func (r *RateCounter) Count(key interface{}) int64 {
+	if !r.c.keyAllowed(key) {
+		return 0
+	}
+	r.c.RLock()
+	defer r.c.RUnlock()
+	if r.c.access(key, OpGet) != nil {
+		return 0
+	}
+	return r.c.windowCount(key)
+}
+
+// windowCount return the count of a RateCounter. The caller must hold the
+// lock, the read lock is enough.
+func (c *Cache) windowCount(key interface{}) int64 {
+	item, ok := c.items[key]
+	if !ok || c.expired(item) {
+		return 0
+	}
+	n, _ := item.Object.(int64)
+	return n
+}
+
+// incrWithTTL is IncrWithTTL. The caller must hold the lock and have
+// checked the key.
+func (c *Cache) incrWithTTL(key interface{}, delta int64, window time.Duration) int64 {
+	item, ok := c.items[key]
+	if ok && !c.expired(item) {
+		if n, ok := item.Object.(int64); ok {
+			item = c.writable(key, item)
+			item.Object = n + delta
+			item.written = c.now().UnixNano()
+			item.version = c.nextVersion()
+			c.watchEvent(EventSet, key, item.Object)
+			c.publishInvalidation(key)
+			return n + delta
+		}
+	}
+	if !c.tombstoned(key) {
+		c.set(key, delta, window)
+	}
+	return delta
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateCounter(t *testing.T) {
+	c := New(0, 0)
+	clk := &fixedClock{now: time.Unix(1000, 0)}
+	c.SetClock(clk)
+	r := NewRateCounter(c)
+	if n := r.IncrWithTTL("a", 2, time.Minute); n != 2 {
+		t.Error("You get a wrong count", n)
+	}
+	clk.now = clk.now.Add(30 * time.Second)
+	if n := r.IncrWithTTL("a", 3, time.Minute); n != 5 {
+		t.Error("You get a wrong count", n)
+	}
+	clk.now = clk.now.Add(31 * time.Second)
+	if n := r.Count("a"); n != 0 {
+		t.Error("The window must not be extended", n)
+	}
+	if n := r.IncrWithTTL("a", 1, time.Minute); n != 1 {
+		t.Error("Now, a new window starts", n)
+	}
+
+	for i := 0; i < 3; i++ {
+		if !r.Allow("b", 3, time.Second) {
+			t.Error("The event must be allowed", i)
+		}
+	}
+	if r.Allow("b", 3, time.Second) || r.Count("b") != 3 {
+		t.Error("The event over the limit must not be allowed nor counted")
+	}
+	clk.now = clk.now.Add(time.Second + 1)
+	if !r.Allow("b", 3, time.Second) {
+		t.Error("Now, the window is over")
+	}
+}
+
+func TestRateCounterConcurrent(t *testing.T) {
+	r := NewRateCounter(New(0, 0))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if r.Allow("key", 50, time.Hour) {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 50 {
+		t.Error("You get a wrong number of allowed events", allowed)
+	}
+}
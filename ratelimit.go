@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket holding up to burst tokens, refilled at
+// rate tokens per second.
+type rateLimiter struct {
+	sync.Mutex
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	dropped uint64
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// allow take a token, or count a drop if there is none.
+func (l *rateLimiter) allow() bool {
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	if l.tokens < 1 {
+		l.dropped++
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// SetEvictionRateLimit limit the eviction callback to rate calls per second
+// on average, and burst calls at once. The calls beyond the limit are
+// dropped and counted by DroppedEvictions, so a mass eviction does not
+// flood the system the callback writes to. The rate is 0 means no limit.
+func (c *Cache) SetEvictionRateLimit(rate float64, burst int) error {
+	if rate < 0 {
+		return errors.New("The rate of eviction callbacks must no less than 0")
+	}
+	if rate > 0 && burst < 1 {
+		return errors.New("The burst of eviction callbacks must be more than 0")
+	}
+	c.Lock()
+	if rate == 0 {
+		c.evictLimit = nil
+	} else {
+		c.evictLimit = newRateLimiter(rate, burst)
+	}
+	c.Unlock()
+	return nil
+}
+
+// DroppedEvictions return the number of eviction callback calls dropped by
+// the rate limit since it was set.
+func (c *Cache) DroppedEvictions() uint64 {
+	c.RLock()
+	l := c.evictLimit
+	c.RUnlock()
+	if l == nil {
+		return 0
+	}
+	l.Lock()
+	defer l.Unlock()
+	return l.dropped
+}
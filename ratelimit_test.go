@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestEvictionRateLimit(t *testing.T) {
+	c := New(0, 0)
+	calls := 0
+	c.OnEvicted(func(key, value interface{}) {
+		calls++
+	})
+	if err := c.SetEvictionRateLimit(1, 0); err == nil {
+		t.Error("Impossiable!")
+	}
+	c.SetEvictionRateLimit(0.001, 3)
+	for i := 0; i < 10; i++ {
+		c.Set(i, i, -1)
+	}
+	c.Close()
+	if calls != 3 || c.DroppedEvictions() != 7 {
+		t.Errorf("You get %d calls and %d drops", calls, c.DroppedEvictions())
+	}
+	c.SetEvictionRateLimit(0, 0)
+	c.Set("a", 1, -1)
+	c.Delete("a")
+	if calls != 4 || c.DroppedEvictions() != 0 {
+		t.Error("Now, the rate limit is removed")
+	}
+}
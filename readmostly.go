@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// ReadMostlyCache is a goroutine-safe cache backed by a sync.Map, for the
+// caches written rarely and read by many goroutines: Get takes no lock, but
+// the writes are slower than the ones of Cache. The expired items are not
+// returned by Get, and are deleted by DeleteExpired.
+type ReadMostlyCache struct {
+	items             sync.Map
+	defaultExpiration time.Duration
+}
+
+type readMostlyItem struct {
+	val interface{}
+	// expiration is in UnixNano, 0 means never.
+	expiration int64
+}
+
+// NewReadMostly create a ReadMostlyCache with a given default expiration
+// duration, as New.
+func NewReadMostly(defaultExpiration time.Duration) *ReadMostlyCache {
+	return &ReadMostlyCache{defaultExpiration: defaultExpiration}
+}
+
+// Get return an item or nil, and a bool indicating whether the key was
+// found.
+func (c *ReadMostlyCache) Get(key interface{}) (interface{}, bool) {
+	v, ok := c.items.Load(key)
+	if !ok {
+		return nil, false
+	}
+	item := v.(*readMostlyItem)
+	if item.expiration != 0 && item.expiration < time.Now().UnixNano() {
+		return nil, false
+	}
+	return item.val, true
+}
+
+// Set add a new key or replace an exist key. If the dur is 0, we will use
+// the defaultExpiration.
+func (c *ReadMostlyCache) Set(key interface{}, val interface{}, dur time.Duration) {
+	if dur == 0 {
+		dur = c.defaultExpiration
+	}
+	item := &readMostlyItem{val: val}
+	if dur > 0 {
+		item.expiration = time.Now().Add(dur).UnixNano()
+	}
+	c.items.Store(key, item)
+}
+
+// Delete a key-value pair if the key is existed.
+func (c *ReadMostlyCache) Delete(key interface{}) {
+	c.items.Delete(key)
+}
+
+// Flush delete all the items.
+func (c *ReadMostlyCache) Flush() {
+	c.items.Range(func(key, value interface{}) bool {
+		c.items.Delete(key)
+		return true
+	})
+}
+
+// ItemCount return the number of items, the expired ones included. It
+// scans every item.
+func (c *ReadMostlyCache) ItemCount() int {
+	n := 0
+	c.items.Range(func(key, value interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// DeleteExpired delete all the expired items, and return their number.
+func (c *ReadMostlyCache) DeleteExpired() int {
+	now := time.Now().UnixNano()
+	n := 0
+	c.items.Range(func(key, value interface{}) bool {
+		item := value.(*readMostlyItem)
+		if item.expiration != 0 && item.expiration < now {
+			// Only delete the expired item, not a new one stored meanwhile.
+			if c.items.CompareAndDelete(key, item) {
+				n++
+			}
+		}
+		return true
+	})
+	return n
+}
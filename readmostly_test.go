@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadMostlyCache(t *testing.T) {
+	var c Interface = NewReadMostly(time.Hour)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if val, found := c.Get("a"); !found || val != 1 {
+		t.Error("You get a wrong value", val)
+	}
+	if _, found := c.Get("b"); found {
+		t.Error("The expired item must not be found")
+	}
+	rm := c.(*ReadMostlyCache)
+	if n := rm.DeleteExpired(); n != 1 || rm.ItemCount() != 1 {
+		t.Error("Now, only a is left", n)
+	}
+	c.Delete("a")
+	if _, found := c.Get("a"); found {
+		t.Error("Impossiable!")
+	}
+	c.Set("c", 3, -1)
+	rm.Flush()
+	if rm.ItemCount() != 0 {
+		t.Error("Now, the cache is flushed")
+	}
+}
+
+func TestReadMostlyCacheConcurrentGet(t *testing.T) {
+	c := NewReadMostly(0)
+	for i := 0; i < 16; i++ {
+		c.Set(i, i, 0)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if val, _ := c.Get(i % 16); val != i%16 {
+					t.Error("You get a wrong value", val)
+					return
+				}
+			}
+		}()
+	}
+	c.Set(16, 16, 0)
+	wg.Wait()
+}
@@ -0,0 +1,48 @@
+package cache
+
+// Range call f for every item which is not expired, until f returns false.
+// The read lock is held during the iteration, so f must not modify the
+// cache, unless the cache has snapshot reads, see SetSnapshotReads.
+func (c *Cache) Range(f func(key, value interface{}) bool) {
+	c.RLock()
+	if c.snapshotReads {
+		c.rangeSnapshot(f)
+		return
+	}
+	defer c.RUnlock()
+	for k, item := range c.items {
+		if c.expired(item) || !c.keyAllowed(k) || c.access(k, OpGet) != nil {
+			continue
+		}
+		if !f(k, c.copyValue(item.Object)) {
+			return
+		}
+	}
+}
+
+// ReadOnlyCache is a view of a Cache which can only be read, see ReadOnly.
+type ReadOnlyCache struct {
+	c *Cache
+}
+
+// ReadOnly return a view of the cache exposing only Get, Range and Stats,
+// to hand the cache to a plugin or an untrusted module without giving it
+// Delete or Flush.
+func (c *Cache) ReadOnly() *ReadOnlyCache {
+	return &ReadOnlyCache{c}
+}
+
+// Get works like Cache.Get.
+func (r *ReadOnlyCache) Get(key interface{}) (interface{}, bool) {
+	return r.c.Get(key)
+}
+
+// Range works like Cache.Range.
+func (r *ReadOnlyCache) Range(f func(key, value interface{}) bool) {
+	r.c.Range(f)
+}
+
+// Stats works like Cache.Stats.
+func (r *ReadOnlyCache) Stats() Stats {
+	return r.c.Stats()
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadOnly(t *testing.T) {
+	c := New(0, 0)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	r := c.ReadOnly()
+	if val, found := r.Get("a"); !found || val != 1 {
+		t.Error("You get a wrong value", val)
+	}
+	sum := 0
+	r.Range(func(key, value interface{}) bool {
+		sum += value.(int)
+		return true
+	})
+	if sum != 3 {
+		t.Error("Range must visit the items which are not expired", sum)
+	}
+	n := 0
+	r.Range(func(key, value interface{}) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Error("Range must stop when f returns false")
+	}
+	if s := r.Stats(); s.Hits != 1 || s.CurrentEntries != 3 {
+		t.Errorf("You get wrong stats %+v", s)
+	}
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is reported to the error handler when the janitor recovers a
+// panic, for example in a callback, see SetErrorHandler.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("Panic in the cleanup of the cache: %v", e.Value)
+}
+
+// SetErrorHandler set a function called with the errors of the janitor, a
+// *PanicError when a cleanup panics, for example in the eviction callback.
+// The janitor recovers the panics, so they do not kill the process, and
+// skips the next cleanups for a number of ticks doubling with every failure
+// in a row, up to 64. Without handler, the errors are dropped. The callbacks
+// are called without the lock held, so a panic in them leaves the cache
+// usable. Set to nil to remove it.
+func (c *Cache) SetErrorHandler(f func(error)) {
+	c.Lock()
+	c.errorHandler = f
+	c.Unlock()
+}
+
+// recoverCleanup run a cleanup of the janitor, and return a *PanicError
+// if it panics.
+func (c *Cache) recoverCleanup() (err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			err = &PanicError{Value: x, Stack: debug.Stack()}
+		}
+	}()
+	c.cleanup()
+	return nil
+}
+
+// reportError give err to the error handler, if any.
+func (c *Cache) reportError(err error) {
+	c.RLock()
+	f := c.errorHandler
+	c.RUnlock()
+	if f != nil {
+		f(err)
+	}
+}
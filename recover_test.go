@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJanitorRecover(t *testing.T) {
+	c := New(0, 0)
+	c.OnEvicted(func(k, v interface{}) {
+		panic("callback failed")
+	})
+	var mu sync.Mutex
+	var errs []error
+	c.SetErrorHandler(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+	j := newJanitor(time.Hour)
+	for i, backoff := range []int{0, 1, 3, 7} {
+		c.Set(i, i, time.Nanosecond)
+		time.Sleep(time.Millisecond)
+		j.cleanup(c)
+		if j.backoff != backoff {
+			t.Error("You get a wrong backoff", j.backoff)
+		}
+	}
+	if len(errs) != 4 {
+		t.Fatal("The panics must be reported", len(errs))
+	}
+	if pe, ok := errs[0].(*PanicError); !ok || pe.Value != "callback failed" || len(pe.Stack) == 0 {
+		t.Error("You get a wrong error", errs[0])
+	}
+	j.failures = 100
+	c.Set("b", 1, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	j.cleanup(c)
+	if j.backoff != janitorMaxBackoff {
+		t.Error("The backoff must be capped after many failures", j.backoff)
+	}
+	c.OnEvicted(nil)
+	c.Set("a", 1, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	j.cleanup(c)
+	if j.backoff != 0 || c.ItemCount() != 0 {
+		t.Error("Now, the cleanup succeeds")
+	}
+}
+
+func TestJanitorRecoverRunning(t *testing.T) {
+	c := New(0, time.Millisecond)
+	defer c.Close()
+	failed := make(chan error, 1)
+	c.SetErrorHandler(func(err error) {
+		select {
+		case failed <- err:
+		default:
+		}
+	})
+	c.OnEvicted(func(k, v interface{}) {
+		panic("callback failed")
+	})
+	c.Set("a", 1, time.Nanosecond)
+	select {
+	case <-failed:
+	case <-time.After(time.Second):
+		t.Fatal("The panic must be reported")
+	}
+	c.OnEvicted(nil)
+	c.Set("b", 2, 0)
+	if _, found := c.Get("b"); !found {
+		t.Error("The cache must be usable after a panic")
+	}
+}
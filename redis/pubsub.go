@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// PubSub is a cache.Transport over a Redis channel, for a
+// cache.InvalidationBus. It publishes with the connection of a Cache and
+// subscribes on a connection of its own, which can not send other commands.
+type PubSub struct {
+	c       *Cache
+	sub     io.ReadWriter
+	channel string
+	closed  int32
+}
+
+// PubSub create a PubSub publishing on channel with the connection of the
+// cache, and subscribing with sub.
+func (c *Cache) PubSub(sub io.ReadWriter, channel string) *PubSub {
+	return &PubSub{c: c, sub: sub, channel: channel}
+}
+
+// Publish a message on the channel.
+func (p *PubSub) Publish(msg []byte) error {
+	_, err := p.c.do([]string{"PUBLISH", p.channel, string(msg)})
+	return err
+}
+
+// Subscribe to the channel and call f with its messages until Close.
+func (p *PubSub) Subscribe(f func(msg []byte)) error {
+	cmd := fmt.Sprintf("*2\r\n$9\r\nSUBSCRIBE\r\n$%d\r\n%s\r\n", len(p.channel), p.channel)
+	if _, err := io.WriteString(p.sub, cmd); err != nil {
+		return p.closedErr(err)
+	}
+	r := bufio.NewReader(p.sub)
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return p.closedErr(err)
+		}
+		// A message is ["message", channel, payload].
+		if msg, ok := reply.([]interface{}); ok && len(msg) == 3 {
+			kind, _ := msg[0].([]byte)
+			payload, _ := msg[2].([]byte)
+			if string(kind) == "message" {
+				f(payload)
+			}
+		}
+	}
+}
+
+// Close the connection of the subscription if it is an io.Closer. The
+// connection of the cache is left open.
+func (p *PubSub) Close() error {
+	atomic.StoreInt32(&p.closed, 1)
+	if closer, ok := p.sub.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// closedErr return nil for the error of a connection closed by Close.
+func (p *PubSub) closedErr(err error) error {
+	if atomic.LoadInt32(&p.closed) == 1 {
+		return nil
+	}
+	return err
+}
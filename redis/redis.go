@@ -0,0 +1,355 @@
+// Package redis is a cache.Interface backed by a Redis server, so it can be
+// the L2 of a cache.TieredCache, or the store of a read-through or
+// write-through cache.Cache with Store, and a transport for a
+// cache.InvalidationBus with PubSub. It speaks the RESP protocol over a
+// single connection, without a client dependency.
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+// Error is an error reply of the server.
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Cache is a goroutine-safe cache of values in Redis. Keys are formatted
+// with fmt.Sprint and values are encoded by a cache.Codec, so with the
+// cache.GobCodec their concrete types must be registered with gob.Register.
+// The commands are sent one at a time over the connection. If a reply can
+// not be read, the connection is closed, as the next replies would not
+// match their commands, and dialed again by the next command if it was made
+// by Dial.
+type Cache struct {
+	mu                sync.Mutex
+	conn              io.ReadWriter
+	r                 *bufio.Reader
+	w                 *bufio.Writer
+	addr              string
+	broken            bool
+	codec             cache.Codec
+	prefix            string
+	defaultExpiration time.Duration
+	lastErr           error
+}
+
+// New create a Cache over a connection to a Redis server, like a net.Conn.
+// Keys are prefixed with prefix in Redis. Values set with a dur of 0 expire
+// after defaultExpiration, which is less than 1 means never.
+func New(conn io.ReadWriter, codec cache.Codec, prefix string, defaultExpiration time.Duration) *Cache {
+	return &Cache{
+		conn:              conn,
+		r:                 bufio.NewReader(conn),
+		w:                 bufio.NewWriter(conn),
+		codec:             codec,
+		prefix:            prefix,
+		defaultExpiration: defaultExpiration,
+	}
+}
+
+// Dial connect to the Redis server at addr and create a Cache over the
+// connection, like New.
+func Dial(addr string, codec cache.Codec, prefix string, defaultExpiration time.Duration) (*Cache, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := New(conn, codec, prefix, defaultExpiration)
+	c.addr = addr
+	return c, nil
+}
+
+// errBroken is returned by the commands of a Cache made by New once its
+// connection is closed after an error.
+var errBroken = errors.New("The connection to Redis is closed after an error")
+
+// reconnect dial the server again if the connection was closed after an
+// error. The caller must hold the lock.
+func (c *Cache) reconnect() error {
+	if !c.broken {
+		return nil
+	}
+	if c.addr == "" {
+		return errBroken
+	}
+	conn, err := net.Dial("tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	c.conn, c.broken = conn, false
+	c.r.Reset(conn)
+	c.w.Reset(conn)
+	return nil
+}
+
+// fail close the connection after an error which leaves it out of sync.
+// The caller must hold the lock.
+func (c *Cache) fail(err error) error {
+	c.broken = true
+	if closer, ok := c.conn.(io.Closer); ok {
+		closer.Close()
+	}
+	return err
+}
+
+// Close the connection if it is an io.Closer.
+func (c *Cache) Close() error {
+	if closer, ok := c.conn.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Get a value. A miss and an error both return false, see LastError.
+func (c *Cache) Get(key interface{}) (interface{}, bool) {
+	val, err := c.Fetch(key)
+	if err == cache.ErrNotFound {
+		c.setErr(nil)
+		return nil, false
+	}
+	c.setErr(err)
+	return val, err == nil
+}
+
+// Fetch a value, or cache.ErrNotFound if the key is not found.
+func (c *Cache) Fetch(key interface{}) (interface{}, error) {
+	replies, err := c.do([]string{"GET", c.key(key)})
+	if err != nil {
+		return nil, err
+	}
+	return c.decode(replies[0])
+}
+
+// Set a value. If the dur is 0, the default expiration is used, and if it
+// is less than 0 the value never expires. The error is kept for LastError.
+func (c *Cache) Set(key interface{}, val interface{}, dur time.Duration) {
+	c.setErr(c.SetSync(key, val, dur))
+}
+
+// SetSync works like Set, but returns the error.
+func (c *Cache) SetSync(key interface{}, val interface{}, dur time.Duration) error {
+	data, err := c.codec.Marshal(&val)
+	if err != nil {
+		return err
+	}
+	if dur == 0 {
+		dur = c.defaultExpiration
+	}
+	cmd := []string{"SET", c.key(key), string(data)}
+	if dur > 0 {
+		ms := int64(dur / time.Millisecond)
+		if ms < 1 {
+			ms = 1
+		}
+		cmd = append(cmd, "PX", strconv.FormatInt(ms, 10))
+	}
+	_, err = c.do(cmd)
+	return err
+}
+
+// Delete a key. The error is kept for LastError.
+func (c *Cache) Delete(key interface{}) {
+	c.setErr(c.DeleteSync(key))
+}
+
+// DeleteSync works like Delete, but returns the error.
+func (c *Cache) DeleteSync(key interface{}) error {
+	_, err := c.do([]string{"DEL", c.key(key)})
+	return err
+}
+
+// TTL return how long the key lives, which is less than 0 if it never
+// expires, or cache.ErrNotFound if the key is not found.
+func (c *Cache) TTL(key interface{}) (time.Duration, error) {
+	replies, err := c.do([]string{"PTTL", c.key(key)})
+	if err != nil {
+		return 0, err
+	}
+	return pttl(replies[0])
+}
+
+// LastError return the error of the last Get, Set or Delete, or nil if it
+// succeeded.
+func (c *Cache) LastError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastErr
+}
+
+// Store return the Cache as a cache.WritableStore, so a cache.Cache can
+// read and write through it with SetStore. The values loaded are kept in
+// the cache.Cache for their remaining TTL in Redis.
+func (c *Cache) Store() cache.WritableStore {
+	return store{c}
+}
+
+type store struct {
+	c *Cache
+}
+
+func (s store) Load(key interface{}) (interface{}, time.Duration, error) {
+	k := s.c.key(key)
+	replies, err := s.c.do([]string{"GET", k}, []string{"PTTL", k})
+	if err != nil {
+		return nil, 0, err
+	}
+	val, err := s.c.decode(replies[0])
+	if err != nil {
+		return nil, 0, err
+	}
+	ttl, err := pttl(replies[1])
+	if err == cache.ErrNotFound {
+		// The key expired between GET and PTTL, keep the value briefly.
+		ttl, err = time.Millisecond, nil
+	}
+	return val, ttl, err
+}
+
+func (s store) Save(key interface{}, val interface{}, ttl time.Duration) error {
+	return s.c.SetSync(key, val, ttl)
+}
+
+func (s store) Delete(key interface{}) error {
+	return s.c.DeleteSync(key)
+}
+
+func (c *Cache) key(key interface{}) string {
+	return c.prefix + fmt.Sprint(key)
+}
+
+func (c *Cache) setErr(err error) {
+	c.mu.Lock()
+	c.lastErr = err
+	c.mu.Unlock()
+}
+
+func (c *Cache) decode(reply interface{}) (interface{}, error) {
+	data, ok := reply.([]byte)
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	var val interface{}
+	if err := c.codec.Unmarshal(data, &val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// pttl convert a reply of PTTL.
+func pttl(reply interface{}) (time.Duration, error) {
+	ms, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("Unexpected reply %v to PTTL", reply)
+	}
+	switch {
+	case ms == -2:
+		return 0, cache.ErrNotFound
+	case ms < 0:
+		return -1, nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// do send the commands in a pipeline and return their replies. An error
+// reply is returned as an Error.
+func (c *Cache) do(cmds ...[]string) ([]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.reconnect(); err != nil {
+		return nil, err
+	}
+	for _, cmd := range cmds {
+		fmt.Fprintf(c.w, "*%d\r\n", len(cmd))
+		for _, arg := range cmd {
+			fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, c.fail(err)
+	}
+	replies := make([]interface{}, len(cmds))
+	var replyErr error
+	for i := range replies {
+		reply, err := readReply(c.r)
+		if e, ok := err.(Error); ok {
+			// Read the other replies to keep the connection in sync.
+			if replyErr == nil {
+				replyErr = e
+			}
+			continue
+		}
+		if err != nil {
+			return nil, c.fail(err)
+		}
+		replies[i] = reply
+	}
+	return replies, replyErr
+}
+
+// readReply read a reply: a string, an int64, a []byte, nil or an
+// []interface{} of replies. An array holding an error reply is read to its
+// end before the error is returned.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("Malformed reply from the server")
+	}
+	line = line[:len(line)-2]
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		replies := make([]interface{}, n)
+		var replyErr error
+		for i := range replies {
+			replies[i], err = readReply(r)
+			if e, ok := err.(Error); ok {
+				if replyErr == nil {
+					replyErr = e
+				}
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if replyErr != nil {
+			return nil, replyErr
+		}
+		return replies, nil
+	}
+	return nil, fmt.Errorf("Unexpected reply %q from the server", line)
+}
@@ -0,0 +1,261 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+// hub is the channels of the test server, shared by its connections.
+type hub struct {
+	sync.Mutex
+	subs map[string][]net.Conn
+}
+
+var testHub = &hub{subs: map[string][]net.Conn{}}
+
+// serve answer the commands of the tests on conn from an in-memory map.
+func serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	data := map[string]string{}
+	deadlines := map[string]time.Time{}
+	alive := func(k string) bool {
+		if d, ok := deadlines[k]; ok && time.Now().After(d) {
+			delete(data, k)
+			delete(deadlines, k)
+		}
+		_, ok := data[k]
+		return ok
+	}
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		var cmd []string
+		for _, arg := range reply.([]interface{}) {
+			cmd = append(cmd, string(arg.([]byte)))
+		}
+		switch strings.ToUpper(cmd[0]) {
+		case "GET":
+			if !alive(cmd[1]) {
+				fmt.Fprint(conn, "$-1\r\n")
+			} else {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(data[cmd[1]]), data[cmd[1]])
+			}
+		case "SET":
+			data[cmd[1]] = cmd[2]
+			delete(deadlines, cmd[1])
+			if len(cmd) == 5 {
+				ms, _ := strconv.Atoi(cmd[4])
+				deadlines[cmd[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			}
+			fmt.Fprint(conn, "+OK\r\n")
+		case "DEL":
+			n := 0
+			if alive(cmd[1]) {
+				delete(data, cmd[1])
+				n = 1
+			}
+			fmt.Fprintf(conn, ":%d\r\n", n)
+		case "PTTL":
+			switch d, ok := deadlines[cmd[1]]; {
+			case !alive(cmd[1]):
+				fmt.Fprint(conn, ":-2\r\n")
+			case !ok:
+				fmt.Fprint(conn, ":-1\r\n")
+			default:
+				fmt.Fprintf(conn, ":%d\r\n", time.Until(d)/time.Millisecond)
+			}
+		case "SUBSCRIBE":
+			testHub.Lock()
+			testHub.subs[cmd[1]] = append(testHub.subs[cmd[1]], conn)
+			testHub.Unlock()
+			fmt.Fprintf(conn, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(cmd[1]), cmd[1])
+		case "PUBLISH":
+			testHub.Lock()
+			subs := testHub.subs[cmd[1]]
+			for _, sub := range subs {
+				fmt.Fprintf(sub, "*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(cmd[1]), cmd[1], len(cmd[2]), cmd[2])
+			}
+			testHub.Unlock()
+			fmt.Fprintf(conn, ":%d\r\n", len(subs))
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", cmd[0])
+		}
+	}
+}
+
+func newTestCache(defaultExpiration time.Duration) *Cache {
+	client, server := net.Pipe()
+	go serve(server)
+	return New(client, cache.JSONCodec{}, "test:", defaultExpiration)
+}
+
+func TestCache(t *testing.T) {
+	c := newTestCache(time.Hour)
+	defer c.Close()
+	var _ cache.Interface = c
+	c.Set("a", "value", 0)
+	c.Set("b", 1.5, -1)
+	c.Set("c", true, time.Millisecond)
+	if c.LastError() != nil {
+		t.Fatal(c.LastError())
+	}
+	if val, found := c.Get("a"); !found || val != "value" {
+		t.Errorf("You get a wrong value %v", val)
+	}
+	if ttl, err := c.TTL("a"); err != nil || ttl <= 59*time.Minute {
+		t.Errorf("The default expiration must be used, not %v", ttl)
+	}
+	if ttl, err := c.TTL("b"); err != nil || ttl >= 0 {
+		t.Errorf("The value must never expire, not %v", ttl)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, found := c.Get("c"); found {
+		t.Error("Now, the value is expired")
+	}
+	c.Delete("a")
+	if _, err := c.Fetch("a"); err != cache.ErrNotFound {
+		t.Error("Now, the key is deleted")
+	}
+	if _, err := c.do([]string{"NOPE"}); err == nil {
+		t.Error("The error reply must be returned")
+	}
+	if _, found := c.Get("b"); !found {
+		t.Error("The connection must be usable after an error reply")
+	}
+}
+
+func TestStore(t *testing.T) {
+	r := newTestCache(0)
+	defer r.Close()
+	c := cache.New(0, 0)
+	c.SetStore(r.Store())
+	if err := c.SetSync("a", "value", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if val, found := r.Get("a"); !found || val != "value" {
+		t.Error("The value must be written through")
+	}
+	r.Set("b", "loaded", time.Minute)
+	if val, found := c.Get("b"); !found || val != "loaded" {
+		t.Error("The value must be read through")
+	}
+	if info, _ := c.GetItemInfo("b"); info.Expiration == nil || info.Expiration.After(time.Now().Add(time.Minute)) {
+		t.Error("The TTL in Redis must be kept")
+	}
+	if _, found := c.Get("c"); found {
+		t.Error("Impossiable!")
+	}
+}
+
+func TestTiered(t *testing.T) {
+	r := newTestCache(0)
+	defer r.Close()
+	l1 := cache.New(0, 0)
+	tiered := cache.NewTiered(l1, r, time.Second)
+	r.Set("a", "value", 0)
+	if val, found := tiered.Get("a"); !found || val != "value" || l1.ItemCount() != 1 {
+		t.Error("The value of Redis must be promoted")
+	}
+}
+
+func TestPubSub(t *testing.T) {
+	caches := make([]*cache.Cache, 2)
+	buses := make([]*cache.InvalidationBus, 2)
+	for i := range caches {
+		r := newTestCache(0)
+		defer r.Close()
+		sub, server := net.Pipe()
+		go serve(server)
+		caches[i] = cache.New(0, 0)
+		var err error
+		if buses[i], err = cache.NewInvalidationBus(caches[i], r.PubSub(sub, "invalidations")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	caches[1].Set("k", "old", 0)
+	time.Sleep(10 * time.Millisecond)
+	caches[0].Set("k", "new", 0)
+	time.Sleep(10 * time.Millisecond)
+	if _, found := caches[1].Get("k"); found {
+		t.Error("The key set by a peer must be deleted")
+	}
+	if _, found := caches[0].Get("k"); !found {
+		t.Error("The publisher must keep its value")
+	}
+	for _, b := range buses {
+		if err := b.Close(); err != nil || b.LastError() != nil {
+			t.Error(err, b.LastError())
+		}
+	}
+}
+
+func TestReadReplyNestedError(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("*3\r\n:1\r\n-ERR nested\r\n:2\r\n:3\r\n"))
+	if _, err := readReply(r); err != Error("ERR nested") {
+		t.Error("You get a wrong error", err)
+	}
+	if reply, err := readReply(r); err != nil || reply != int64(3) {
+		t.Error("The whole array must be read", reply, err)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		// The first connection answers a malformed integer and a stale
+		// reply, the next ones are served.
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		readReply(bufio.NewReader(conn))
+		fmt.Fprint(conn, ":nope\r\n+OK\r\n")
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+	c, err := Dial(l.Addr().String(), cache.JSONCodec{}, "test:", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.TTL("a"); err == nil {
+		t.Error("The malformed reply must be an error")
+	}
+	c.Set("a", "value", 0)
+	if val, found := c.Get("a"); !found || val != "value" {
+		t.Errorf("The connection must be dialed again: %v %v", val, c.LastError())
+	}
+
+	client, server := net.Pipe()
+	go func() {
+		readReply(bufio.NewReader(server))
+		fmt.Fprint(server, ":nope\r\n+OK\r\n")
+	}()
+	c = New(client, cache.JSONCodec{}, "test:", 0)
+	if _, err := c.TTL("a"); err == nil {
+		t.Error("The malformed reply must be an error")
+	}
+	if _, err := c.TTL("a"); err != errBroken {
+		t.Error("You get a wrong error", err)
+	}
+}
@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// RefreshPolicy configure a Refresher.
+type RefreshPolicy struct {
+	// Loader return the new value of a key.
+	Loader func(key interface{}) (interface{}, error)
+	// Ahead is how long before its expiration an item is refreshed.
+	Ahead time.Duration
+	// Workers is the number of loaders running at once, 1 if less.
+	Workers int
+	// Backoff is the delay before retrying a key whose loader failed. It
+	// doubles at every failure in a row, up to MaxBackoff. It defaults to
+	// Ahead divided by 4.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Refresher refresh the registered keys of a Cache shortly before they
+// expire, with a bounded pool of workers, so hot keys never miss. A key
+// which is missing from the cache is loaded too. Nothing is refreshed while
+// the DisableRefresh of the RefreshFlags of the cache is true.
+type Refresher struct {
+	c      *Cache
+	policy RefreshPolicy
+	jobs   chan interface{}
+	stop   chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	keys map[interface{}]*refreshKey
+}
+
+type refreshKey struct {
+	ttl      time.Duration
+	queued   bool
+	failures uint
+	retryAt  time.Time
+}
+
+// NewRefresher create and start a Refresher of c, checking the registered
+// keys every Ahead divided by 2.
+func NewRefresher(c *Cache, policy RefreshPolicy) (*Refresher, error) {
+	if policy.Loader == nil {
+		return nil, errors.New("The loader must not be nil")
+	}
+	if policy.Ahead < 2 {
+		// The keys are checked every Ahead divided by 2.
+		return nil, errors.New("The refresh ahead duration must greater than 1ns")
+	}
+	if policy.Workers < 1 {
+		policy.Workers = 1
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = policy.Ahead / 4
+	}
+	if policy.MaxBackoff < policy.Backoff {
+		policy.MaxBackoff = policy.Backoff
+	}
+	r := &Refresher{
+		c:      c,
+		policy: policy,
+		jobs:   make(chan interface{}, policy.Workers),
+		stop:   make(chan struct{}),
+		keys:   map[interface{}]*refreshKey{},
+	}
+	r.wg.Add(1 + policy.Workers)
+	go r.run()
+	for i := 0; i < policy.Workers; i++ {
+		go r.work()
+	}
+	return r, nil
+}
+
+// Register a key to refresh, whose new values are set for ttl, like Set.
+func (r *Refresher) Register(key interface{}, ttl time.Duration) {
+	r.mu.Lock()
+	if k, ok := r.keys[key]; ok {
+		k.ttl = ttl
+	} else {
+		r.keys[key] = &refreshKey{ttl: ttl}
+	}
+	r.mu.Unlock()
+}
+
+// Unregister a key, which is not refreshed anymore.
+func (r *Refresher) Unregister(key interface{}) {
+	r.mu.Lock()
+	delete(r.keys, key)
+	r.mu.Unlock()
+}
+
+// Stop the refreshes and wait for the running loaders to finish. It can be
+// called more than once.
+func (r *Refresher) Stop() {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+	r.wg.Wait()
+}
+
+func (r *Refresher) run() {
+	defer r.wg.Done()
+	defer close(r.jobs)
+	ticker := time.NewTicker(r.policy.Ahead / 2)
+	defer ticker.Stop()
+	for {
+		r.check()
+		select {
+		case <-ticker.C:
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// check queue the keys which expire within Ahead, are missing, or are due
+// to be retried. The keys which do not fit the queue wait for the next
+// check.
+func (r *Refresher) check() {
+	r.c.RLock()
+	disabled := r.c.refreshDisabled()
+	r.c.RUnlock()
+	if disabled {
+		return
+	}
+	now := time.Now()
+	r.mu.Lock()
+	var due []interface{}
+	for key, k := range r.keys {
+		if k.queued || now.Before(k.retryAt) {
+			continue
+		}
+		info, found := r.c.GetItemInfo(key)
+		if found && info.State == StateFresh && (info.Expiration == nil || info.Expiration.Sub(now) > r.policy.Ahead) {
+			continue
+		}
+		due = append(due, key)
+	}
+	r.mu.Unlock()
+	// A key is marked queued before it is sent, as a worker may take it
+	// and clear the mark at once.
+	for _, key := range due {
+		r.setQueued(key, true)
+		select {
+		case r.jobs <- key:
+			continue
+		case <-r.stop:
+		default:
+		}
+		r.setQueued(key, false)
+		return
+	}
+}
+
+// setQueued mark a registered key as waiting for a worker or not.
+func (r *Refresher) setQueued(key interface{}, queued bool) {
+	r.mu.Lock()
+	if k, ok := r.keys[key]; ok {
+		k.queued = queued
+	}
+	r.mu.Unlock()
+}
+
+func (r *Refresher) work() {
+	defer r.wg.Done()
+	for key := range r.jobs {
+		r.c.SetRefreshing(key, true)
+		val, err := r.policy.Loader(key)
+		r.mu.Lock()
+		k, ok := r.keys[key]
+		if !ok {
+			r.mu.Unlock()
+			r.c.SetRefreshing(key, false)
+			continue
+		}
+		k.queued = false
+		ttl := k.ttl
+		if err != nil {
+			backoff := r.policy.Backoff << k.failures
+			if backoff > r.policy.MaxBackoff || backoff <= 0 {
+				backoff = r.policy.MaxBackoff
+			} else {
+				k.failures++
+			}
+			k.retryAt = time.Now().Add(backoff)
+		} else {
+			k.failures = 0
+			k.retryAt = time.Time{}
+		}
+		r.mu.Unlock()
+		if err != nil {
+			r.c.SetRefreshing(key, false)
+			continue
+		}
+		r.c.Set(key, val, ttl)
+	}
+}
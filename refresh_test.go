@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRefresher(t *testing.T) {
+	c := New(0, 0)
+	var version int32
+	r, err := NewRefresher(c, RefreshPolicy{
+		Loader: func(key interface{}) (interface{}, error) {
+			return atomic.AddInt32(&version, 1), nil
+		},
+		Ahead:   20 * time.Millisecond,
+		Workers: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+	c.Set("a", int32(0), 30*time.Millisecond)
+	r.Register("a", 30*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		time.Sleep(10 * time.Millisecond)
+		if _, found := c.Get("a"); !found {
+			t.Fatal("A refreshed key must never miss")
+		}
+	}
+	if atomic.LoadInt32(&version) < 2 {
+		t.Error("The key must be refreshed")
+	}
+	if _, err := NewRefresher(c, RefreshPolicy{Ahead: time.Second}); err == nil {
+		t.Error("Impossiable!")
+	}
+	loader := func(key interface{}) (interface{}, error) { return nil, nil }
+	if _, err := NewRefresher(c, RefreshPolicy{Loader: loader, Ahead: 1}); err == nil {
+		t.Error("The ahead duration is too small for the ticker")
+	}
+}
+
+func TestRefresherBackoff(t *testing.T) {
+	c := New(0, 0)
+	var calls int32
+	r, _ := NewRefresher(c, RefreshPolicy{
+		Loader: func(key interface{}) (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			return nil, errors.New("origin down")
+		},
+		Ahead:      10 * time.Millisecond,
+		Backoff:    40 * time.Millisecond,
+		MaxBackoff: time.Second,
+	})
+	r.Register("a", time.Minute)
+	time.Sleep(100 * time.Millisecond)
+	r.Stop()
+	r.Stop()
+	if n := atomic.LoadInt32(&calls); n < 1 || n > 3 {
+		t.Errorf("The loader is called %d times", n)
+	}
+}
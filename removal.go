@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"sync"
+)
+
+type removalSubscriber struct {
+	f func(Event)
+}
+
+// SubscribeRemovals register f to be called with an EventExpire or an
+// EventEvict event for every item removed by the cleanup or evicted, and
+// return a function to cancel the subscription. Unlike OnEvicted, any
+// number of independent subscribers, like the metrics, the logs and the
+// re-warm logic, can observe the removals. f is called without the lock
+// held, after the eviction callback, by the goroutine which removed the
+// item. A removal may still be given to f right after the cancellation.
+// Nothing is given in the degraded mode, see SetDegraded.
+func (c *Cache) SubscribeRemovals(f func(Event)) (cancel func()) {
+	sub := &removalSubscriber{f}
+	c.Lock()
+	c.removalSubs = append(c.removalSubs, sub)
+	c.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.Lock()
+			defer c.Unlock()
+			for i, other := range c.removalSubs {
+				if other == sub {
+					// The notifying goroutines may still read the old
+					// slice, so it is copied.
+					c.removalSubs = append(c.removalSubs[:i:i], c.removalSubs[i+1:]...)
+					return
+				}
+			}
+		})
+	}
+}
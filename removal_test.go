@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscribeRemovals(t *testing.T) {
+	c := New(0, 0)
+	c.SetMaxItems(1, 0, EvictNearestExpiry)
+	var mu sync.Mutex
+	var a, b []Event
+	cancelA := c.SubscribeRemovals(func(ev Event) {
+		mu.Lock()
+		a = append(a, ev)
+		mu.Unlock()
+	})
+	cancelB := c.SubscribeRemovals(func(ev Event) {
+		mu.Lock()
+		b = append(b, ev)
+		mu.Unlock()
+	})
+	c.Set("a", 1, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	c.DeleteExpired()
+	c.Set("b", 2, 0)
+	c.Set("c", 3, 0)
+	c.Delete("c")
+	if len(a) != 2 || len(b) != 2 {
+		t.Fatal("Every subscriber must get the expiration and the eviction", a, b)
+	}
+	if a[0].Type != EventExpire || a[0].Key != "a" || a[1].Type != EventEvict || a[1].Key != "b" {
+		t.Errorf("You get wrong events %+v", a)
+	}
+	cancelA()
+	c.Set("d", 4, 0)
+	c.Set("e", 5, 0)
+	if len(a) != 2 || len(b) != 3 {
+		t.Error("A cancelled subscriber must not get the removals")
+	}
+	cancelB()
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// RequestCache is a lightweight cache living as long as a request. It has
+// no lock and no janitor, so it must only be used by the goroutine handling
+// the request, and it stops returning anything once its context is done.
+// Entries worth keeping can be copied to a long-lived Cache by PromoteTo.
+type RequestCache struct {
+	ctx   context.Context
+	items map[interface{}]requestItem
+}
+
+type requestItem struct {
+	val interface{}
+	dur time.Duration
+	// expiration is zero if the item does not expire.
+	expiration time.Time
+}
+
+type requestCacheKey struct{}
+
+// NewRequestCache create a RequestCache bound to ctx.
+func NewRequestCache(ctx context.Context) *RequestCache {
+	return &RequestCache{
+		ctx:   ctx,
+		items: map[interface{}]requestItem{},
+	}
+}
+
+// WithRequestCache return a copy of ctx carrying a new RequestCache bound to
+// it, which FromContext returns.
+func WithRequestCache(ctx context.Context) (context.Context, *RequestCache) {
+	c := NewRequestCache(ctx)
+	return context.WithValue(ctx, requestCacheKey{}, c), c
+}
+
+// FromContext return the RequestCache carried by ctx, if any.
+func FromContext(ctx context.Context) (*RequestCache, bool) {
+	c, ok := ctx.Value(requestCacheKey{}).(*RequestCache)
+	return c, ok
+}
+
+// Get return an item or nil, and a bool indicating whether the key was
+// found. Nothing is found once the context is done.
+func (c *RequestCache) Get(key interface{}) (interface{}, bool) {
+	if c.ctx.Err() != nil {
+		return nil, false
+	}
+	item, ok := c.items[key]
+	if !ok || (!item.expiration.IsZero() && item.expiration.Before(time.Now())) {
+		return nil, false
+	}
+	return item.val, true
+}
+
+// Set add a new key or replace an exist key. The dur is only enforced if it
+// is greater than 0, and is kept for PromoteTo. Set does nothing once the
+// context is done.
+func (c *RequestCache) Set(key interface{}, val interface{}, dur time.Duration) {
+	if c.ctx.Err() != nil {
+		return
+	}
+	item := requestItem{val: val, dur: dur}
+	if dur > 0 {
+		item.expiration = time.Now().Add(dur)
+	}
+	c.items[key] = item
+}
+
+// Delete a key-value pair if the key is existed.
+func (c *RequestCache) Delete(key interface{}) {
+	delete(c.items, key)
+}
+
+// Return the number of item in cache.
+func (c *RequestCache) ItemCount() int {
+	return len(c.items)
+}
+
+// PromoteTo copy the items of keys, or all the items if no key is given,
+// to the parent cache with what is left of their duration. Items with a
+// dur of 0 get the default expiration of the parent. Expired items are
+// skipped.
+func (c *RequestCache) PromoteTo(parent *Cache, keys ...interface{}) {
+	now := time.Now()
+	promote := func(key interface{}, item requestItem) {
+		dur := item.dur
+		if dur > 0 {
+			if dur = item.expiration.Sub(now); dur <= 0 {
+				return
+			}
+		}
+		parent.Set(key, item.val, dur)
+	}
+	if len(keys) == 0 {
+		for k, item := range c.items {
+			promote(k, item)
+		}
+		return
+	}
+	for _, k := range keys {
+		if item, ok := c.items[k]; ok {
+			promote(k, item)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRequestCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, rc := WithRequestCache(ctx)
+	if c, ok := FromContext(ctx); !ok || c != rc {
+		t.Error("The context must carry the request cache")
+	}
+	rc.Set("a", 1, 0)
+	rc.Set("b", 2, time.Hour)
+	rc.Set("c", 3, -1)
+	if val, found := rc.Get("a"); !found || val != 1 {
+		t.Error("You must get this value")
+	}
+	rc.Delete("c")
+	if rc.ItemCount() != 2 {
+		t.Error("The number of cache must be 2")
+	}
+
+	parent := New(time.Minute, 0)
+	rc.PromoteTo(parent, "b")
+	if _, found := parent.Get("a"); found {
+		t.Error("Only the selected key must be promoted")
+	}
+	rc.PromoteTo(parent)
+	if val, found := parent.Get("a"); !found || val != 1 {
+		t.Error("All keys must be promoted")
+	}
+	parent.RLock()
+	exp := parent.items["b"].Expiration
+	parent.RUnlock()
+	if exp == nil || exp.Before(time.Now().Add(59*time.Minute)) {
+		t.Error("The remaining duration must be kept")
+	}
+
+	cancel()
+	if _, found := rc.Get("a"); found {
+		t.Error("The request is done, you should not get")
+	}
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("There is no request cache in this context")
+	}
+}
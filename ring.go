@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Ring route the keys to shards by consistent hashing: every shard owns
+// the keys hashed near its points on a ring, so changing the number of
+// shards only moves the keys of the points added or removed, about 1/n of
+// them, instead of almost all of them with a modulo.
+//
+// After Resize, PreviousShard gives the shard of a key before, so a
+// sharded cache can look a missed key up there and move it, rehashing the
+// keys on their first miss instead of flushing them.
+type Ring struct {
+	mu       sync.RWMutex
+	hasher   Hasher
+	replicas int
+	shards   int
+	points   []ringPoint
+	previous []ringPoint
+}
+
+type ringPoint struct {
+	hash  uint64
+	shard int
+}
+
+// NewRing create a Ring of shards shards, each with replicas points on the
+// ring; more points spread the keys more evenly. The keys are hashed by
+// hasher, or DefaultHasher if it is nil.
+func NewRing(shards int, replicas int, hasher Hasher) (*Ring, error) {
+	if replicas <= 0 {
+		return nil, errors.New("The replicas of a ring must greater than 0")
+	}
+	if hasher == nil {
+		hasher = DefaultHasher
+	}
+	r := &Ring{hasher: hasher, replicas: replicas}
+	if err := r.Resize(shards); err != nil {
+		return nil, err
+	}
+	r.previous = r.points
+	return r, nil
+}
+
+// Resize change the number of shards. The points of a shard do not depend
+// on the number of shards, so growing from n to n+1 shards moves about
+// 1/(n+1) of the keys, all to the new shard.
+func (r *Ring) Resize(shards int) error {
+	if shards <= 0 {
+		return errors.New("The shards of a ring must greater than 0")
+	}
+	points := make([]ringPoint, 0, shards*r.replicas)
+	for s := 0; s < shards; s++ {
+		for i := 0; i < r.replicas; i++ {
+			points = append(points, ringPoint{mix64(uint64(s)<<32 | uint64(i)), s})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].hash < points[j].hash
+	})
+	r.mu.Lock()
+	r.previous, r.points, r.shards = r.points, points, shards
+	r.mu.Unlock()
+	return nil
+}
+
+// Shards return the number of shards.
+func (r *Ring) Shards() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.shards
+}
+
+// Shard return the shard of the key, from 0 to Shards()-1.
+func (r *Ring) Shard(key interface{}) int {
+	h := r.hasher.Hash(key)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return locate(r.points, h)
+}
+
+// PreviousShard return the shard of the key before the last Resize, which
+// may be out of the current shards if it shrank the ring.
+func (r *Ring) PreviousShard(key interface{}) int {
+	h := r.hasher.Hash(key)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return locate(r.previous, h)
+}
+
+// locate return the shard of the first point at or after h, wrapping
+// around the ring.
+func locate(points []ringPoint, h uint64) int {
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].hash >= h
+	})
+	if i == len(points) {
+		i = 0
+	}
+	return points[i].shard
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	if _, err := NewRing(0, 10, nil); err == nil {
+		t.Error("Impossiable!")
+	}
+	if _, err := NewRing(4, 0, nil); err == nil {
+		t.Error("Impossiable!")
+	}
+	r, err := NewRing(4, 100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make([]int, 4)
+	before := map[int]int{}
+	for i := 0; i < 10000; i++ {
+		s := r.Shard(i)
+		if s != r.PreviousShard(i) {
+			t.Fatal("The ring was not resized")
+		}
+		counts[s]++
+		before[i] = s
+	}
+	for s, n := range counts {
+		if n < 1500 || n > 3500 {
+			t.Error("The keys must be spread over the shards", s, n)
+		}
+	}
+	r.Resize(5)
+	if r.Shards() != 5 {
+		t.Error("You get a wrong number of shards", r.Shards())
+	}
+	moved := 0
+	for i := 0; i < 10000; i++ {
+		s := r.Shard(i)
+		if r.PreviousShard(i) != before[i] {
+			t.Fatal("You get a wrong previous shard")
+		}
+		if s != before[i] {
+			moved++
+			if s != 4 {
+				t.Fatal("The keys must only move to the new shard")
+			}
+		}
+	}
+	if moved < 1000 || moved > 3000 {
+		t.Error("About 1/5 of the keys must move", moved)
+	}
+}
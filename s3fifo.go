@@ -0,0 +1,213 @@
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// S3FIFOCache is a goroutine-safe cache using the S3-FIFO eviction
+// algorithm. New entries go to a small FIFO queue, entries accessed while in
+// it move to a main FIFO queue, and the keys evicted from the small queue are
+// remembered by a ghost queue so they go straight to the main queue if added
+// again. A hit only bumps a small access counter, so Get runs under a read
+// lock.
+type S3FIFOCache struct {
+	sync.RWMutex
+	maxEntries int
+	items      map[interface{}]*list.Element
+	small      *list.List
+	main       *list.List
+	ghosts     map[interface{}]*list.Element
+	ghost      *list.List
+}
+
+type s3fifoEntry struct {
+	key   interface{}
+	value interface{}
+	freq  int32
+	main  bool
+}
+
+// s3fifoMaxFreq is the cap of the access counter of an entry.
+const s3fifoMaxFreq = 3
+
+// NewS3FIFO create a S3FIFOCache with max size. The size must be greater
+// than 0.
+func NewS3FIFO(size int) (*S3FIFOCache, error) {
+	if size <= 0 {
+		return nil, errors.New("The size of S3-FIFO Cache must greater than 0")
+	}
+	c := &S3FIFOCache{maxEntries: size}
+	c.reset()
+	return c, nil
+}
+
+// Add a new key-value pair to the S3FIFOCache.
+func (c *S3FIFOCache) Add(key interface{}, value interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if ele, hit := c.items[key]; hit {
+		ent := ele.Value.(*s3fifoEntry)
+		ent.value = value
+		c.touch(ent)
+		return
+	}
+	for len(c.items) >= c.maxEntries {
+		c.evict()
+	}
+	ent := &s3fifoEntry{key: key, value: value}
+	if ele, hit := c.ghosts[key]; hit {
+		c.ghost.Remove(ele)
+		delete(c.ghosts, key)
+		ent.main = true
+		c.items[key] = c.main.PushFront(ent)
+		return
+	}
+	c.items[key] = c.small.PushFront(ent)
+}
+
+// Get a value from the S3FIFOCache. And a bool indicating
+// whether found or not.
+func (c *S3FIFOCache) Get(key interface{}) (interface{}, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	if ele, hit := c.items[key]; hit {
+		ent := ele.Value.(*s3fifoEntry)
+		c.touch(ent)
+		return ent.value, true
+	}
+	return nil, false
+}
+
+// Remove a key-value pair in S3FIFOCache. If the key is not existed,
+// nothing will happen.
+func (c *S3FIFOCache) Remove(key interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if ele, hit := c.items[key]; hit {
+		c.removeElement(ele)
+	}
+	if ele, hit := c.ghosts[key]; hit {
+		c.ghost.Remove(ele)
+		delete(c.ghosts, key)
+	}
+}
+
+// Return the number of key-value pair in S3FIFOCache.
+func (c *S3FIFOCache) Len() int {
+	c.RLock()
+	length := len(c.items)
+	c.RUnlock()
+	return length
+}
+
+// Delete all entry in the S3FIFOCache. But the max size will hold.
+func (c *S3FIFOCache) Clear() {
+	c.Lock()
+	c.reset()
+	c.Unlock()
+}
+
+// Resize the max limit. The limit must be greater than 0.
+func (c *S3FIFOCache) SetMaxEntries(max int) error {
+	if max <= 0 {
+		return errors.New("The max limit of entryies must greater than 0")
+	}
+	c.Lock()
+	c.maxEntries = max
+	for len(c.items) > max {
+		c.evict()
+	}
+	c.trimGhost()
+	c.Unlock()
+	return nil
+}
+
+func (c *S3FIFOCache) reset() {
+	c.items = make(map[interface{}]*list.Element, c.maxEntries)
+	c.small = list.New()
+	c.main = list.New()
+	c.ghosts = map[interface{}]*list.Element{}
+	c.ghost = list.New()
+}
+
+// smallSize is 10% of the max size, and at least 1.
+func (c *S3FIFOCache) smallSize() int {
+	if size := c.maxEntries / 10; size > 0 {
+		return size
+	}
+	return 1
+}
+
+func (c *S3FIFOCache) touch(ent *s3fifoEntry) {
+	for {
+		freq := atomic.LoadInt32(&ent.freq)
+		if freq >= s3fifoMaxFreq || atomic.CompareAndSwapInt32(&ent.freq, freq, freq+1) {
+			return
+		}
+	}
+}
+
+func (c *S3FIFOCache) evict() {
+	if c.small.Len() >= c.smallSize() || c.main.Len() == 0 {
+		c.evictSmall()
+	} else {
+		c.evictMain()
+	}
+}
+
+// evictSmall move the entries accessed more than once from the tail of the
+// small queue to the main queue, until one entry can be evicted to the ghost
+// queue.
+func (c *S3FIFOCache) evictSmall() {
+	for ele := c.small.Back(); ele != nil; ele = c.small.Back() {
+		ent := ele.Value.(*s3fifoEntry)
+		c.small.Remove(ele)
+		if atomic.LoadInt32(&ent.freq) > 1 {
+			ent.main = true
+			atomic.StoreInt32(&ent.freq, 0)
+			c.items[ent.key] = c.main.PushFront(ent)
+			continue
+		}
+		delete(c.items, ent.key)
+		c.ghosts[ent.key] = c.ghost.PushFront(ent.key)
+		c.trimGhost()
+		return
+	}
+	c.evictMain()
+}
+
+// evictMain reinsert the entries accessed since they were last seen at the
+// tail of the main queue, until one entry can be evicted.
+func (c *S3FIFOCache) evictMain() {
+	for ele := c.main.Back(); ele != nil; ele = c.main.Back() {
+		ent := ele.Value.(*s3fifoEntry)
+		if freq := atomic.LoadInt32(&ent.freq); freq > 0 {
+			atomic.StoreInt32(&ent.freq, freq-1)
+			c.main.MoveToFront(ele)
+			continue
+		}
+		c.removeElement(ele)
+		return
+	}
+}
+
+func (c *S3FIFOCache) trimGhost() {
+	for c.ghost.Len() > c.maxEntries {
+		ele := c.ghost.Back()
+		c.ghost.Remove(ele)
+		delete(c.ghosts, ele.Value)
+	}
+}
+
+func (c *S3FIFOCache) removeElement(ele *list.Element) {
+	ent := ele.Value.(*s3fifoEntry)
+	if ent.main {
+		c.main.Remove(ele)
+	} else {
+		c.small.Remove(ele)
+	}
+	delete(c.items, ent.key)
+}
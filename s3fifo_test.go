@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestS3FIFOCache(t *testing.T) {
+	if _, err := NewS3FIFO(0); err == nil {
+		t.Error("Impossiable!")
+	}
+	var c BoundedCache
+	c, err := NewS3FIFO(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add("hot", 1)
+	c.Get("hot")
+	c.Get("hot")
+	// A scan of keys accessed only once must not flush the hot key.
+	for i := 0; i < 100; i++ {
+		c.Add(i, i)
+	}
+	if c.Len() != 10 {
+		t.Error("Now, the len of S3-FIFO cache must be 10")
+	}
+	if val, hit := c.Get("hot"); !hit || val != 1 {
+		t.Error("The frequently used value must be kept")
+	}
+	c.Remove("hot")
+	if _, hit := c.Get("hot"); hit {
+		t.Error("The value must be removed")
+	}
+	c.SetMaxEntries(5)
+	if c.Len() != 5 {
+		t.Error("The S3-FIFO cache must be shrunk to the new limit")
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Error("Now, the S3-FIFO cache is cleared")
+	}
+}
+
+func TestS3FIFOGhost(t *testing.T) {
+	c, _ := NewS3FIFO(10)
+	for i := 0; i < 11; i++ {
+		c.Add(i, i)
+	}
+	if _, hit := c.ghosts[0]; !hit {
+		t.Error("The evicted key must be remembered by the ghost queue")
+	}
+	c.Add(0, 0)
+	if ele, hit := c.items[0]; !hit || !ele.Value.(*s3fifoEntry).main {
+		t.Error("A ghost hit must go to the main queue")
+	}
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// EntrySample describe an entry picked by SampleEntries.
+type EntrySample struct {
+	Key        interface{}
+	TypeName   string
+	Size       int64
+	Expiration *time.Time
+}
+
+// SampleEntries return up to n entries picked at random from the cache, with
+// the type name of their values and an estimation of the memory used by the
+// key and the value. It is meant to find out what fills a large cache
+// without taking a heap dump.
+func (c *Cache) SampleEntries(n int) []EntrySample {
+	if n <= 0 {
+		return nil
+	}
+	picked := make([]interface{}, 0, n)
+	c.RLock()
+	seen := 0
+	for k := range c.items {
+		if len(picked) < n {
+			picked = append(picked, k)
+		} else if j := rand.Intn(seen + 1); j < n {
+			picked[j] = k
+		}
+		seen++
+	}
+	samples := make([]EntrySample, 0, len(picked))
+	for _, k := range picked {
+		item := c.items[k]
+		samples = append(samples, EntrySample{
+			Key:        k,
+			TypeName:   fmt.Sprintf("%T", item.Object),
+			Size:       sizeOf(k) + sizeOf(item.Object),
+			Expiration: item.Expiration,
+		})
+	}
+	c.RUnlock()
+	return samples
+}
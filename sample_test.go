@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestSampleEntries(t *testing.T) {
+	c := New(0, 0)
+	for i := 0; i < 100; i++ {
+		c.Set(i, make([]byte, 1024), 0)
+	}
+	samples := c.SampleEntries(10)
+	if len(samples) != 10 {
+		t.Fatal("You must get 10 samples")
+	}
+	for _, s := range samples {
+		if s.TypeName != "[]uint8" {
+			t.Error("You get a wrong type name", s.TypeName)
+		}
+		if s.Size < 1024 {
+			t.Error("The size must include the value", s.Size)
+		}
+	}
+	if len(c.SampleEntries(1000)) != 100 {
+		t.Error("You can not get more samples than entries")
+	}
+	c = New(0, 0)
+	c.Set("a", sizedValue(1<<20), 0)
+	if s := c.SampleEntries(1); len(s) != 1 || s[0].Size != 1<<20+sizeOf("a") {
+		t.Error("The Size of a Sizer must be used", s)
+	}
+}
+
+func TestEstimateSize(t *testing.T) {
+	if estimateSize(nil) != 0 {
+		t.Error("The size of nil must be 0")
+	}
+	s := "hello"
+	if estimateSize(s) != int64(len(s))+int64(unsafe.Sizeof(s)) {
+		t.Error("You get a wrong size of string", estimateSize(s))
+	}
+	shared := make([]byte, 100)
+	if estimateSize([][]byte{shared, shared}) > 200 {
+		t.Error("Shared memory must be counted once")
+	}
+	b := make([]byte, 1000, 2000)
+	if estimateSize(b) != 2000+int64(unsafe.Sizeof(b)) {
+		t.Error("You get a wrong size of []byte", estimateSize(b))
+	}
+	ps := []struct {
+		n int
+		s string
+	}{{1, "hello"}}
+	if estimateSize(ps) != int64(unsafe.Sizeof(ps[0]))+5+int64(unsafe.Sizeof(ps)) {
+		t.Error("The strings of the elements must be counted", estimateSize(ps))
+	}
+}
+
+func TestCacheEstimateSize(t *testing.T) {
+	c := New(0, 0)
+	if c.EstimateSize() != 0 {
+		t.Error("The empty cache must use nothing")
+	}
+	c.Set("a", strings.Repeat("x", 1000), 0)
+	one := c.EstimateSize()
+	if one < 1000 || one > 2000 {
+		t.Error("You get a wrong size", one)
+	}
+	c.Set("b", sizedValue(1<<20), 0)
+	if size := c.EstimateSize(); size < one+1<<20 {
+		t.Error("The Size of a Sizer must be used", size)
+	}
+	c.Delete("a")
+	c.Delete("b")
+	if c.EstimateSize() != 0 {
+		t.Error("Now, the cache is empty")
+	}
+}
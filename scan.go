@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// scanCount is the number of keys of a Scan whose count is less than 1.
+const scanCount = 10
+
+// Scan return up to about count keys of the cache from cursor, and the
+// cursor of the next call, 0 when the scan is done. Start with a cursor
+// of 0. Like the SCAN of Redis, the keys in the cache during the whole
+// scan are returned once, however the cache is modified between the calls,
+// while the others may be returned or not. The keys are ordered by hash,
+// and the expired ones are skipped. Every call scans all the keys with the
+// read lock held, but only allocates the page.
+func (c *Cache) Scan(cursor uint64, count int) ([]interface{}, uint64) {
+	c.RLock()
+	defer c.RUnlock()
+	return scanKeys(cursor, count, func(f func(key interface{})) {
+		for k, v := range c.items {
+			if !c.expired(v) && c.access(k, OpGet) == nil {
+				f(k)
+			}
+		}
+	})
+}
+
+// Scan return up to about count keys of the LRUCache from cursor, like
+// Cache.Scan. It does not change the order of the entries.
+func (c *LRUCache) Scan(cursor uint64, count int) ([]interface{}, uint64) {
+	c.RLock()
+	defer c.RUnlock()
+	return scanKeys(cursor, count, func(f func(key interface{})) {
+		for k := range c.items {
+			f(k)
+		}
+	})
+}
+
+type hashedKey struct {
+	hash uint64
+	key  interface{}
+}
+
+// hashedKeys is a max-heap of keys by hash.
+type hashedKeys []hashedKey
+
+func (h hashedKeys) Len() int            { return len(h) }
+func (h hashedKeys) Less(i, j int) bool  { return h[i].hash > h[j].hash }
+func (h hashedKeys) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *hashedKeys) Push(x interface{}) { *h = append(*h, x.(hashedKey)) }
+func (h *hashedKeys) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// scanKeys return the count keys given by each with the lowest hashes from
+// cursor, and the next cursor. The keys with the same hash as the last one
+// are all returned, so none is skipped by the next call.
+func scanKeys(cursor uint64, count int, each func(func(key interface{}))) ([]interface{}, uint64) {
+	if count < 1 {
+		count = scanCount
+	}
+	page := make(hashedKeys, 0, count)
+	each(func(key interface{}) {
+		h := hashKey(key)
+		switch {
+		case h < cursor:
+		case len(page) < count:
+			heap.Push(&page, hashedKey{h, key})
+		case h < page[0].hash:
+			page[0] = hashedKey{h, key}
+			heap.Fix(&page, 0)
+		}
+	})
+	if len(page) == 0 {
+		return nil, 0
+	}
+	last := page[0].hash
+	full := len(page) == count
+	if full {
+		// The other keys of the same hash were left out of the heap.
+		seen := map[interface{}]bool{}
+		for _, hk := range page {
+			if hk.hash == last {
+				seen[hk.key] = true
+			}
+		}
+		each(func(key interface{}) {
+			if !seen[key] && hashKey(key) == last {
+				page = append(page, hashedKey{last, key})
+			}
+		})
+	}
+	sort.Slice(page, func(i, j int) bool {
+		return page[i].hash < page[j].hash
+	})
+	keys := make([]interface{}, len(page))
+	for i, hk := range page {
+		keys[i] = hk.key
+	}
+	if !full {
+		return keys, 0
+	}
+	// The next cursor wraps to 0 if the last hash is the max, as there is
+	// nothing after it.
+	return keys, last + 1
+}
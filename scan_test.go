@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScan(t *testing.T) {
+	c := New(0, 0)
+	for i := 0; i < 100; i++ {
+		c.Set(i, i, 0)
+	}
+	c.Set("expired", 1, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	seen := map[interface{}]int{}
+	cursor, pages := uint64(0), 0
+	for {
+		keys, next := c.Scan(cursor, 7)
+		if len(keys) > 7 {
+			t.Error("You get too many keys", len(keys))
+		}
+		for _, k := range keys {
+			seen[k]++
+		}
+		// The cache is modified during the scan.
+		c.Delete(pages)
+		c.Set(1000+pages, 0, 0)
+		pages++
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	for i := 0; i < 100; i++ {
+		if seen[i] > 1 || seen[i] == 0 && i >= pages {
+			t.Error("The keys must be returned once", i, seen[i])
+		}
+	}
+	if seen["expired"] != 0 {
+		t.Error("The expired keys must be skipped")
+	}
+}
+
+func TestScanCollision(t *testing.T) {
+	c := New(0, 0)
+	// int and int64 keys of the same value have the same hash.
+	for i := 0; i < 10; i++ {
+		c.Set(i, i, 0)
+		c.Set(int64(i), i, 0)
+	}
+	n := 0
+	for cursor := uint64(0); ; {
+		keys, next := c.Scan(cursor, 1)
+		if len(keys) != 2 && next != 0 {
+			t.Error("The keys of the same hash must be returned together", keys)
+		}
+		n += len(keys)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	if n != 20 {
+		t.Error("The colliding keys must not be skipped", n)
+	}
+}
+
+func TestLRUScan(t *testing.T) {
+	lru, _ := NewLRU(0)
+	for i := 0; i < 25; i++ {
+		lru.Add(i, i)
+	}
+	n := 0
+	for cursor := uint64(0); ; {
+		keys, next := lru.Scan(cursor, 0)
+		n += len(keys)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	if n != 25 {
+		t.Error("You get a wrong number of keys", n)
+	}
+}
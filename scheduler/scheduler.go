@@ -0,0 +1,228 @@
+// Package scheduler runs delayed tasks on top of the expiration of a cache:
+// a task is an item which expires at its deadline, and the expiration is
+// delivered to a handler.
+package scheduler
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+// Handler is called with the id and payload of a task once its deadline
+// passed. If it returns an error, the task is retried later.
+type Handler func(id string, payload interface{}) error
+
+// Scheduler is a goroutine-safe delayed task scheduler. Tasks are delivered
+// at least once while it runs: a task is only forgotten once its handler
+// succeeded, and a task whose handler fails is scheduled again after the
+// retry delay, until the handler succeeds or the task is canceled. The
+// tasks are kept in memory only: to keep them across a restart, Stop the
+// scheduler, Save its tasks, and Load them in the next one. The tasks are
+// lost if the process crashes.
+type Scheduler struct {
+	c          *cache.Cache
+	handler    Handler
+	precision  time.Duration
+	retryDelay time.Duration
+	cancel     func()
+	stop       chan struct{}
+	stopOnce   sync.Once
+	wg         sync.WaitGroup
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	expired []savedTask
+	// due are the tasks past their deadline, in deadline order, whose
+	// handler did not succeed yet. The first one is being handled.
+	due     []savedTask
+	stopped bool
+}
+
+// task is the value of the item of a pending task.
+type task struct {
+	payload interface{}
+	runAt   time.Time
+}
+
+// savedTask is a task written by Save.
+type savedTask struct {
+	ID      string
+	Payload interface{}
+	RunAt   time.Time
+}
+
+// New create and start a Scheduler. Deadlines are checked every precision,
+// and a failed task is run again retryDelay later.
+func New(handler Handler, precision, retryDelay time.Duration) (*Scheduler, error) {
+	if handler == nil {
+		return nil, errors.New("The handler must not be nil")
+	}
+	if precision <= 0 {
+		return nil, errors.New("The precision must greater than 0")
+	}
+	s := &Scheduler{
+		c:          cache.New(0, 0),
+		handler:    handler,
+		precision:  precision,
+		retryDelay: retryDelay,
+		stop:       make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	// The expired tasks are recorded by the goroutine which removes them,
+	// before DeleteExpired returns, so none is lost between the cache and
+	// the delivery.
+	s.cancel = s.c.SubscribeRemovals(func(ev cache.Event) {
+		if ev.Type != cache.EventExpire {
+			return
+		}
+		t := ev.Value.(task)
+		s.mu.Lock()
+		s.expired = append(s.expired, savedTask{ev.Key.(string), t.payload, t.runAt})
+		s.mu.Unlock()
+	})
+	s.wg.Add(2)
+	go s.tick()
+	go s.deliver()
+	return s, nil
+}
+
+// Schedule the task id to run at runAt with the payload. A task with the
+// same id which is still pending is replaced. A runAt in the past runs the
+// task at the next check.
+func (s *Scheduler) Schedule(id string, payload interface{}, runAt time.Time) {
+	d := runAt.Sub(time.Now())
+	if d <= 0 {
+		d = time.Nanosecond
+	}
+	s.c.Set(id, task{payload, runAt}, d)
+}
+
+// Cancel a pending task, and return false if there was no such task or its
+// deadline already passed. A task whose deadline passes while it is being
+// canceled may still run.
+func (s *Scheduler) Cancel(id string) bool {
+	if _, ok := s.c.Get(id); !ok {
+		return false
+	}
+	s.c.Delete(id)
+	return true
+}
+
+// Pending return the number of tasks waiting for their deadline or for
+// their handler to succeed.
+func (s *Scheduler) Pending() int {
+	s.mu.Lock()
+	n := len(s.expired) + len(s.due)
+	s.mu.Unlock()
+	return s.c.ItemCount() + n
+}
+
+// Stop the scheduler, waiting for the running handler to return. The
+// pending tasks are not run, but are kept for Save.
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		s.mu.Lock()
+		s.stopped = true
+		s.cond.Broadcast()
+		s.mu.Unlock()
+		s.wg.Wait()
+		s.cancel()
+	})
+}
+
+// Save write the pending tasks to w using gob, the tasks past their
+// deadline included. The concrete types of the payloads are registered
+// with gob, so Load can decode them in a process which registered them too.
+// Call it after Stop to save all the tasks.
+func (s *Scheduler) Save(w io.Writer) (err error) {
+	var tasks []savedTask
+	s.mu.Lock()
+	tasks = append(tasks, s.due...)
+	tasks = append(tasks, s.expired...)
+	s.mu.Unlock()
+	s.c.Range(func(key, value interface{}) bool {
+		t := value.(task)
+		tasks = append(tasks, savedTask{key.(string), t.payload, t.runAt})
+		return true
+	})
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("Error registering payload types with gob: %v", x)
+		}
+	}()
+	for _, t := range tasks {
+		if t.Payload != nil {
+			gob.Register(t.Payload)
+		}
+	}
+	return gob.NewEncoder(w).Encode(tasks)
+}
+
+// Load schedule the tasks written by Save. The tasks past their deadline
+// run at the next check.
+func (s *Scheduler) Load(r io.Reader) error {
+	var tasks []savedTask
+	if err := gob.NewDecoder(r).Decode(&tasks); err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		s.Schedule(t.ID, t.Payload, t.RunAt)
+	}
+	return nil
+}
+
+func (s *Scheduler) tick() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.precision)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.c.DeleteExpired()
+			s.mu.Lock()
+			// The tasks of a check are run in deadline order, after the
+			// tasks of the previous checks.
+			sort.Slice(s.expired, func(i, j int) bool {
+				return s.expired[i].RunAt.Before(s.expired[j].RunAt)
+			})
+			s.due = append(s.due, s.expired...)
+			s.expired = nil
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) deliver() {
+	defer s.wg.Done()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		for len(s.due) == 0 && !s.stopped {
+			s.cond.Wait()
+		}
+		if s.stopped {
+			return
+		}
+		t := s.due[0]
+		s.mu.Unlock()
+		err := s.handler(t.ID, t.Payload)
+		if err != nil {
+			s.Schedule(t.ID, t.Payload, time.Now().Add(s.retryDelay))
+		}
+		s.mu.Lock()
+		// The task is acknowledged once its handler succeeded, or it was
+		// scheduled again.
+		s.due = s.due[1:]
+	}
+}
@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	done := make(chan struct{}, 10)
+	s, err := New(func(id string, payload interface{}) error {
+		mu.Lock()
+		order = append(order, id)
+		mu.Unlock()
+		done <- struct{}{}
+		return nil
+	}, 5*time.Millisecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+	now := time.Now()
+	s.Schedule("second", 2, now.Add(30*time.Millisecond))
+	s.Schedule("first", 1, now.Add(10*time.Millisecond))
+	s.Schedule("canceled", 3, now.Add(20*time.Millisecond))
+	if !s.Cancel("canceled") {
+		t.Error("The task is pending, it must be canceled")
+	}
+	if s.Cancel("unknown") {
+		t.Error("There is no such task")
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("The task is not run")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Error("The tasks must run in deadline order", order)
+	}
+}
+
+func TestSchedulerRetry(t *testing.T) {
+	calls := make(chan int, 10)
+	n := 0
+	s, _ := New(func(id string, payload interface{}) error {
+		n++
+		calls <- n
+		if n < 3 {
+			return errors.New("try again")
+		}
+		return nil
+	}, 5*time.Millisecond, 5*time.Millisecond)
+	defer s.Stop()
+	s.Schedule("task", nil, time.Now())
+	for want := 1; want <= 3; want++ {
+		select {
+		case got := <-calls:
+			if got != want {
+				t.Error("You get a wrong attempt", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("The failed task must be retried")
+		}
+	}
+	if s.Pending() != 0 {
+		t.Error("The task succeeded, nothing is pending")
+	}
+}
+
+func TestSchedulerSaveLoad(t *testing.T) {
+	called := make(chan struct{}, 10)
+	s, _ := New(func(id string, payload interface{}) error {
+		called <- struct{}{}
+		return errors.New("down")
+	}, 5*time.Millisecond, time.Hour)
+	s.Schedule("now", 1, time.Now())
+	s.Schedule("later", 2, time.Now().Add(time.Hour))
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("The task is not run")
+	}
+	s.Stop()
+	if s.Pending() != 2 {
+		t.Error("The failed task must still be pending", s.Pending())
+	}
+	var buf bytes.Buffer
+	if err := s.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(chan interface{}, 10)
+	s2, _ := New(func(id string, payload interface{}) error {
+		got <- payload
+		return nil
+	}, 5*time.Millisecond, 0)
+	defer s2.Stop()
+	if err := s2.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if s2.Pending() != 2 {
+		t.Error("The tasks must be loaded", s2.Pending())
+	}
+	s2.Schedule("now", 1, time.Now())
+	select {
+	case p := <-got:
+		if p != 1 {
+			t.Error("You get a wrong payload", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The loaded task is not run")
+	}
+}
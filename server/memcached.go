@@ -0,0 +1,271 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+// MaxValueSize is the largest value a client can set, like the default item
+// size limit of memcached.
+const MaxValueSize = 1 << 20
+
+// maxRelativeExpiration is the longest exptime taken as a number of
+// seconds, longer ones are a unix time.
+const maxRelativeExpiration = 30 * 24 * 60 * 60
+
+// Value is a value set by a memcached client with non-zero flags. The
+// values set without flags are stored as []byte.
+type Value struct {
+	Flags uint32
+	Data  []byte
+}
+
+// Memcached serve a cache.Cache over the memcached text protocol, with the
+// commands get, set, delete, incr, decr, touch, version and quit. The
+// values set by Go code are returned as their bytes if they are a []byte or
+// a string, and formatted with fmt.Sprint otherwise. The keys are strings.
+type Memcached struct {
+	server
+	c *cache.Cache
+	// incr serializes incr and decr, which read and set the value. The
+	// writes of Go code between them are lost.
+	incr sync.Mutex
+}
+
+// NewMemcached create a Memcached serving c.
+func NewMemcached(c *cache.Cache) *Memcached {
+	s := &Memcached{c: c}
+	s.handle = s.serveConn
+	return s
+}
+
+// Serve the connections accepted by l until Close. It returns nil after
+// Close, or the error of l.
+func (s *Memcached) Serve(l net.Listener) error {
+	return s.serve(l)
+}
+
+// ListenAndServe listen on the TCP address addr and serve it, like Serve.
+func (s *Memcached) ListenAndServe(addr string) error {
+	return s.listenAndServe(addr)
+}
+
+// Close the listeners and the connections, and wait for the commands being
+// run to finish.
+func (s *Memcached) Close() error {
+	return s.close()
+}
+
+func (s *Memcached) serveConn(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		line, err := r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			fmt.Fprint(w, "CLIENT_ERROR line too long\r\n")
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(string(line))
+		if len(fields) == 0 {
+			fmt.Fprint(w, "ERROR\r\n")
+		} else if !s.command(fields, r, w) {
+			w.Flush()
+			return
+		}
+		// Flush once the pipelined commands are answered.
+		if r.Buffered() == 0 {
+			if w.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// command run a command, and return false if the connection must be
+// closed.
+func (s *Memcached) command(f []string, r *bufio.Reader, w *bufio.Writer) bool {
+	if len(f) > 1 && f[0] != "get" && f[len(f)-1] == "noreply" {
+		f = f[:len(f)-1]
+		w = bufio.NewWriter(ioutil.Discard)
+	}
+	switch {
+	case f[0] == "get" && len(f) > 1:
+		for _, key := range f[1:] {
+			if val, found := s.c.Get(key); found {
+				flags, data := encode(val)
+				fmt.Fprintf(w, "VALUE %s %d %d\r\n%s\r\n", key, flags, len(data), data)
+			}
+		}
+		fmt.Fprint(w, "END\r\n")
+	case f[0] == "set" && len(f) == 5:
+		return s.set(f, r, w)
+	case f[0] == "delete" && len(f) == 2:
+		if _, found := s.c.GetItemInfo(f[1]); !found {
+			fmt.Fprint(w, "NOT_FOUND\r\n")
+		} else if err := s.c.DeleteSync(f[1]); err != nil {
+			fmt.Fprintf(w, "SERVER_ERROR %v\r\n", err)
+		} else {
+			fmt.Fprint(w, "DELETED\r\n")
+		}
+	case (f[0] == "incr" || f[0] == "decr") && len(f) == 3:
+		delta, err := strconv.ParseUint(f[2], 10, 64)
+		if err != nil {
+			fmt.Fprint(w, "CLIENT_ERROR invalid numeric delta argument\r\n")
+			break
+		}
+		s.incrDecr(f[1], delta, f[0] == "decr", w)
+	case f[0] == "touch" && len(f) == 3:
+		exptime, err := strconv.ParseInt(f[2], 10, 64)
+		if err != nil {
+			fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+			break
+		}
+		dur, expired := duration(exptime)
+		if expired {
+			if _, found := s.c.GetItemInfo(f[1]); found {
+				s.c.Delete(f[1])
+				fmt.Fprint(w, "TOUCHED\r\n")
+			} else {
+				fmt.Fprint(w, "NOT_FOUND\r\n")
+			}
+		} else if s.c.Touch(f[1], dur) {
+			fmt.Fprint(w, "TOUCHED\r\n")
+		} else {
+			fmt.Fprint(w, "NOT_FOUND\r\n")
+		}
+	case f[0] == "version":
+		fmt.Fprint(w, "VERSION go-cache\r\n")
+	case f[0] == "quit":
+		return false
+	default:
+		fmt.Fprint(w, "ERROR\r\n")
+	}
+	return true
+}
+
+// set run "set <key> <flags> <exptime> <bytes>" and read its data block.
+func (s *Memcached) set(f []string, r *bufio.Reader, w *bufio.Writer) bool {
+	flags, err1 := strconv.ParseUint(f[2], 10, 32)
+	exptime, err2 := strconv.ParseInt(f[3], 10, 64)
+	n, err3 := strconv.Atoi(f[4])
+	if err1 != nil || err2 != nil || err3 != nil || n < 0 || len(f[1]) > 250 {
+		fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+		// The data block can not be skipped without its size.
+		return err3 == nil && n >= 0 && skip(r, n+2)
+	}
+	if n > MaxValueSize {
+		fmt.Fprint(w, "SERVER_ERROR object too large for cache\r\n")
+		return skip(r, n+2)
+	}
+	data := make([]byte, n+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return false
+	}
+	if string(data[n:]) != "\r\n" {
+		fmt.Fprint(w, "CLIENT_ERROR bad data chunk\r\n")
+		return false
+	}
+	var val interface{} = data[:n]
+	if flags != 0 {
+		val = Value{Flags: uint32(flags), Data: data[:n]}
+	}
+	dur, expired := duration(exptime)
+	if expired {
+		s.c.Delete(f[1])
+	} else if err := s.c.SetSync(f[1], val, dur); err != nil {
+		fmt.Fprintf(w, "SERVER_ERROR %v\r\n", err)
+		return true
+	}
+	fmt.Fprint(w, "STORED\r\n")
+	return true
+}
+
+// incrDecr add delta to, or subtract it from, the decimal value of a key.
+func (s *Memcached) incrDecr(key string, delta uint64, decr bool, w *bufio.Writer) {
+	s.incr.Lock()
+	defer s.incr.Unlock()
+	val, found := s.c.Get(key)
+	info, _ := s.c.GetItemInfo(key)
+	if !found {
+		fmt.Fprint(w, "NOT_FOUND\r\n")
+		return
+	}
+	flags, data := encode(val)
+	n, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		fmt.Fprint(w, "CLIENT_ERROR cannot increment or decrement non-numeric value\r\n")
+		return
+	}
+	switch {
+	case !decr:
+		n += delta
+	case delta > n:
+		n = 0
+	default:
+		n -= delta
+	}
+	data = strconv.AppendUint(nil, n, 10)
+	val = data
+	if flags != 0 {
+		val = Value{Flags: flags, Data: data}
+	}
+	dur := time.Duration(-1)
+	if info.Expiration != nil {
+		if dur = time.Until(*info.Expiration); dur <= 0 {
+			fmt.Fprint(w, "NOT_FOUND\r\n")
+			return
+		}
+	}
+	if err := s.c.SetSync(key, val, dur); err != nil {
+		fmt.Fprintf(w, "SERVER_ERROR %v\r\n", err)
+		return
+	}
+	fmt.Fprintf(w, "%d\r\n", n)
+}
+
+// encode return the flags and the data of a value.
+func encode(val interface{}) (uint32, []byte) {
+	switch v := val.(type) {
+	case []byte:
+		return 0, v
+	case string:
+		return 0, []byte(v)
+	case Value:
+		return v.Flags, v.Data
+	}
+	return 0, []byte(fmt.Sprint(val))
+}
+
+// duration convert an exptime of memcached to the dur of Set, and return
+// true if the key is expired already.
+func duration(exptime int64) (time.Duration, bool) {
+	switch {
+	case exptime == 0:
+		return -1, false
+	case exptime < 0:
+		return 0, true
+	case exptime > maxRelativeExpiration:
+		dur := time.Until(time.Unix(exptime, 0))
+		return dur, dur <= 0
+	}
+	return time.Duration(exptime) * time.Second, false
+}
+
+// skip discard n bytes of r, and return false if they can not be read.
+func skip(r *bufio.Reader, n int) bool {
+	_, err := io.CopyN(ioutil.Discard, r, int64(n))
+	return err == nil
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maemual/go-cache"
+	"github.com/maemual/go-cache/memcache"
+)
+
+// session send the commands on a connection to s and return the replies.
+func session(s *Memcached, cmds ...string) string {
+	client, conn := net.Pipe()
+	go func() {
+		s.serveConn(conn)
+		conn.Close()
+	}()
+	defer client.Close()
+	go fmt.Fprint(client, strings.Join(cmds, "")+"quit\r\n")
+	var replies []string
+	r := bufio.NewReader(client)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return strings.Join(replies, "")
+		}
+		replies = append(replies, line)
+	}
+}
+
+func TestMemcached(t *testing.T) {
+	c := cache.New(0, 0)
+	s := NewMemcached(c)
+	replies := session(s,
+		"set a 0 0 5\r\nhello\r\n",
+		"set b 42 0 2\r\nhi\r\n",
+		"get a b c\r\n",
+		"set n 0 0 2\r\n10\r\n",
+		"incr n 5\r\n",
+		"decr n 100\r\n",
+		"incr a 1\r\n",
+		"incr c 1\r\n",
+		"delete a\r\n",
+		"delete a\r\n",
+		"touch b 100\r\n",
+		"set q 0 0 1 noreply\r\nx\r\n",
+		"bogus\r\n",
+	)
+	want := "STORED\r\nSTORED\r\n" +
+		"VALUE a 0 5\r\nhello\r\nVALUE b 42 2\r\nhi\r\nEND\r\n" +
+		"STORED\r\n15\r\n0\r\n" +
+		"CLIENT_ERROR cannot increment or decrement non-numeric value\r\n" +
+		"NOT_FOUND\r\nDELETED\r\nNOT_FOUND\r\nTOUCHED\r\nERROR\r\n"
+	if replies != want {
+		t.Errorf("You get wrong replies:\n%q\nwant:\n%q", replies, want)
+	}
+	if val, _ := c.Get("b"); val.(Value).Flags != 42 {
+		t.Error("The flags must be kept")
+	}
+	if info, _ := c.GetItemInfo("b"); info.Expiration == nil || info.Expiration.After(time.Now().Add(100*time.Second)) {
+		t.Error("The key must be touched")
+	}
+	if _, found := c.Get("q"); !found {
+		t.Error("The command without reply must be run")
+	}
+	c.Set("go", 12, 0)
+	if replies := session(s, "get go\r\n", "set e 0 -1 1\r\nx\r\n", "get e\r\n"); replies != "VALUE go 0 2\r\n12\r\nEND\r\nSTORED\r\nEND\r\n" {
+		t.Errorf("You get wrong replies %q", replies)
+	}
+}
+
+func TestMemcachedServe(t *testing.T) {
+	c := cache.New(0, 0)
+	s := NewMemcached(c)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- s.Serve(l)
+	}()
+	m, err := memcache.Dial(l.Addr().String(), cache.JSONCodec{}, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Set("a", "value", time.Minute)
+	if val, found := m.Get("a"); !found || val != "value" || m.LastError() != nil {
+		t.Errorf("You get a wrong value %v: %v", val, m.LastError())
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	if _, found := m.Get("a"); found || m.LastError() == nil {
+		t.Error("Now, the connection is closed")
+	}
+}
@@ -0,0 +1,302 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+// maxArgs is the largest number of arguments of a RESP command.
+const maxArgs = 1024
+
+// errProtocol is returned for a request which is not RESP.
+var errProtocol = errors.New("Protocol error")
+
+// RESP serve a cache.Cache over the Redis protocol, with the commands GET,
+// SET (with EX or PX), DEL, EXPIRE, TTL, PTTL, INCR, INCRBY, DECR, DECRBY
+// and PING, so redis-cli and the Redis clients can use it. The values are
+// returned like by Memcached, and the keys are strings.
+type RESP struct {
+	server
+	c *cache.Cache
+	// incr serializes the commands which read and set a value. The writes
+	// of Go code between them are lost.
+	incr sync.Mutex
+}
+
+// NewRESP create a RESP serving c.
+func NewRESP(c *cache.Cache) *RESP {
+	s := &RESP{c: c}
+	s.handle = s.serveConn
+	return s
+}
+
+// Serve the connections accepted by l until Close. It returns nil after
+// Close, or the error of l.
+func (s *RESP) Serve(l net.Listener) error {
+	return s.serve(l)
+}
+
+// ListenAndServe listen on the TCP address addr and serve it, like Serve.
+func (s *RESP) ListenAndServe(addr string) error {
+	return s.listenAndServe(addr)
+}
+
+// Close the listeners and the connections, and wait for the commands being
+// run to finish.
+func (s *RESP) Close() error {
+	return s.close()
+}
+
+func (s *RESP) serveConn(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r)
+		if err == errProtocol {
+			fmt.Fprint(w, "-ERR Protocol error\r\n")
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+		if len(args) > 0 && !s.command(args, w) {
+			w.Flush()
+			return
+		}
+		if r.Buffered() == 0 {
+			if w.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// readCommand read an array of bulk strings, or an inline command.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 || n > maxArgs {
+		return nil, errProtocol
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, errProtocol
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > MaxValueSize {
+			return nil, errProtocol
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		args = append(args, string(data[:size]))
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return "", errProtocol
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// respArity is the number of arguments of the commands, including their
+// name, or its opposite for the minimum of a variable number.
+var respArity = map[string]int{
+	"GET": 2, "SET": -3, "DEL": -2, "EXPIRE": 3, "TTL": 2, "PTTL": 2,
+	"INCR": 2, "INCRBY": 3, "DECR": 2, "DECRBY": 3, "PING": -1, "QUIT": 1,
+}
+
+// command run a command, and return false if the connection must be
+// closed.
+func (s *RESP) command(args []string, w *bufio.Writer) bool {
+	name := strings.ToUpper(args[0])
+	n, ok := respArity[name]
+	if !ok {
+		fmt.Fprintf(w, "-ERR unknown command '%s'\r\n", args[0])
+		return true
+	}
+	if (n > 0 && len(args) != n) || (n < 0 && len(args) < -n) {
+		fmt.Fprintf(w, "-ERR wrong number of arguments for '%s' command\r\n", strings.ToLower(name))
+		return true
+	}
+	switch name {
+	case "GET":
+		if val, found := s.c.Get(args[1]); found {
+			_, data := encode(val)
+			writeBulk(w, data)
+		} else {
+			fmt.Fprint(w, "$-1\r\n")
+		}
+	case "SET":
+		s.set(args, w)
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, found := s.info(key); found {
+				s.c.Delete(key)
+				deleted++
+			}
+		}
+		fmt.Fprintf(w, ":%d\r\n", deleted)
+	case "EXPIRE":
+		secs, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			fmt.Fprint(w, "-ERR value is not an integer or out of range\r\n")
+			break
+		}
+		if secs <= 0 {
+			_, found := s.info(args[1])
+			s.c.Delete(args[1])
+			fmt.Fprintf(w, ":%d\r\n", boolInt(found))
+			break
+		}
+		fmt.Fprintf(w, ":%d\r\n", boolInt(s.c.Touch(args[1], time.Duration(secs)*time.Second)))
+	case "TTL", "PTTL":
+		unit := time.Second
+		if name == "PTTL" {
+			unit = time.Millisecond
+		}
+		info, found := s.info(args[1])
+		switch {
+		case !found:
+			fmt.Fprint(w, ":-2\r\n")
+		case info.Expiration == nil:
+			fmt.Fprint(w, ":-1\r\n")
+		default:
+			// Round up like Redis, so a key is not reported as 0 before
+			// it expires.
+			fmt.Fprintf(w, ":%d\r\n", (time.Until(*info.Expiration)+unit-1)/unit)
+		}
+	case "INCR", "DECR", "INCRBY", "DECRBY":
+		delta := int64(1)
+		if len(args) == 3 {
+			var err error
+			if delta, err = strconv.ParseInt(args[2], 10, 64); err != nil {
+				fmt.Fprint(w, "-ERR value is not an integer or out of range\r\n")
+				break
+			}
+		}
+		if name[0] == 'D' {
+			delta = -delta
+		}
+		s.incrBy(args[1], delta, w)
+	case "PING":
+		if len(args) > 1 {
+			writeBulk(w, []byte(args[1]))
+		} else {
+			fmt.Fprint(w, "+PONG\r\n")
+		}
+	case "QUIT":
+		fmt.Fprint(w, "+OK\r\n")
+		return false
+	}
+	return true
+}
+
+// set run "SET key value [EX seconds|PX milliseconds]".
+func (s *RESP) set(args []string, w *bufio.Writer) {
+	dur := time.Duration(-1)
+	for i := 3; i < len(args); i += 2 {
+		opt := strings.ToUpper(args[i])
+		if (opt != "EX" && opt != "PX") || i+1 >= len(args) {
+			fmt.Fprint(w, "-ERR syntax error\r\n")
+			return
+		}
+		n, err := strconv.ParseInt(args[i+1], 10, 64)
+		if err != nil || n <= 0 {
+			fmt.Fprint(w, "-ERR invalid expire time in 'set' command\r\n")
+			return
+		}
+		dur = time.Duration(n) * time.Second
+		if opt == "PX" {
+			dur = time.Duration(n) * time.Millisecond
+		}
+	}
+	if err := s.c.SetSync(args[1], []byte(args[2]), dur); err != nil {
+		fmt.Fprintf(w, "-ERR %v\r\n", err)
+		return
+	}
+	fmt.Fprint(w, "+OK\r\n")
+}
+
+// incrBy add delta to the decimal value of a key, which is 0 if it is not
+// found, keeping its expiration.
+func (s *RESP) incrBy(key string, delta int64, w *bufio.Writer) {
+	s.incr.Lock()
+	defer s.incr.Unlock()
+	var n int64
+	dur := time.Duration(-1)
+	if val, found := s.c.Get(key); found {
+		_, data := encode(val)
+		var err error
+		if n, err = strconv.ParseInt(string(data), 10, 64); err != nil {
+			fmt.Fprint(w, "-ERR value is not an integer or out of range\r\n")
+			return
+		}
+		if info, _ := s.c.GetItemInfo(key); info.Expiration != nil {
+			if dur = time.Until(*info.Expiration); dur <= 0 {
+				n, dur = 0, -1
+			}
+		}
+	}
+	if (delta > 0 && n > n+delta) || (delta < 0 && n < n+delta) {
+		fmt.Fprint(w, "-ERR increment or decrement would overflow\r\n")
+		return
+	}
+	n += delta
+	if err := s.c.SetSync(key, strconv.AppendInt(nil, n, 10), dur); err != nil {
+		fmt.Fprintf(w, "-ERR %v\r\n", err)
+		return
+	}
+	fmt.Fprintf(w, ":%d\r\n", n)
+}
+
+// info return the metadata of a key if it is not expired.
+func (s *RESP) info(key string) (cache.ItemInfo, bool) {
+	info, found := s.c.GetItemInfo(key)
+	if !found || info.State == cache.StateStale || info.State == cache.StateExpired {
+		return info, false
+	}
+	return info, true
+}
+
+func writeBulk(w *bufio.Writer, data []byte) {
+	fmt.Fprintf(w, "$%d\r\n", len(data))
+	w.Write(data)
+	w.WriteString("\r\n")
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
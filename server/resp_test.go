@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maemual/go-cache"
+	"github.com/maemual/go-cache/redis"
+)
+
+// respSession send the inline commands on a connection to s and return the
+// replies.
+func respSession(s *RESP, cmds ...string) string {
+	client, conn := net.Pipe()
+	go func() {
+		s.serveConn(conn)
+		conn.Close()
+	}()
+	defer client.Close()
+	go fmt.Fprint(client, strings.Join(cmds, "\r\n")+"\r\nQUIT\r\n")
+	var replies []string
+	r := bufio.NewReader(client)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return strings.Join(replies, "")
+		}
+		replies = append(replies, line)
+	}
+}
+
+func TestRESP(t *testing.T) {
+	c := cache.New(0, 0)
+	s := NewRESP(c)
+	replies := respSession(s,
+		"PING",
+		"SET a hello",
+		"GET a",
+		"GET b",
+		"TTL a",
+		"SET b 1 EX 100",
+		"TTL b",
+		"EXPIRE a 10",
+		"PTTL c",
+		"INCR n",
+		"INCRBY n 10",
+		"DECR n",
+		"INCR a",
+		"DEL a n c",
+		"SET x 1 EX 0",
+		"NOPE",
+		"GET",
+	)
+	want := "+PONG\r\n+OK\r\n$5\r\nhello\r\n$-1\r\n:-1\r\n+OK\r\n:100\r\n:1\r\n:-2\r\n" +
+		":1\r\n:11\r\n:10\r\n-ERR value is not an integer or out of range\r\n:2\r\n" +
+		"-ERR invalid expire time in 'set' command\r\n-ERR unknown command 'NOPE'\r\n" +
+		"-ERR wrong number of arguments for 'get' command\r\n+OK\r\n"
+	if replies != want {
+		t.Errorf("You get wrong replies:\n%q\nwant:\n%q", replies, want)
+	}
+	if info, _ := c.GetItemInfo("b"); info.Expiration == nil || info.Expiration.After(time.Now().Add(100*time.Second)) {
+		t.Error("The expiration must be set")
+	}
+}
+
+func TestRESPNegativeCount(t *testing.T) {
+	s := NewRESP(cache.New(0, 0))
+	for _, cmd := range []string{"*-1", "*1\r\n$-1"} {
+		if replies := respSession(s, cmd); replies != "-ERR Protocol error\r\n" {
+			t.Errorf("You get wrong replies for %q: %q", cmd, replies)
+		}
+	}
+}
+
+func TestServePanic(t *testing.T) {
+	srv := &server{handle: func(conn net.Conn) {
+		panic("handler failed")
+	}}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- srv.serve(l)
+	}()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Error("The connection must be closed after the panic")
+	}
+	conn.Close()
+	if err := srv.close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRESPServe(t *testing.T) {
+	s := NewRESP(cache.New(0, 0))
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- s.Serve(l)
+	}()
+	r, err := redis.Dial(l.Addr().String(), cache.JSONCodec{}, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Set("a", "value", time.Minute)
+	if val, found := r.Get("a"); !found || val != "value" || r.LastError() != nil {
+		t.Errorf("You get a wrong value %v: %v", val, r.LastError())
+	}
+	if ttl, err := r.TTL("a"); err != nil || ttl <= 59*time.Second || ttl > time.Minute {
+		t.Errorf("You get a wrong TTL %v: %v", ttl, err)
+	}
+	r.Delete("a")
+	if _, found := r.Get("a"); found {
+		t.Error("Now, the key is deleted")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
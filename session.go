@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Session gives read-your-writes consistency over a cache: after a Set or
+// a Delete through the session, its reads of the key skip the entries of a
+// Cache written before, and read the next layer instead, so the session
+// never sees a value older than its own write. For example, if the L1 of a
+// TieredCache refuses a value, see SetMaxValueSize and SetFullPolicy, and
+// keeps the old one, the session reads the value from L2, and promotes it
+// to L1 again. The age of an entry is only known for the layers which are
+// a Cache, the others, like a remote cache, are trusted. A value promoted
+// concurrently with the write may still be older than it. A Session is
+// meant to last a request or a user session: the keys it wrote are kept
+// until it is dropped.
+type Session struct {
+	c Interface
+
+	mu sync.Mutex
+	// writes map the keys written through the session to the time before
+	// their last write, the minimum write time of the entries it reads.
+	writes map[interface{}]time.Time
+}
+
+// NewSession create a Session reading and writing c.
+func NewSession(c Interface) *Session {
+	return &Session{c: c, writes: map[interface{}]time.Time{}}
+}
+
+// Get a value which is not older than the last write of the key through
+// the session.
+func (s *Session) Get(key interface{}) (interface{}, bool) {
+	s.mu.Lock()
+	min, ok := s.writes[key]
+	s.mu.Unlock()
+	if !ok {
+		return s.c.Get(key)
+	}
+	return sessionGet(s.c, key, min)
+}
+
+// Set a value through the session, like Interface.Set.
+func (s *Session) Set(key interface{}, val interface{}, dur time.Duration) {
+	s.written(key)
+	s.c.Set(key, val, dur)
+}
+
+// Delete a key through the session, like Interface.Delete. The entries of
+// the key written before are not read by the session anymore.
+func (s *Session) Delete(key interface{}) {
+	s.written(key)
+	s.c.Delete(key)
+}
+
+// written record a write of the key, before it is done.
+func (s *Session) written(key interface{}) {
+	now := time.Now()
+	s.mu.Lock()
+	s.writes[key] = now
+	s.mu.Unlock()
+}
+
+// sessionGet read the key from c, skipping the entries written before min.
+func sessionGet(c Interface, key interface{}, min time.Time) (interface{}, bool) {
+	switch c := c.(type) {
+	case *TieredCache:
+		if val, found := sessionGet(c.l1, key, min); found {
+			return val, true
+		}
+		val, found := sessionGet(c.l2, key, min)
+		if found {
+			c.l1.Set(key, val, c.l1Dur(0))
+		}
+		return val, found
+	case *Cache:
+		if info, found := c.GetItemInfo(key); found && info.Written.Before(min) {
+			return nil, false
+		}
+	}
+	return c.Get(key)
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	l1, l2 := New(0, 0), New(0, 0)
+	l1.SetMaxValueSize(5, ValueSizeReject)
+	tiered := NewTiered(l1, l2, 0)
+	tiered.Set("k", "old", 0)
+	s := NewSession(tiered)
+	s.Set("k", "too large for L1", 0)
+	if val, _ := tiered.Get("k"); val != "old" {
+		t.Fatal("Impossiable!")
+	}
+	if val, found := s.Get("k"); !found || val != "too large for L1" {
+		t.Error("The session must read its own write", val)
+	}
+	tiered.Set("n", "old", 0)
+	s.Delete("n")
+	if val, found := s.Get("n"); found {
+		t.Error("Impossiable!", val)
+	}
+	l2.Set("n", "new", 0)
+	if val, found := s.Get("n"); !found || val != "new" {
+		t.Error("The entries written after the session must be read", val)
+	}
+	if val, _ := l1.Get("n"); val != "new" {
+		t.Error("The value must be promoted to L1", val)
+	}
+	if val, found := s.Get("other"); found {
+		t.Error("Impossiable!", val)
+	}
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// shardReplicas is the number of points of a shard on the ring of a
+// ShardedCache.
+const shardReplicas = 100
+
+// ShardedCache is a goroutine-safe cache spreading the keys over several
+// Caches, each with its own lock, routed by a Ring. The number of shards
+// can be changed at runtime with Resize, which moves about 1/n of the keys
+// instead of flushing them: a key is moved from its previous shard on its
+// first miss after the Resize.
+type ShardedCache struct {
+	mu                sync.RWMutex
+	ring              *Ring
+	shards            []*Cache
+	previous          []*Cache
+	defaultExpiration time.Duration
+	cleanInterval     time.Duration
+}
+
+// NewSharded create a ShardedCache of shards Caches, each made by New with
+// the default expiration and cleanup interval.
+func NewSharded(shards int, defaultExpiration, cleanInterval time.Duration) (*ShardedCache, error) {
+	ring, err := NewRing(shards, shardReplicas, nil)
+	if err != nil {
+		return nil, err
+	}
+	c := &ShardedCache{
+		ring:              ring,
+		defaultExpiration: defaultExpiration,
+		cleanInterval:     cleanInterval,
+	}
+	for i := 0; i < shards; i++ {
+		c.shards = append(c.shards, New(defaultExpiration, cleanInterval))
+	}
+	c.previous = c.shards
+	return c, nil
+}
+
+// route return the shard of the key, and its shard before the last Resize
+// if it is another one, nil otherwise.
+func (c *ShardedCache) route(key interface{}) (cur, prev *Cache) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cur = c.shards[c.ring.Shard(key)]
+	if p := c.ring.PreviousShard(key); p < len(c.previous) && c.previous[p] != cur {
+		prev = c.previous[p]
+	}
+	return cur, prev
+}
+
+// Get return an item or nil, and a bool indicating whether the key was
+// found. A key missed in its shard is looked up in its shard before the
+// last Resize, and moved if it is found there.
+func (c *ShardedCache) Get(key interface{}) (interface{}, bool) {
+	cur, prev := c.route(key)
+	if val, found := cur.Get(key); found || prev == nil {
+		return val, found
+	}
+	info, found := prev.GetItemInfo(key)
+	if !found || info.State != StateFresh {
+		return nil, false
+	}
+	dur := time.Duration(-1)
+	if info.Expiration != nil {
+		if dur = time.Until(*info.Expiration); dur <= 0 {
+			return nil, false
+		}
+	}
+	if _, loaded := cur.GetOrSet(key, info.Value, dur); !loaded {
+		prev.Delete(key)
+	}
+	return cur.Get(key)
+}
+
+// Set add an item to the shard of the key, replacing any existing item,
+// like Cache.Set.
+func (c *ShardedCache) Set(key interface{}, val interface{}, dur time.Duration) {
+	cur, prev := c.route(key)
+	cur.Set(key, val, dur)
+	if prev != nil {
+		prev.Delete(key)
+	}
+}
+
+// Delete a key-value pair if the key is existed, in its shard and in its
+// shard before the last Resize.
+func (c *ShardedCache) Delete(key interface{}) {
+	cur, prev := c.route(key)
+	cur.Delete(key)
+	if prev != nil {
+		prev.Delete(key)
+	}
+}
+
+// ItemCount return the number of items in all the shards, the keys not
+// moved yet included.
+func (c *ShardedCache) ItemCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	n := 0
+	for _, s := range c.shards {
+		n += s.ItemCount()
+	}
+	for i := len(c.shards); i < len(c.previous); i++ {
+		n += c.previous[i].ItemCount()
+	}
+	return n
+}
+
+// Shards return the number of shards.
+func (c *ShardedCache) Shards() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.shards)
+}
+
+// Resize change the number of shards. The keys whose shard changes are
+// moved on their first miss, see Get. The keys which were not moved since
+// the previous Resize are dropped with the shards removed by it.
+func (c *ShardedCache) Resize(shards int) error {
+	c.mu.Lock()
+	if err := c.ring.Resize(shards); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	current := c.shards
+	var dropped []*Cache
+	for i := len(current); i < len(c.previous); i++ {
+		dropped = append(dropped, c.previous[i])
+	}
+	if shards < len(current) {
+		c.shards = current[:shards:shards]
+	} else {
+		c.shards = append(current[:len(current):len(current)], make([]*Cache, shards-len(current))...)
+		for i := len(current); i < shards; i++ {
+			c.shards[i] = New(c.defaultExpiration, c.cleanInterval)
+		}
+	}
+	c.previous = current
+	c.mu.Unlock()
+	for _, s := range dropped {
+		s.Close()
+	}
+	return nil
+}
+
+// Close stop the janitors and remove every item from all the shards.
+func (c *ShardedCache) Close() {
+	c.mu.Lock()
+	shards := c.shards
+	for i := len(c.shards); i < len(c.previous); i++ {
+		shards = append(shards[:len(shards):len(shards)], c.previous[i])
+	}
+	c.mu.Unlock()
+	for _, s := range shards {
+		s.Close()
+	}
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestShardedResize(t *testing.T) {
+	if _, err := NewSharded(0, 0, 0); err == nil {
+		t.Error("Impossiable!")
+	}
+	c, err := NewSharded(4, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for i := 0; i < 1000; i++ {
+		c.Set(i, i, 0)
+	}
+	if err := c.Resize(5); err != nil {
+		t.Fatal(err)
+	}
+	if c.Shards() != 5 {
+		t.Error("You get a wrong number of shards", c.Shards())
+	}
+	if c.ItemCount() != 1000 {
+		t.Error("The items must be kept by Resize", c.ItemCount())
+	}
+	c.Delete(7)
+	for i := 0; i < 1000; i++ {
+		v, found := c.Get(i)
+		if i == 7 {
+			if found {
+				t.Error("A deleted key must not come back from its previous shard")
+			}
+			continue
+		}
+		if !found || v.(int) != i {
+			t.Error("The key must be found after Resize", i, v, found)
+		}
+	}
+	if c.ItemCount() != 999 {
+		t.Error("The keys must be moved, not copied", c.ItemCount())
+	}
+	if err := c.Resize(3); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if _, found := c.Get(i); found != (i != 7) {
+			t.Error("The key must be found after shrinking", i)
+		}
+	}
+	if c.Resize(0) == nil {
+		t.Error("Impossiable!")
+	}
+}
@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// SieveCache is a goroutine-safe cache using the SIEVE eviction algorithm.
+// Like CLOCK, a hit only marks the entry as visited and never moves it, so
+// Get runs under a read lock. New entries are inserted at the head of a
+// queue and a hand moves from the tail to the head, evicting the first
+// entry which was not visited and clearing the visited ones it passes.
+type SieveCache struct {
+	sync.RWMutex
+	maxEntries int
+	items      map[interface{}]*list.Element
+	queue      *list.List
+	hand       *list.Element
+}
+
+type sieveEntry struct {
+	key     interface{}
+	value   interface{}
+	visited uint32
+}
+
+// NewSieve create a SieveCache with max size. The size is 0 means no limit.
+func NewSieve(size int) (*SieveCache, error) {
+	if size < 0 {
+		return nil, errors.New("The size of Sieve Cache must no less than 0")
+	}
+	c := &SieveCache{
+		maxEntries: size,
+		items:      make(map[interface{}]*list.Element, size),
+		queue:      list.New(),
+	}
+	return c, nil
+}
+
+// Add a new key-value pair to the SieveCache.
+func (c *SieveCache) Add(key interface{}, value interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if ele, hit := c.items[key]; hit {
+		ent := ele.Value.(*sieveEntry)
+		ent.value = value
+		atomic.StoreUint32(&ent.visited, 1)
+		return
+	}
+	if c.maxEntries > 0 && len(c.items) >= c.maxEntries {
+		c.evict()
+	}
+	c.items[key] = c.queue.PushFront(&sieveEntry{key: key, value: value})
+}
+
+// Get a value from the SieveCache. And a bool indicating
+// whether found or not.
+func (c *SieveCache) Get(key interface{}) (interface{}, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	if ele, hit := c.items[key]; hit {
+		ent := ele.Value.(*sieveEntry)
+		if atomic.LoadUint32(&ent.visited) == 0 {
+			atomic.StoreUint32(&ent.visited, 1)
+		}
+		return ent.value, true
+	}
+	return nil, false
+}
+
+// Remove a key-value pair in SieveCache. If the key is not existed,
+// nothing will happen.
+func (c *SieveCache) Remove(key interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if ele, hit := c.items[key]; hit {
+		c.removeElement(ele)
+	}
+}
+
+// Return the number of key-value pair in SieveCache.
+func (c *SieveCache) Len() int {
+	c.RLock()
+	length := len(c.items)
+	c.RUnlock()
+	return length
+}
+
+// Delete all entry in the SieveCache. But the max size will hold.
+func (c *SieveCache) Clear() {
+	c.Lock()
+	c.items = make(map[interface{}]*list.Element, c.maxEntries)
+	c.queue = list.New()
+	c.hand = nil
+	c.Unlock()
+}
+
+// Resize the max limit. If the cache holds more entries than the new limit,
+// entries are evicted by the hand.
+func (c *SieveCache) SetMaxEntries(max int) error {
+	if max < 0 {
+		return errors.New("The max limit of entryies must no less than 0")
+	}
+	c.Lock()
+	c.maxEntries = max
+	for max > 0 && len(c.items) > max {
+		c.evict()
+	}
+	c.Unlock()
+	return nil
+}
+
+func (c *SieveCache) evict() {
+	ele := c.hand
+	if ele == nil {
+		ele = c.queue.Back()
+	}
+	for ele != nil {
+		ent := ele.Value.(*sieveEntry)
+		if atomic.LoadUint32(&ent.visited) == 0 {
+			break
+		}
+		atomic.StoreUint32(&ent.visited, 0)
+		if ele = ele.Prev(); ele == nil {
+			ele = c.queue.Back()
+		}
+	}
+	if ele != nil {
+		c.hand = ele.Prev()
+		c.removeElement(ele)
+	}
+}
+
+func (c *SieveCache) removeElement(ele *list.Element) {
+	if c.hand == ele {
+		c.hand = ele.Prev()
+	}
+	c.queue.Remove(ele)
+	delete(c.items, ele.Value.(*sieveEntry).key)
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestSieveCache(t *testing.T) {
+	if _, err := NewSieve(-1); err == nil {
+		t.Error("Impossiable!")
+	}
+	var c BoundedCache
+	c, err := NewSieve(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add("1", 111)
+	c.Add("2", 222)
+	c.Add("3", 333)
+	c.Get("1")
+	c.Get("3")
+	c.Add("4", 444)
+	if _, hit := c.Get("2"); hit {
+		t.Error("The value not visited must be removed")
+	}
+	c.Add("5", 555)
+	if _, hit := c.Get("4"); hit {
+		t.Error("The hand must resume from where it stopped")
+	}
+	if _, hit := c.Get("1"); !hit {
+		t.Error("The visited value must be kept")
+	}
+	if val, hit := c.Get("5"); !hit || val != 555 {
+		t.Error("I should get the key")
+	}
+	if c.Len() != 3 {
+		t.Error("Now, the len of sieve cache must be 3")
+	}
+	c.Remove("5")
+	if c.Len() != 2 {
+		t.Error("The value must be removed")
+	}
+	c.SetMaxEntries(1)
+	if c.Len() != 1 {
+		t.Error("The sieve cache must be shrunk to the new limit")
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Error("Now, the sieve cache is cleared")
+	}
+}
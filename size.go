@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// EstimateSize return the approximate number of bytes used by the items of
+// the cache, keys and values included, to watch its growth. The size of the
+// values implementing Sizer is their Size, the others are estimated by
+// following their pointers. It visits all the items with the read lock
+// held, so it is slow for a large cache, see SampleEntries.
+func (c *Cache) EstimateSize() int64 {
+	c.RLock()
+	defer c.RUnlock()
+	// The map holds a pointer to the Item for every key.
+	size := int64(len(c.items)) * int64(unsafe.Sizeof(uintptr(0))+unsafe.Sizeof(Item{}))
+	for k, item := range c.items {
+		size += sizeOf(k) + sizeOf(item.Object)
+		if item.Expiration != nil {
+			size += int64(unsafe.Sizeof(time.Time{}))
+		}
+	}
+	return size
+}
+
+// sizeOf return the Size of v if it is a Sizer, or estimateSize(v).
+func sizeOf(v interface{}) int64 {
+	if s, ok := v.(Sizer); ok {
+		return s.Size()
+	}
+	return estimateSize(v)
+}
+
+// estimateSize return the approximate number of bytes used by v, following
+// pointers, slices, maps and interfaces. Memory shared by several values is
+// only counted once.
+func estimateSize(v interface{}) int64 {
+	if v == nil {
+		return 0
+	}
+	seen := map[uintptr]bool{}
+	rv := reflect.ValueOf(v)
+	return int64(rv.Type().Size()) + indirectSize(rv, seen)
+}
+
+// indirectSize return the size of the memory referenced by v, not counting
+// v itself.
+func indirectSize(v reflect.Value, seen map[uintptr]bool) int64 {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		return int64(v.Type().Elem().Size()) + indirectSize(v.Elem(), seen)
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return int64(v.Elem().Type().Size()) + indirectSize(v.Elem(), seen)
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		if pointerFree(v.Type().Elem()) {
+			return size
+		}
+		for i := 0; i < v.Len(); i++ {
+			size += indirectSize(v.Index(i), seen)
+		}
+		return size
+	case reflect.Array:
+		if pointerFree(v.Type().Elem()) {
+			return 0
+		}
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += indirectSize(v.Index(i), seen)
+		}
+		return size
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		size := int64(unsafe.Sizeof(uintptr(0))) * 6
+		iter := v.MapRange()
+		for iter.Next() {
+			k, e := iter.Key(), iter.Value()
+			size += int64(k.Type().Size()) + indirectSize(k, seen)
+			size += int64(e.Type().Size()) + indirectSize(e, seen)
+		}
+		return size
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += indirectSize(v.Field(i), seen)
+		}
+		return size
+	}
+	return 0
+}
+
+// pointerFree return whether the values of t do not reference any memory,
+// so their size is only t.Size() and they need not be visited.
+func pointerFree(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return pointerFree(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !pointerFree(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+)
+
+// MaxSmallEntries is the largest size of a SmallLRUCache.
+const MaxSmallEntries = 64
+
+// SmallLRUCache is a goroutine-safe LRU cache for a few entries, like a
+// per-request memo cache. The entries live in one slice which is scanned
+// on every operation: for less than MaxSmallEntries entries this beats the
+// map and list of LRUCache, and it allocates nothing once full.
+type SmallLRUCache struct {
+	sync.Mutex
+	maxEntries int
+	entries    []smallEntry
+	tick       uint64
+}
+
+type smallEntry struct {
+	key   interface{}
+	value interface{}
+	// used is the tick of the last access, the oldest entry has the
+	// smallest one.
+	used uint64
+}
+
+// NewSmallLRU create a SmallLRUCache with max size, which must be between
+// 1 and MaxSmallEntries.
+func NewSmallLRU(size int) (*SmallLRUCache, error) {
+	if size <= 0 || size > MaxSmallEntries {
+		return nil, errors.New("The size of Small LRU Cache must between 1 and 64")
+	}
+	c := &SmallLRUCache{
+		maxEntries: size,
+		entries:    make([]smallEntry, 0, size),
+	}
+	return c, nil
+}
+
+// Add a new key-value pair to the SmallLRUCache.
+func (c *SmallLRUCache) Add(key interface{}, value interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	c.tick++
+	if i := c.index(key); i >= 0 {
+		c.entries[i].value = value
+		c.entries[i].used = c.tick
+		return
+	}
+	ent := smallEntry{key: key, value: value, used: c.tick}
+	if len(c.entries) < c.maxEntries {
+		c.entries = append(c.entries, ent)
+		return
+	}
+	c.entries[c.oldest()] = ent
+}
+
+// Get a value from the SmallLRUCache. And a bool indicating
+// whether found or not.
+func (c *SmallLRUCache) Get(key interface{}) (interface{}, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if i := c.index(key); i >= 0 {
+		c.tick++
+		c.entries[i].used = c.tick
+		return c.entries[i].value, true
+	}
+	return nil, false
+}
+
+// Remove a key-value pair in SmallLRUCache. If the key is not existed,
+// nothing will happen.
+func (c *SmallLRUCache) Remove(key interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if i := c.index(key); i >= 0 {
+		c.removeAt(i)
+	}
+}
+
+// Return the number of key-value pair in SmallLRUCache.
+func (c *SmallLRUCache) Len() int {
+	c.Lock()
+	length := len(c.entries)
+	c.Unlock()
+	return length
+}
+
+// Delete all entry in the SmallLRUCache. But the max size will hold.
+func (c *SmallLRUCache) Clear() {
+	c.Lock()
+	for i := range c.entries {
+		c.entries[i] = smallEntry{}
+	}
+	c.entries = c.entries[:0]
+	c.Unlock()
+}
+
+// Resize the max limit, which must be between 1 and MaxSmallEntries. If the
+// cache holds more entries than the new limit, the oldest ones are evicted.
+func (c *SmallLRUCache) SetMaxEntries(max int) error {
+	if max <= 0 || max > MaxSmallEntries {
+		return errors.New("The max limit of entryies must between 1 and 64")
+	}
+	c.Lock()
+	defer c.Unlock()
+	for len(c.entries) > max {
+		c.removeAt(c.oldest())
+	}
+	if cap(c.entries) < max {
+		entries := make([]smallEntry, len(c.entries), max)
+		copy(entries, c.entries)
+		c.entries = entries
+	}
+	c.maxEntries = max
+	return nil
+}
+
+func (c *SmallLRUCache) index(key interface{}) int {
+	for i := range c.entries {
+		if c.entries[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *SmallLRUCache) oldest() int {
+	oldest := 0
+	for i := range c.entries {
+		if c.entries[i].used < c.entries[oldest].used {
+			oldest = i
+		}
+	}
+	return oldest
+}
+
+func (c *SmallLRUCache) removeAt(i int) {
+	last := len(c.entries) - 1
+	c.entries[i] = c.entries[last]
+	c.entries[last] = smallEntry{}
+	c.entries = c.entries[:last]
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestSmallLRUCache(t *testing.T) {
+	if _, err := NewSmallLRU(0); err == nil {
+		t.Error("Impossiable!")
+	}
+	if _, err := NewSmallLRU(MaxSmallEntries + 1); err == nil {
+		t.Error("The small lru cache can not be that large")
+	}
+	var c BoundedCache
+	c, err := NewSmallLRU(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add("1", 111)
+	c.Add("2", 222)
+	c.Get("1")
+	c.Add("3", 333)
+	if _, hit := c.Get("2"); hit {
+		t.Error("The least recently used value must be removed")
+	}
+	if val, hit := c.Get("1"); !hit || val != 111 {
+		t.Error("I should get the key")
+	}
+	c.Remove("1")
+	if c.Len() != 1 {
+		t.Error("Now, there is one value in cache")
+	}
+	c.SetMaxEntries(4)
+	for i := 0; i < 4; i++ {
+		c.Add(i, i)
+	}
+	if c.Len() != 4 {
+		t.Error("Now, the len of small lru cache must be 4")
+	}
+	c.SetMaxEntries(1)
+	if _, hit := c.Get(3); !hit || c.Len() != 1 {
+		t.Error("Only the most recently used value must be kept")
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Error("Now, the small lru cache is cleared")
+	}
+}
+
+func BenchmarkSmallLRUGet(b *testing.B) {
+	b.StopTimer()
+	c, _ := NewSmallLRU(16)
+	for i := 0; i < 16; i++ {
+		c.Add(i, i)
+	}
+	b.ReportAllocs()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(i % 16)
+	}
+}
+
+func BenchmarkLRUGet(b *testing.B) {
+	b.StopTimer()
+	c, _ := NewLRU(16)
+	for i := 0; i < 16; i++ {
+		c.Add(i, i)
+	}
+	b.ReportAllocs()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(i % 16)
+	}
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Snapshotter write a Cache to a file periodically. Every snapshot is
+// written to a temporary file which is renamed over the previous one, so
+// the file always holds the last complete snapshot and can be given to
+// LoadFile at startup, or LoadFileEncrypted if an encryption key is set.
+type Snapshotter struct {
+	c        *Cache
+	path     string
+	interval time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	lastErr error
+	key     []byte
+}
+
+// NewSnapshotter create and start a Snapshotter writing c to path every
+// interval, which must greater than 0.
+func NewSnapshotter(c *Cache, path string, interval time.Duration) (*Snapshotter, error) {
+	if interval <= 0 {
+		return nil, errors.New("The snapshot interval must greater than 0")
+	}
+	s := &Snapshotter{
+		c:        c,
+		path:     path,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Snapshot write the cache to the file now.
+func (s *Snapshotter) Snapshot() error {
+	err := s.write()
+	s.mu.Lock()
+	s.lastErr = err
+	s.mu.Unlock()
+	return err
+}
+
+// SetEncryptionKey make the next snapshots encrypted with key, like
+// SaveEncrypted. A nil key turns encryption off.
+func (s *Snapshotter) SetEncryptionKey(key []byte) error {
+	if key != nil {
+		if _, err := newGCM(key); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	s.key = key
+	s.mu.Unlock()
+	return nil
+}
+
+// LastError return the error of the last snapshot, or nil if it succeeded.
+func (s *Snapshotter) LastError() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
+
+// Stop the periodic snapshots. It can be called more than once.
+func (s *Snapshotter) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	s.wg.Wait()
+}
+
+func (s *Snapshotter) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Snapshot()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Snapshotter) write() error {
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	s.mu.Lock()
+	key := s.key
+	s.mu.Unlock()
+	if key != nil {
+		err = s.c.SaveEncrypted(f, key)
+	} else {
+		err = s.c.Save(f)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.snapshot")
+	c := New(0, 0)
+	c.Set("key", "val", 0)
+	if _, err := NewSnapshotter(c, path, 0); err == nil {
+		t.Error("Impossiable!")
+	}
+	s, err := NewSnapshotter(c, path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	s.Stop()
+	s.Stop()
+	if s.LastError() != nil {
+		t.Fatal(s.LastError())
+	}
+	c2 := New(0, 0)
+	if err := c2.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := c2.Get("key"); val != "val" {
+		t.Error("You get a wrong value from the snapshot")
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Error("The temporary files must be renamed or removed")
+	}
+
+	bad, err := NewSnapshotter(c, filepath.Join(dir, "missing", "cache"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bad.Stop()
+	if err := bad.Snapshot(); err == nil || bad.LastError() != err {
+		t.Error("The error of the snapshot must be reported")
+	}
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"time"
+)
+
+// itemSnapshot is an immutable copy of the items, shared by the Range
+// calls until the items change.
+type itemSnapshot struct {
+	changes uint64
+	entries []snapshotEntry
+}
+
+type snapshotEntry struct {
+	key        interface{}
+	value      interface{}
+	expiration *time.Time
+}
+
+// SetSnapshotReads make Range iterate over an immutable copy of the items
+// instead of holding the read lock during the whole iteration, so a long
+// scan of a large cache does not stall the writers, and f may use the
+// cache. The copy is made under the read lock by the first Range after a
+// change of the items, and shared by the next ones, so it suits the caches
+// scanned more often than written. Range does not see the changes made
+// during its iteration.
+func (c *Cache) SetSnapshotReads(on bool) {
+	c.Lock()
+	c.snapshotReads = on
+	if !on {
+		c.snapshot.Store((*itemSnapshot)(nil))
+	}
+	c.Unlock()
+}
+
+// rangeSnapshot works like Range on the snapshot of the items. The caller
+// must hold the read lock, which it releases.
+func (c *Cache) rangeSnapshot(f func(key, value interface{}) bool) {
+	snap, _ := c.snapshot.Load().(*itemSnapshot)
+	if snap == nil || snap.changes != c.changes {
+		snap = &itemSnapshot{changes: c.changes, entries: make([]snapshotEntry, 0, len(c.items))}
+		for k, item := range c.items {
+			if c.keyAllowed(k) && c.access(k, OpGet) == nil && !c.removable(item) {
+				snap.entries = append(snap.entries, snapshotEntry{k, item.Object, item.Expiration})
+			}
+		}
+		c.snapshot.Store(snap)
+	}
+	now, degraded, copier := c.now(), c.degraded, c.copier
+	c.RUnlock()
+	for _, e := range snap.entries {
+		if !degraded && e.expiration != nil && e.expiration.Before(now) {
+			continue
+		}
+		value := e.value
+		if copier != nil {
+			value = copier(value)
+		}
+		if !f(e.key, value) {
+			return
+		}
+	}
+}
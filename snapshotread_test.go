@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotReads(t *testing.T) {
+	c := New(0, 0)
+	c.SetSnapshotReads(true)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	sum := 0
+	c.Range(func(key, value interface{}) bool {
+		sum += value.(int)
+		// The lock is not held, so f may write the cache.
+		c.Set(key.(string)+"2", value, 0)
+		return true
+	})
+	if sum != 3 {
+		t.Error("Range must visit the items which are not expired", sum)
+	}
+	if c.ItemCount() != 5 {
+		t.Error("Now, the cache has the copies", c.ItemCount())
+	}
+	snap := c.snapshot.Load().(*itemSnapshot)
+	n := 0
+	c.Range(func(key, value interface{}) bool {
+		n++
+		return true
+	})
+	if n != 4 || c.snapshot.Load().(*itemSnapshot) == snap {
+		t.Error("The snapshot must be made again after a change", n)
+	}
+	snap = c.snapshot.Load().(*itemSnapshot)
+	c.Range(func(key, value interface{}) bool { return true })
+	if c.snapshot.Load().(*itemSnapshot) != snap {
+		t.Error("The snapshot must be shared while the items do not change")
+	}
+	c.Increment("a", 1)
+	c.Range(func(key, value interface{}) bool {
+		if key == "a" && value != 2 {
+			t.Error("Range must see the increment", value)
+		}
+		return true
+	})
+	c.SetSnapshotReads(false)
+	c.Range(func(key, value interface{}) bool { return true })
+}
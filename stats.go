@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"sync/atomic"
+)
+
+// Stats are the counters of a Cache or LRUCache since it was created or
+// since the last ResetStats.
+type Stats struct {
+	// Hits and Misses are the number of Get which found the key or not.
+	Hits   uint64
+	Misses uint64
+	// Sets is the number of values stored.
+	Sets uint64
+	// Deletes is the number of entries removed on purpose.
+	Deletes uint64
+	// Evictions is the number of entries removed to make room.
+	Evictions uint64
+	// Expired is the number of expired entries removed.
+	Expired uint64
+	// CurrentEntries is the number of entries at the time of the call.
+	CurrentEntries int
+	// PeakEntries is the max number of entries seen, and AllTimePeakEntries
+	// the same since the creation, not reset by ResetStats.
+	PeakEntries        int
+	AllTimePeakEntries int
+	// MaxEntries is the limit of entries, or 0 if there is none. See
+	// SetMaxItems for a Cache.
+	MaxEntries int
+	// CurrentWeight, PeakWeight, AllTimePeakWeight and MaxWeight are the
+	// same for the total weight of the entries of a LRUCache, see
+	// AddWithWeight. They are 0 for a Cache.
+	CurrentWeight     int64
+	PeakWeight        int64
+	AllTimePeakWeight int64
+	MaxWeight         int64
+}
+
+// HitRate return Hits divided by the number of Get, or 0 if there was none.
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Occupancy return CurrentEntries divided by MaxEntries, or 0 if there is
+// no limit.
+func (s Stats) Occupancy() float64 {
+	if s.MaxEntries <= 0 {
+		return 0
+	}
+	return float64(s.CurrentEntries) / float64(s.MaxEntries)
+}
+
+// WeightOccupancy return CurrentWeight divided by MaxWeight, or 0 if there
+// is no limit.
+func (s Stats) WeightOccupancy() float64 {
+	if s.MaxWeight <= 0 {
+		return 0
+	}
+	return float64(s.CurrentWeight) / float64(s.MaxWeight)
+}
+
+// statCounters are updated atomically, so they can be updated under the
+// read lock. It is allocated on its own to keep the counters 64-bit aligned.
+type statCounters struct {
+	hits      uint64
+	misses    uint64
+	sets      uint64
+	deletes   uint64
+	evictions uint64
+	expired   uint64
+	// The high-water marks, the all-time ones are not reset.
+	peakEntries    int64
+	peakWeight     int64
+	allPeakEntries int64
+	allPeakWeight  int64
+}
+
+// load return the counters, with the current number of entries and
+// weight, which count in the high-water marks.
+func (s *statCounters) load(entries int, weight int64) Stats {
+	s.observe(entries, weight)
+	return Stats{
+		Hits:               atomic.LoadUint64(&s.hits),
+		Misses:             atomic.LoadUint64(&s.misses),
+		Sets:               atomic.LoadUint64(&s.sets),
+		Deletes:            atomic.LoadUint64(&s.deletes),
+		Evictions:          atomic.LoadUint64(&s.evictions),
+		Expired:            atomic.LoadUint64(&s.expired),
+		CurrentEntries:     entries,
+		PeakEntries:        int(atomic.LoadInt64(&s.peakEntries)),
+		AllTimePeakEntries: int(atomic.LoadInt64(&s.allPeakEntries)),
+		CurrentWeight:      weight,
+		PeakWeight:         atomic.LoadInt64(&s.peakWeight),
+		AllTimePeakWeight:  atomic.LoadInt64(&s.allPeakWeight),
+	}
+}
+
+// observe raise the high-water marks to the number of entries and weight
+// if they are higher.
+func (s *statCounters) observe(entries int, weight int64) {
+	raise(&s.peakEntries, int64(entries))
+	raise(&s.allPeakEntries, int64(entries))
+	raise(&s.peakWeight, weight)
+	raise(&s.allPeakWeight, weight)
+}
+
+// raise set *addr to v if it is higher.
+func raise(addr *int64, v int64) {
+	for {
+		old := atomic.LoadInt64(addr)
+		if v <= old || atomic.CompareAndSwapInt64(addr, old, v) {
+			return
+		}
+	}
+}
+
+func (s *statCounters) reset() {
+	atomic.StoreUint64(&s.hits, 0)
+	atomic.StoreUint64(&s.misses, 0)
+	atomic.StoreUint64(&s.sets, 0)
+	atomic.StoreUint64(&s.deletes, 0)
+	atomic.StoreUint64(&s.evictions, 0)
+	atomic.StoreUint64(&s.expired, 0)
+	atomic.StoreInt64(&s.peakEntries, 0)
+	atomic.StoreInt64(&s.peakWeight, 0)
+}
+
+// Stats return the counters of the cache.
+func (c *Cache) Stats() Stats {
+	c.RLock()
+	defer c.RUnlock()
+	return c.loadStats()
+}
+
+// loadStats return the counters of the cache. The caller must hold the
+// lock, the read lock is enough.
+func (c *Cache) loadStats() Stats {
+	s := c.stats.load(len(c.items), 0)
+	s.MaxEntries = c.maxItems
+	return s
+}
+
+// ResetStats set the counters of the cache back to 0, and the high-water
+// marks back to the current number of entries.
+func (c *Cache) ResetStats() {
+	c.stats.reset()
+}
+
+// Stats return the counters of the LRUCache.
+func (c *LRUCache) Stats() Stats {
+	c.RLock()
+	s := c.stats.load(c.cacheList.Len(), c.weight)
+	s.MaxEntries = c.maxEntries
+	s.MaxWeight = c.maxWeight
+	c.RUnlock()
+	return s
+}
+
+// ResetStats set the counters of the LRUCache back to 0, and the
+// high-water marks back to the current number of entries and weight.
+func (c *LRUCache) ResetStats() {
+	c.stats.reset()
+}
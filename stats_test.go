@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheStats(t *testing.T) {
+	c := New(0, 0)
+	c.SetMaxItems(2, 0, EvictNearestExpiry)
+	c.Set("a", 1, -1)
+	c.Set("b", 2, time.Millisecond)
+	c.Get("a")
+	c.Get("x")
+	c.Delete("a")
+	c.Set("c", 3, -1)
+	c.Set("d", 4, -1)
+	time.Sleep(2 * time.Millisecond)
+	c.DeleteExpired()
+	s := c.Stats()
+	if s.Hits != 1 || s.Misses != 1 || s.Sets != 4 || s.Deletes != 1 || s.Evictions != 1 || s.CurrentEntries != 2 {
+		t.Errorf("You get wrong stats: %+v", s)
+	}
+	if s.HitRate() != 0.5 {
+		t.Error("The hit rate must be 0.5")
+	}
+	c.ResetStats()
+	if s := c.Stats(); s.Sets != 0 || s.CurrentEntries != 2 {
+		t.Errorf("Now, the stats are reset: %+v", s)
+	}
+}
+
+func TestLRUCacheStats(t *testing.T) {
+	lru, _ := NewExpirableLRU(2, 0)
+	lru.Add("a", 1)
+	lru.AddWithTTL("b", 2, time.Millisecond)
+	lru.Get("a")
+	time.Sleep(2 * time.Millisecond)
+	lru.Get("b")
+	lru.Add("c", 3)
+	lru.Add("d", 4)
+	lru.Remove("d")
+	s := lru.Stats()
+	if s.Hits != 1 || s.Misses != 1 || s.Sets != 4 || s.Expired != 1 || s.Evictions != 1 || s.Deletes != 1 || s.CurrentEntries != 1 {
+		t.Errorf("You get wrong stats: %+v", s)
+	}
+}
+
+func TestStatsPeaks(t *testing.T) {
+	c := New(0, 0)
+	c.SetMaxItems(10, 0, EvictNearestExpiry)
+	for i := 0; i < 5; i++ {
+		c.Set(i, i, 0)
+	}
+	c.Delete(0)
+	c.Delete(1)
+	s := c.Stats()
+	if s.PeakEntries != 5 || s.AllTimePeakEntries != 5 || s.CurrentEntries != 3 || s.MaxEntries != 10 {
+		t.Errorf("You get wrong peaks: %+v", s)
+	}
+	if s.Occupancy() != 0.3 {
+		t.Error("You get a wrong occupancy", s.Occupancy())
+	}
+	c.ResetStats()
+	if s := c.Stats(); s.PeakEntries != 3 || s.AllTimePeakEntries != 5 {
+		t.Errorf("Now, the peak is reset to the current entries: %+v", s)
+	}
+
+	lru, _ := NewLRU(0)
+	lru.SetMaxWeight(100)
+	lru.AddWithWeight("a", 1, 60)
+	lru.AddWithWeight("b", 2, 30)
+	lru.Remove("a")
+	s = lru.Stats()
+	if s.PeakWeight != 90 || s.CurrentWeight != 30 || s.PeakEntries != 2 || s.WeightOccupancy() != 0.3 {
+		t.Errorf("You get wrong peaks: %+v", s)
+	}
+	if s.Occupancy() != 0 {
+		t.Error("There is no limit of entries")
+	}
+}
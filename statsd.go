@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Emitter is a metrics sink, like a StatsD or Datadog agent.
+type Emitter interface {
+	// Gauge report the current value of a metric.
+	Gauge(name string, value float64) error
+	// Count report the increase of a counter since the last report.
+	Count(name string, delta int64) error
+}
+
+// StatsDEmitter is an Emitter sending StatsD lines over UDP.
+type StatsDEmitter struct {
+	conn net.Conn
+}
+
+// NewStatsDEmitter create a StatsDEmitter sending to addr, like
+// "127.0.0.1:8125".
+func NewStatsDEmitter(addr string) (*StatsDEmitter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsDEmitter{conn: conn}, nil
+}
+
+// Gauge send "name:value|g".
+func (e *StatsDEmitter) Gauge(name string, value float64) error {
+	return e.send(name + ":" + strconv.FormatFloat(value, 'f', -1, 64) + "|g")
+}
+
+// Count send "name:delta|c".
+func (e *StatsDEmitter) Count(name string, delta int64) error {
+	return e.send(name + ":" + strconv.FormatInt(delta, 10) + "|c")
+}
+
+// Close the connection.
+func (e *StatsDEmitter) Close() error {
+	return e.conn.Close()
+}
+
+func (e *StatsDEmitter) send(line string) error {
+	_, err := e.conn.Write([]byte(line))
+	return err
+}
+
+// StatsSource is a cache with Stats, like Cache and LRUCache.
+type StatsSource interface {
+	Stats() Stats
+}
+
+// StatsReporter push the Stats of a cache to an Emitter periodically: the
+// hit ratio of the interval and the number of entries as gauges, and the
+// hits, misses, evictions and expirations as counts, all named prefix
+// followed by a dot and the metric.
+type StatsReporter struct {
+	source   StatsSource
+	prefix   string
+	emitter  Emitter
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	last    Stats
+	lastErr error
+}
+
+// NewStatsReporter create and start a StatsReporter flushing the stats of
+// source to e every interval.
+func NewStatsReporter(source StatsSource, prefix string, e Emitter, interval time.Duration) *StatsReporter {
+	r := &StatsReporter{
+		source:   source,
+		prefix:   prefix,
+		emitter:  e,
+		interval: interval,
+		stop:     make(chan struct{}),
+		last:     source.Stats(),
+	}
+	r.wg.Add(1)
+	go r.run()
+	return r
+}
+
+// Flush report the stats now.
+func (r *StatsReporter) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.source.Stats()
+	hits, misses := delta(s.Hits, r.last.Hits), delta(s.Misses, r.last.Misses)
+	evictions, expired := delta(s.Evictions, r.last.Evictions), delta(s.Expired, r.last.Expired)
+	r.last = s
+	ratio := 0.0
+	if hits+misses > 0 {
+		ratio = float64(hits) / float64(hits+misses)
+	}
+	var err error
+	record := func(e error) {
+		if err == nil {
+			err = e
+		}
+	}
+	record(r.emitter.Gauge(r.prefix+".hit_ratio", ratio))
+	record(r.emitter.Gauge(r.prefix+".entries", float64(s.CurrentEntries)))
+	record(r.emitter.Count(r.prefix+".hits", int64(hits)))
+	record(r.emitter.Count(r.prefix+".misses", int64(misses)))
+	record(r.emitter.Count(r.prefix+".evictions", int64(evictions)))
+	record(r.emitter.Count(r.prefix+".expired", int64(expired)))
+	r.lastErr = err
+	return err
+}
+
+// delta return the increase of a counter, which restarted from 0 if it
+// is less than before, because of ResetStats.
+func delta(now, before uint64) uint64 {
+	if now < before {
+		return now
+	}
+	return now - before
+}
+
+// LastError return the error of the last flush, or nil if it succeeded.
+func (r *StatsReporter) LastError() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastErr
+}
+
+// Stop the periodic reports.
+func (r *StatsReporter) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+}
+
+func (r *StatsReporter) run() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.Flush()
+		case <-r.stop:
+			return
+		}
+	}
+}
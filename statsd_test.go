@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsReporter(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	e, err := NewStatsDEmitter(pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	c := New(0, 0)
+	c.Get("a")
+	r := NewStatsReporter(c, "app.cache", e, time.Hour)
+	defer r.Stop()
+	c.Set("a", 1, 0)
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	if err := r.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	buf := make([]byte, 512)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	for i := 0; i < 6; i++ {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(buf[:n]))
+	}
+	got := strings.Join(lines, "\n")
+	for _, want := range []string{"app.cache.hit_ratio:0.6666666666666666|g", "app.cache.entries:1|g", "app.cache.hits:2|c", "app.cache.misses:1|c"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%s is not sent in:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNotFound is returned by Fetch when the key is not found, and by a
+// Store which does not have the key.
+var ErrNotFound = errors.New("The key is not found")
+
+// Store is the backend of a read-through cache, like a database.
+type Store interface {
+	// Load return the value of the key and how long to cache it, as the
+	// dur of Set, or ErrNotFound if the store does not have it.
+	Load(key interface{}) (value interface{}, ttl time.Duration, err error)
+}
+
+// ContextStore is a Store which takes the context of GetContext, to honor
+// its cancellation and deadline, or pass its values to the backend.
+type ContextStore interface {
+	Store
+	LoadContext(ctx context.Context, key interface{}) (value interface{}, ttl time.Duration, err error)
+}
+
+// WritableStore is a Store which can also be written, for a write-through
+// cache.
+type WritableStore interface {
+	Store
+	// Save the value of the key, which the cache keeps for ttl.
+	Save(key interface{}, value interface{}, ttl time.Duration) error
+	// Delete the key.
+	Delete(key interface{}) error
+}
+
+// SetStore make the misses of Get and Fetch fall through to s, and store
+// what it loads. Concurrent misses of a key share one Load. The errors of s
+// are not cached. If s is a WritableStore, the cache is also write-through,
+// see SetSync and DeleteSync. Set to nil to remove it.
+func (c *Cache) SetStore(s Store) {
+	c.Lock()
+	c.store = s
+	c.Unlock()
+}
+
+// SetSync works like Set, but if the Store of the cache is a WritableStore,
+// the value is saved to it first, and the cache is only updated if it
+// succeeds. The error of the store is returned, or ErrFull, see
+// SetFullPolicy. Set calls SetSync and
+// ignores the error. The store is called without the lock held, so the
+// concurrent writes of a key may reach the store and the cache in a
+// different order.
+func (c *Cache) SetSync(key interface{}, val interface{}, dur time.Duration) error {
+	return c.SetContext(context.Background(), key, val, dur)
+}
+
+// SetContext works like SetSync, passing ctx to the access check, see
+// SetAccessCheck. It returns the error of ctx if ctx is done before the
+// write. With FullBlock, it waits until ctx is done for room in a full cache
+// and returns the error of ctx.
+func (c *Cache) SetContext(ctx context.Context, key interface{}, val interface{}, dur time.Duration) error {
+	if log := c.opLog(); log != nil {
+		log.record("set", key)
+	}
+	return c.setContext(ctx, key, val, dur)
+}
+
+func (c *Cache) setContext(ctx context.Context, key interface{}, val interface{}, dur time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !c.keyAllowed(key) {
+		return nil
+	}
+	c.Lock()
+	if err := c.checkAccess(ctx, key, OpSet); err != nil {
+		c.Unlock()
+		return err
+	}
+	if err := c.admit(ctx, key); err != nil {
+		c.Unlock()
+		return err
+	}
+	if wb := c.writeBehind; wb != nil {
+		var err error
+		ttl := c.cappedTTL(dur)
+		m := Mutation{Key: key, Value: val, TTL: ttl}
+		ok := !c.tombstoned(key)
+		if ok && wb.policy.HoldUntilWritten {
+			// The key expires when the mutation is written, see
+			// expireWritten.
+			if err = c.set(key, val, -1); err == nil {
+				m.version = c.items[key].version
+			}
+		} else if ok {
+			err = c.set(key, val, dur)
+		}
+		c.unlockAndNotify()
+		if ok && err == nil {
+			wb.enqueue(m)
+		}
+		return err
+	}
+	if s, ok := c.store.(WritableStore); ok {
+		ttl := c.cappedTTL(dur)
+		c.Unlock()
+		if err := s.Save(key, val, ttl); err != nil {
+			return err
+		}
+		c.Lock()
+	}
+	var err error
+	if !c.tombstoned(key) {
+		err = c.set(key, val, dur)
+	}
+	c.unlockAndNotify()
+	return err
+}
+
+// DeleteSync works like Delete, but if the Store of the cache is a
+// WritableStore, the key is deleted from it first, and from the cache only
+// if it succeeds. The error of the store is returned.
+func (c *Cache) DeleteSync(key interface{}) error {
+	return c.DeleteContext(context.Background(), key)
+}
+
+// DeleteContext works like DeleteSync, passing ctx to the access check, see
+// SetAccessCheck. It returns the error of ctx if ctx is done before the
+// delete.
+func (c *Cache) DeleteContext(ctx context.Context, key interface{}) error {
+	if log := c.opLog(); log != nil {
+		log.record("delete", key)
+	}
+	err := c.deleteContext(ctx, key)
+	if err == nil {
+		c.publishInvalidation(key)
+	}
+	return err
+}
+
+func (c *Cache) deleteContext(ctx context.Context, key interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !c.keyAllowed(key) {
+		return nil
+	}
+	c.Lock()
+	if err := c.checkAccess(ctx, key, OpDelete); err != nil {
+		c.Unlock()
+		return err
+	}
+	wb := c.writeBehind
+	if s, ok := c.store.(WritableStore); ok && wb == nil {
+		c.Unlock()
+		if err := s.Delete(key); err != nil {
+			return err
+		}
+		c.Lock()
+	}
+	if item, ok := c.items[key]; ok {
+		delete(c.items, key)
+		atomic.AddUint64(&c.stats.deletes, 1)
+		c.removed(key, item)
+	}
+	c.unlockAndNotify()
+	if wb != nil {
+		wb.enqueue(Mutation{Key: key, Delete: true})
+	}
+	return nil
+}
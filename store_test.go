@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mapStore struct {
+	data  map[interface{}]interface{}
+	loads int
+	err   error
+}
+
+func (s *mapStore) Load(key interface{}) (interface{}, time.Duration, error) {
+	s.loads++
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	if val, ok := s.data[key]; ok {
+		return val, time.Hour, nil
+	}
+	return nil, 0, ErrNotFound
+}
+
+func TestStore(t *testing.T) {
+	c := New(0, 0)
+	s := &mapStore{data: map[interface{}]interface{}{"a": 1}}
+	c.SetStore(s)
+	for i := 0; i < 2; i++ {
+		if val, found := c.Get("a"); !found || val != 1 {
+			t.Error("The value must be loaded from the store", val)
+		}
+	}
+	if s.loads != 1 {
+		t.Error("The loaded value must be cached")
+	}
+	if info, _ := c.GetItemInfo("a"); info.Expiration == nil {
+		t.Error("The TTL of the store must be used")
+	}
+	if _, err := c.Fetch("missing"); err != ErrNotFound {
+		t.Error("You get a wrong error", err)
+	}
+	s.err = errors.New("database down")
+	if _, err := c.Fetch("b"); err != s.err {
+		t.Error("The error of the store must be returned", err)
+	}
+	if c.ItemCount() != 1 {
+		t.Error("Impossiable!")
+	}
+}
+
+type writableMapStore struct {
+	mapStore
+	saveErr error
+}
+
+func (s *writableMapStore) Save(key interface{}, value interface{}, ttl time.Duration) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.data[key] = value
+	return nil
+}
+
+func (s *writableMapStore) Delete(key interface{}) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	delete(s.data, key)
+	return nil
+}
+
+func TestWriteThrough(t *testing.T) {
+	c := New(0, 0)
+	s := &writableMapStore{mapStore: mapStore{data: map[interface{}]interface{}{}}}
+	c.SetStore(s)
+	c.Set("a", 1, 0)
+	if s.data["a"] != 1 {
+		t.Error("The value must be saved to the store")
+	}
+	s.saveErr = errors.New("database down")
+	if err := c.SetSync("a", 2, 0); err != s.saveErr {
+		t.Error("You get a wrong error", err)
+	}
+	if val, _ := c.Get("a"); val != 1 {
+		t.Error("The cache must not be updated when the store fails")
+	}
+	if err := c.DeleteSync("a"); err != s.saveErr || c.ItemCount() != 1 {
+		t.Error("The key must not be deleted when the store fails")
+	}
+	s.saveErr = nil
+	c.Delete("a")
+	if _, ok := s.data["a"]; ok || c.ItemCount() != 0 {
+		t.Error("The key must be deleted from both")
+	}
+}
+
+type contextMapStore struct {
+	mapStore
+	ctx context.Context
+}
+
+func (s *contextMapStore) LoadContext(ctx context.Context, key interface{}) (interface{}, time.Duration, error) {
+	s.ctx = ctx
+	return s.Load(key)
+}
+
+type ctxKey struct{}
+
+func TestContextStore(t *testing.T) {
+	c := New(0, 0)
+	s := &contextMapStore{mapStore: mapStore{data: map[interface{}]interface{}{"a": 1}}}
+	c.SetStore(s)
+	ctx := context.WithValue(context.Background(), ctxKey{}, "trace")
+	if val, err := c.GetContext(ctx, "a"); err != nil || val != 1 {
+		t.Error("You get a wrong value", val, err)
+	}
+	if s.ctx == nil || s.ctx.Value(ctxKey{}) != "trace" {
+		t.Error("The context must be passed to the store")
+	}
+}
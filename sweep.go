@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+// sweepCheckEvery is the number of items checked between two reads of the
+// clock in a pass limited by time.
+const sweepCheckEvery = 64
+
+// SetCleanupBudget make the janitor delete the expired items incrementally:
+// every cleanup checks at most n items, or for at most d, and the next one
+// resumes where it stopped, so the lock is never held for a scan of a very
+// large cache. The expired tombstones are deleted once all the items were
+// checked. A limit is 0 means no limit, and both are 0 means a full scan by
+// DeleteExpired, the default.
+func (c *Cache) SetCleanupBudget(n int, d time.Duration) error {
+	if n < 0 || d < 0 {
+		return errors.New("The cleanup budget must no less than 0")
+	}
+	c.Lock()
+	c.cleanupItems = n
+	c.cleanupTime = d
+	c.Unlock()
+	return nil
+}
+
+// DeleteExpiredIncremental delete the expired items of the next part of the
+// cache allowed by the cleanup budget, like the janitor does, and return
+// their number. Without budget, it works like DeleteExpired. The items are
+// not removed in the order of their expiration, but are delivered in this
+// order by SubscribeExpired.
+func (c *Cache) DeleteExpiredIncremental() int {
+	c.RLock()
+	full := c.cleanupItems == 0 && c.cleanupTime == 0
+	c.RUnlock()
+	if full {
+		return c.DeleteExpired()
+	}
+	var expired []Expiration
+	deleted := 0
+	c.Lock()
+	// A map iterator stays valid while the map is modified between the
+	// passes: the deleted items are skipped, and the new ones may be
+	// checked in this cycle or in the next.
+	m := reflect.ValueOf(c.items)
+	if c.sweep == nil || c.sweepMap != m.Pointer() {
+		c.sweep, c.sweepMap = m.MapRange(), m.Pointer()
+	}
+	start := time.Now()
+	for n := 1; c.cleanupItems == 0 || n <= c.cleanupItems; n++ {
+		if !c.sweep.Next() {
+			c.sweep = nil
+			c.deleteExpiredTombstones()
+			break
+		}
+		if c.deleteIfExpired(c.sweep.Key().Interface(), c.sweep.Value().Interface().(*Item), &expired) {
+			deleted++
+		}
+		if c.cleanupTime > 0 && n%sweepCheckEvery == 0 && time.Since(start) >= c.cleanupTime {
+			break
+		}
+	}
+	c.notifyExpired(expired)
+	c.unlockAndNotify()
+	return deleted
+}
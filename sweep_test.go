@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeleteExpiredIncremental(t *testing.T) {
+	c := New(0, 0)
+	for i := 0; i < 100; i++ {
+		c.Set(i, i, time.Millisecond)
+	}
+	c.Set("forever", 0, -1)
+	time.Sleep(2 * time.Millisecond)
+	if err := c.SetCleanupBudget(30, 0); err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for pass := 0; pass < 4; pass++ {
+		n := c.DeleteExpiredIncremental()
+		if n > 30 {
+			t.Errorf("A pass must check at most 30 items, not %d", n)
+		}
+		total += n
+		if pass == 0 {
+			c.Delete(99)
+			c.Delete(98)
+			c.Set("new", 1, time.Millisecond)
+		}
+	}
+	if c.ItemCount() > 2 || total < 97 {
+		t.Errorf("All the expired items must be deleted in 4 passes, %d left", c.ItemCount())
+	}
+	c.Flush()
+	c.Set("a", 1, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if c.DeleteExpiredIncremental() != 1 {
+		t.Error("The sweep must restart on the new map after Flush")
+	}
+	c.SetCleanupBudget(0, time.Millisecond)
+	c.Set("b", 1, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if c.DeleteExpiredIncremental() != 1 {
+		t.Error("Impossiable!")
+	}
+}
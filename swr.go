@@ -0,0 +1,38 @@
+package cache
+
+// SetStaleWhileRevalidate make Get return the stale items, expired but
+// within the stale window set by SetStaleWindow, instead of missing them,
+// and refresh them in the background by calling loader. The new value is
+// set with the default expiration. If the loader fails, the item stays
+// stale and the next Get tries again. One refresh at most runs per key, the
+// item being refreshing meanwhile. A nil loader disables it, and so does
+// the DisableRefresh of RefreshFlags.
+func (c *Cache) SetStaleWhileRevalidate(loader func(key interface{}) (interface{}, error)) {
+	c.Lock()
+	c.revalidate = loader
+	c.Unlock()
+}
+
+// startRevalidate mark a stale item refreshing and refresh it in a new
+// goroutine, unless another Get did it first.
+func (c *Cache) startRevalidate(key interface{}) {
+	c.Lock()
+	item, ok := c.items[key]
+	loader := c.revalidate
+	if !ok || loader == nil || c.refreshDisabled() || item.refreshing || !c.expired(item) || c.removable(item) {
+		c.unlockAndNotify()
+		return
+	}
+	item = c.writable(key, item)
+	item.refreshing = true
+	c.stateChanged(key, StateRefreshing)
+	c.unlockAndNotify()
+	go func() {
+		val, err := loader(key)
+		if err != nil {
+			c.SetRefreshing(key, false)
+			return
+		}
+		c.Set(key, val, 0)
+	}()
+}
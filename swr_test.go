@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	c := New(time.Hour, 0)
+	c.SetStaleWindow(time.Hour)
+	var calls int32
+	release := make(chan struct{})
+	c.SetStaleWhileRevalidate(func(key interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "new", nil
+	})
+	c.Set("a", "old", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if val, found := c.Get("a"); !found || val != "old" {
+			t.Error("The stale value must be returned", val)
+		}
+	}
+	if info, _ := c.GetItemInfo("a"); info.State != StateRefreshing {
+		t.Errorf("You get a wrong state %v", info.State)
+	}
+	close(release)
+	for i := 0; i < 100; i++ {
+		if val, _ := c.Get("a"); val == "new" {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if val, _ := c.Get("a"); val != "new" || atomic.LoadInt32(&calls) != 1 {
+		t.Error("The value must be refreshed once", val)
+	}
+
+	c.SetStaleWhileRevalidate(func(key interface{}) (interface{}, error) {
+		return nil, errors.New("origin down")
+	})
+	c.Set("b", "old", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	c.Get("b")
+	time.Sleep(10 * time.Millisecond)
+	if info, _ := c.GetItemInfo("b"); info.State != StateStale {
+		t.Errorf("A failed refresh must leave the item stale, not %v", info.State)
+	}
+}
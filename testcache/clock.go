@@ -0,0 +1,131 @@
+// Package testcache helps to test the code using the package cache.
+package testcache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+// Clock is a fake cache.Clock whose time only moves by Advance and Set, so
+// the expirations can be tested without sleeping:
+//
+//	clk := testcache.NewClock(time.Now())
+//	c := cache.New(time.Minute, 0)
+//	c.SetClock(clk)
+//	c.Set("a", 1, 0)
+//	clk.Advance(2 * time.Minute)
+//	c.Get("a") // not found
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*ticker
+}
+
+// NewClock create a Clock at now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now return the time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance move the clock d forward, and make its tickers tick. Like a
+// time.Ticker, a ticker ticks once even if several periods passed, and drops
+// the tick if the previous one was not received yet.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set move the clock to now, which must not be before its time, and make
+// its tickers tick.
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(now)
+}
+
+func (c *Clock) set(now time.Time) {
+	if now.Before(c.now) {
+		panic("testcache: the clock can not go backward")
+	}
+	c.now = now
+	for _, t := range c.tickers {
+		if now.Before(t.next) {
+			continue
+		}
+		select {
+		case t.ch <- now:
+		default:
+		}
+		for !now.Before(t.next) {
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+// NewTicker return a ticker ticking every d of the clock. The period must
+// be greater than 0.
+func (c *Clock) NewTicker(d time.Duration) cache.Ticker {
+	if d <= 0 {
+		panic("testcache: non-positive interval for NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &ticker{clock: c, ch: make(chan time.Time, 1), period: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+type ticker struct {
+	clock  *Clock
+	ch     chan time.Time
+	period time.Duration
+	next   time.Time
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *ticker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("testcache: non-positive interval for Reset")
+	}
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.period = d
+	t.next = c.now.Add(d)
+	if !c.has(t) {
+		c.tickers = append(c.tickers, t)
+	}
+}
+
+func (t *ticker) Stop() {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, other := range c.tickers {
+		if other == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *Clock) has(t *ticker) bool {
+	for _, other := range c.tickers {
+		if other == t {
+			return true
+		}
+	}
+	return false
+}
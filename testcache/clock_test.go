@@ -0,0 +1,74 @@
+package testcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+func TestClockExpiration(t *testing.T) {
+	clk := NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := cache.New(time.Minute, 0)
+	c.SetClock(clk)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, time.Hour)
+	clk.Advance(59 * time.Second)
+	if _, found := c.Get("a"); !found {
+		t.Error("The item must not be expired yet")
+	}
+	clk.Advance(2 * time.Second)
+	if _, found := c.Get("a"); found {
+		t.Error("The item must be expired by the fake clock")
+	}
+	if n := c.DeleteExpired(); n != 1 || c.ItemCount() != 1 {
+		t.Error("Now, only b is left", n)
+	}
+	if info, _ := c.GetItemInfo("b"); !info.Expiration.Equal(clk.Now().Add(time.Hour - 61*time.Second)) {
+		t.Error("The expiration must be computed from the fake clock", info.Expiration)
+	}
+}
+
+func TestClockJanitor(t *testing.T) {
+	clk := NewClock(time.Now())
+	c := cache.New(0, time.Minute)
+	defer c.Close()
+	c.SetClock(clk)
+	c.Set("a", 1, time.Second)
+	clk.Advance(2 * time.Second)
+	if c.ItemCount() != 1 {
+		t.Error("The janitor must wait for its interval on the fake clock")
+	}
+	clk.Advance(time.Minute)
+	for i := 0; c.ItemCount() != 0; i++ {
+		if i == 100 {
+			t.Fatal("The janitor must run at the tick of the fake clock")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTicker(t *testing.T) {
+	clk := NewClock(time.Now())
+	ticker := clk.NewTicker(time.Second)
+	clk.Advance(500 * time.Millisecond)
+	select {
+	case <-ticker.C():
+		t.Error("Impossiable!")
+	default:
+	}
+	clk.Advance(3 * time.Second)
+	<-ticker.C()
+	select {
+	case <-ticker.C():
+		t.Error("The missed ticks must be dropped")
+	default:
+	}
+	ticker.Stop()
+	clk.Advance(time.Hour)
+	select {
+	case <-ticker.C():
+		t.Error("A stopped ticker must not tick")
+	default:
+	}
+}
@@ -0,0 +1,189 @@
+package testcache
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maemual/go-cache"
+)
+
+// The names of the operations recorded by a Recorder.
+const (
+	OpGet    = "get"
+	OpSet    = "set"
+	OpDelete = "delete"
+)
+
+// Op is an operation recorded by a Recorder.
+type Op struct {
+	At   time.Time
+	Name string
+	Key  interface{}
+	// Value is the value of a set, or the value returned by a get.
+	Value interface{}
+	// Dur is the duration of a set.
+	Dur time.Duration
+	// Found is the result of a get.
+	Found bool
+}
+
+// Response is a scripted response of Get, see Script.
+type Response struct {
+	Value interface{}
+	Found bool
+}
+
+// Recorder is a cache.Interface recording its operations, to unit test the
+// code using a cache. It stores the values like a cache, expiring them by
+// its clock, but the responses of Get can be scripted per key. The zero
+// Recorder is not usable, call NewRecorder.
+type Recorder struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	items   map[interface{}]entry
+	scripts map[interface{}][]Response
+	ops     []Op
+}
+
+type entry struct {
+	val        interface{}
+	expiration time.Time
+}
+
+var _ cache.Interface = (*Recorder)(nil)
+
+// NewRecorder create an empty Recorder. Its operations are timestamped by
+// clk, or by the system clock if clk is nil.
+func NewRecorder(clk cache.Clock) *Recorder {
+	r := &Recorder{
+		now:     time.Now,
+		items:   map[interface{}]entry{},
+		scripts: map[interface{}][]Response{},
+	}
+	if clk != nil {
+		r.now = clk.Now
+	}
+	return r
+}
+
+// Get return the next scripted response of the key if there is one,
+// otherwise the value stored by Set if it is not expired.
+func (r *Recorder) Get(key interface{}) (interface{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	var val interface{}
+	var found bool
+	if script := r.scripts[key]; len(script) > 0 {
+		val, found = script[0].Value, script[0].Found
+		r.scripts[key] = script[1:]
+	} else if e, ok := r.items[key]; ok && (e.expiration.IsZero() || now.Before(e.expiration)) {
+		val, found = e.val, true
+	}
+	r.ops = append(r.ops, Op{At: now, Name: OpGet, Key: key, Value: val, Found: found})
+	return val, found
+}
+
+// Set store the value of the key for dur, or without expiration if dur is
+// not greater than 0.
+func (r *Recorder) Set(key interface{}, val interface{}, dur time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	e := entry{val: val}
+	if dur > 0 {
+		e.expiration = now.Add(dur)
+	}
+	r.items[key] = e
+	r.ops = append(r.ops, Op{At: now, Name: OpSet, Key: key, Value: val, Dur: dur})
+}
+
+// Delete the value of the key.
+func (r *Recorder) Delete(key interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.items, key)
+	r.ops = append(r.ops, Op{At: r.now(), Name: OpDelete, Key: key})
+}
+
+// Script make the next Gets of the key return the responses in order,
+// whatever is stored. Once they are used up, Get returns the stored value
+// again. Script appends to the responses not used yet.
+func (r *Recorder) Script(key interface{}, responses ...Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scripts[key] = append(r.scripts[key], responses...)
+}
+
+// Ops return the recorded operations, oldest first.
+func (r *Recorder) Ops() []Op {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Op(nil), r.ops...)
+}
+
+// Reset forget the recorded operations, the stored values and the scripts.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = map[interface{}]entry{}
+	r.scripts = map[interface{}][]Response{}
+	r.ops = nil
+}
+
+// Count return the number of operations named name on the key.
+func (r *Recorder) Count(name string, key interface{}) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, op := range r.ops {
+		if op.Name == name && reflect.DeepEqual(op.Key, key) {
+			n++
+		}
+	}
+	return n
+}
+
+// AssertCount report an error to t if the number of operations named name
+// on the key is not n.
+func (r *Recorder) AssertCount(t testing.TB, name string, key interface{}, n int) {
+	t.Helper()
+	if got := r.Count(name, key); got != n {
+		t.Errorf("testcache: %s %v was called %d times, not %d", name, key, got, n)
+	}
+}
+
+// AssertCalled report an error to t if there is no operation named name on
+// the key.
+func (r *Recorder) AssertCalled(t testing.TB, name string, key interface{}) {
+	t.Helper()
+	if r.Count(name, key) == 0 {
+		t.Errorf("testcache: %s %v was not called", name, key)
+	}
+}
+
+// AssertNotCalled report an error to t if there is an operation named name
+// on the key.
+func (r *Recorder) AssertNotCalled(t testing.TB, name string, key interface{}) {
+	t.Helper()
+	if n := r.Count(name, key); n != 0 {
+		t.Errorf("testcache: %s %v was called %d times", name, key, n)
+	}
+}
+
+// AssertStored report an error to t if the value of the key is not stored,
+// expired or different from val.
+func (r *Recorder) AssertStored(t testing.TB, key interface{}, val interface{}) {
+	t.Helper()
+	r.mu.Lock()
+	e, ok := r.items[key]
+	now := r.now()
+	r.mu.Unlock()
+	if !ok || !e.expiration.IsZero() && !now.Before(e.expiration) {
+		t.Errorf("testcache: %v is not stored", key)
+	} else if !reflect.DeepEqual(e.val, val) {
+		t.Errorf("testcache: %v is %v, not %v", key, e.val, val)
+	}
+}
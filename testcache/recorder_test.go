@@ -0,0 +1,63 @@
+package testcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	clk := NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	r := NewRecorder(clk)
+	r.Set("a", 1, time.Minute)
+	clk.Advance(time.Second)
+	if val, found := r.Get("a"); !found || val != 1 {
+		t.Error("The stored value must be returned", val)
+	}
+	r.Script("a", Response{Found: false}, Response{Value: 2, Found: true})
+	if _, found := r.Get("a"); found {
+		t.Error("The first scripted response must be returned")
+	}
+	if val, _ := r.Get("a"); val != 2 {
+		t.Error("The second scripted response must be returned", val)
+	}
+	if val, _ := r.Get("a"); val != 1 {
+		t.Error("Now, the scripts are used up", val)
+	}
+	r.AssertStored(t, "a", 1)
+	clk.Advance(time.Minute)
+	if _, found := r.Get("a"); found {
+		t.Error("The value must be expired by the clock")
+	}
+	r.Delete("b")
+	r.AssertCount(t, OpGet, "a", 5)
+	r.AssertCalled(t, OpSet, "a")
+	r.AssertCalled(t, OpDelete, "b")
+	r.AssertNotCalled(t, OpDelete, "a")
+
+	ops := r.Ops()
+	if len(ops) != 7 || ops[0].Name != OpSet || ops[0].Dur != time.Minute || !ops[1].At.Equal(ops[0].At.Add(time.Second)) {
+		t.Errorf("You get wrong operations %+v", ops)
+	}
+
+	mock := &mockTB{TB: t}
+	r.AssertCalled(mock, OpSet, "b")
+	r.AssertStored(mock, "a", 1)
+	if mock.errors != 2 {
+		t.Error("The assertions must fail")
+	}
+	r.Reset()
+	if len(r.Ops()) != 0 || r.Count(OpGet, "a") != 0 {
+		t.Error("Impossiable!")
+	}
+}
+
+type mockTB struct {
+	testing.TB
+	errors int
+}
+
+func (m *mockTB) Helper() {}
+
+func (m *mockTB) Errorf(format string, args ...interface{}) {
+	m.errors++
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"time"
+)
+
+// Interface is the common interface of the caches storing values with a
+// TTL, like Cache, LRUCache and TieredCache, so they can be composed.
+type Interface interface {
+	Get(key interface{}) (interface{}, bool)
+	// Set the value of the key for dur. If the dur is 0, the default
+	// expiration of the cache is used, and if it is less than 0 the value
+	// never expires.
+	Set(key interface{}, val interface{}, dur time.Duration)
+	Delete(key interface{})
+}
+
+// TieredCache compose a small and fast L1 cache in front of a larger L2
+// cache, for example a LRUCache in front of a Cache, or a Cache in front of
+// a remote cache. Values found in L2 are promoted to L1.
+type TieredCache struct {
+	l1    Interface
+	l2    Interface
+	l1TTL time.Duration
+}
+
+// NewTiered create a TieredCache. Values are kept in L1 for l1TTL at most,
+// which bounds how stale L1 can be when L2 is shared and written by
+// others. The l1TTL is 0 means the TTL of the values.
+func NewTiered(l1, l2 Interface, l1TTL time.Duration) *TieredCache {
+	return &TieredCache{l1: l1, l2: l2, l1TTL: l1TTL}
+}
+
+// Get a value from L1, or from L2 promoting it to L1.
+func (t *TieredCache) Get(key interface{}) (interface{}, bool) {
+	if val, found := t.l1.Get(key); found {
+		return val, true
+	}
+	val, found := t.l2.Get(key)
+	if found {
+		t.l1.Set(key, val, t.l1Dur(0))
+	}
+	return val, found
+}
+
+// Set a value in L2, then in L1.
+func (t *TieredCache) Set(key interface{}, val interface{}, dur time.Duration) {
+	t.l2.Set(key, val, dur)
+	t.l1.Set(key, val, t.l1Dur(dur))
+}
+
+// Delete a key from L2, then from L1.
+func (t *TieredCache) Delete(key interface{}) {
+	t.l2.Delete(key)
+	t.l1.Delete(key)
+}
+
+// l1Dur return the dur for L1 of a value set for dur.
+func (t *TieredCache) l1Dur(dur time.Duration) time.Duration {
+	if t.l1TTL > 0 && (dur <= 0 || dur > t.l1TTL) {
+		return t.l1TTL
+	}
+	return dur
+}
+
+// Set add a key-value pair which expires after dur, like AddWithTTL, so a
+// LRUCache implements Interface.
+func (c *LRUCache) Set(key interface{}, val interface{}, dur time.Duration) {
+	c.AddWithTTL(key, val, dur)
+}
+
+// Delete a key-value pair, like Remove.
+func (c *LRUCache) Delete(key interface{}) {
+	c.Remove(key)
+}
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTieredCache(t *testing.T) {
+	l1, _ := NewLRU(1)
+	l2 := New(0, 0)
+	var tiered Interface = NewTiered(l1, l2, time.Minute)
+	tiered.Set("a", 1, time.Hour)
+	tiered.Set("b", 2, 0)
+	if l1.Contains("a") || l2.ItemCount() != 2 {
+		t.Error("L1 must hold the last value and L2 both")
+	}
+	if val, found := tiered.Get("a"); !found || val != 1 || !l1.Contains("a") {
+		t.Error("The value of L2 must be promoted")
+	}
+	l1.Lock()
+	exp := l1.items["a"].Value.(*entry).expiration
+	l1.Unlock()
+	if exp == nil || exp.After(time.Now().Add(time.Minute)) {
+		t.Error("The L1 TTL must be applied")
+	}
+	tiered.Delete("a")
+	if _, found := tiered.Get("a"); found || l1.Contains("a") {
+		t.Error("Now, the key is deleted")
+	}
+}
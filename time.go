@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"time"
+)
+
+// Clock is the time source of a Cache, see SetClock. The package testcache
+// has a fake clock to test the expirations without sleeping.
+type Clock interface {
+	Now() time.Time
+	// NewTicker return a ticker sending the time every d, like
+	// time.NewTicker. The janitor uses it.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is a ticker made by a Clock, like a time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Reset(d time.Duration)
+	Stop()
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t realTicker) Reset(d time.Duration) {
+	t.t.Reset(d)
+}
+
+func (t realTicker) Stop() {
+	t.t.Stop()
+}
+
+// SetClock make the cache use clk instead of the system clock for the
+// expirations, the tombstones and the janitor, nil restores the system
+// clock. It is meant for the tests, and should be called before the cache
+// is used: the expirations already set are not moved.
+func (c *Cache) SetClock(clk Clock) {
+	if clk == nil {
+		clk = realClock{}
+	}
+	c.Lock()
+	c.clock = clk
+	j := c.janitor
+	c.Unlock()
+	if j != nil {
+		// Wait for the janitor to use the new ticker, so the next tick of
+		// the clock is not missed.
+		change := clockChange{clk, make(chan struct{})}
+		select {
+		case j.clocks <- change:
+			<-change.done
+		case <-j.stop:
+		}
+	}
+}
+
+// timeSource return the clock of the cache. The caller must hold the lock,
+// the read lock is enough.
+func (c *Cache) timeSource() Clock {
+	if c.clock == nil {
+		return realClock{}
+	}
+	return c.clock
+}
+
+// now return the time of the clock of the cache. The caller must hold the
+// lock, the read lock is enough.
+func (c *Cache) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+// expiredAt return true if the item is expired at now.
+func (item *Item) expiredAt(now time.Time) bool {
+	return item.Expiration != nil && item.Expiration.Before(now)
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+type fixedClock struct {
+	realClock
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestSetClock(t *testing.T) {
+	clk := &fixedClock{now: time.Now()}
+	c := New(0, time.Hour)
+	defer c.Close()
+	c.SetClock(clk)
+	c.Set("a", 1, time.Minute)
+	c.DeleteSoft("b", time.Minute)
+	clk.now = clk.now.Add(2 * time.Minute)
+	if _, found := c.Get("a"); found {
+		t.Error("The item must be expired by the clock of the cache")
+	}
+	if c.Tombstoned("b") {
+		t.Error("The tombstone must be expired by the clock of the cache")
+	}
+	c.SetClock(nil)
+	if _, found := c.Get("a"); !found {
+		t.Error("Now, the system clock is used again")
+	}
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"sync"
+)
+
+// AdmissionPolicy decide whether a new key may enter a full LRUCache at the
+// cost of evicting the least recently used one.
+type AdmissionPolicy interface {
+	// Record an access to the key, hit or miss.
+	Record(key interface{})
+	// Admit return true if the candidate should replace the victim.
+	Admit(candidate, victim interface{}) bool
+}
+
+// TinyLFU is an AdmissionPolicy estimating the access frequency of keys with
+// a count-min sketch. A new key is only admitted if it was requested more
+// often than the entry it would evict, so keys seen once can not push hot
+// entries out. The counters are halved periodically to forget old history.
+type TinyLFU struct {
+	sync.Mutex
+	rows        [tinyLFUDepth][]uint8
+	mask        uint64
+	additions   int
+	resetPeriod int
+}
+
+const (
+	tinyLFUDepth   = 4
+	tinyLFUMaxFreq = 15
+)
+
+// NewTinyLFU create a TinyLFU with a sketch of width counters per row
+// (rounded up to a power of 2) which halves its counters every resetPeriod
+// recorded accesses. A resetPeriod less than 1 defaults to 10 times the
+// width.
+func NewTinyLFU(width, resetPeriod int) *TinyLFU {
+	size := 16
+	for size < width {
+		size <<= 1
+	}
+	if resetPeriod < 1 {
+		resetPeriod = 10 * size
+	}
+	t := &TinyLFU{
+		mask:        uint64(size - 1),
+		resetPeriod: resetPeriod,
+	}
+	for i := range t.rows {
+		t.rows[i] = make([]uint8, size)
+	}
+	return t
+}
+
+// Record an access to the key.
+func (t *TinyLFU) Record(key interface{}) {
+	h := hashKey(key)
+	t.Lock()
+	for i := range t.rows {
+		idx := t.index(h, i)
+		if t.rows[i][idx] < tinyLFUMaxFreq {
+			t.rows[i][idx]++
+		}
+	}
+	t.additions++
+	if t.additions >= t.resetPeriod {
+		t.reset()
+	}
+	t.Unlock()
+}
+
+// Admit return true if the candidate is estimated to be more frequently
+// accessed than the victim.
+func (t *TinyLFU) Admit(candidate, victim interface{}) bool {
+	t.Lock()
+	defer t.Unlock()
+	return t.estimate(hashKey(candidate)) > t.estimate(hashKey(victim))
+}
+
+// Estimate return the estimated access frequency of the key.
+func (t *TinyLFU) Estimate(key interface{}) int {
+	t.Lock()
+	defer t.Unlock()
+	return int(t.estimate(hashKey(key)))
+}
+
+func (t *TinyLFU) estimate(h uint64) uint8 {
+	min := uint8(tinyLFUMaxFreq)
+	for i := range t.rows {
+		if v := t.rows[i][t.index(h, i)]; v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+func (t *TinyLFU) index(h uint64, row int) uint64 {
+	lo, hi := h&0xffffffff, h>>32
+	return (lo + uint64(row)*hi) & t.mask
+}
+
+func (t *TinyLFU) reset() {
+	for i := range t.rows {
+		for j := range t.rows[i] {
+			t.rows[i][j] >>= 1
+		}
+	}
+	t.additions /= 2
+}
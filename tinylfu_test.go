@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestTinyLFU(t *testing.T) {
+	sketch := NewTinyLFU(64, 0)
+	for i := 0; i < 5; i++ {
+		sketch.Record("hot")
+	}
+	sketch.Record("cold")
+	if sketch.Estimate("hot") < 5 {
+		t.Error("The estimation must not be less than the real frequency")
+	}
+	if !sketch.Admit("hot", "cold") || sketch.Admit("cold", "hot") {
+		t.Error("Only the more frequent key must be admitted")
+	}
+	small := NewTinyLFU(16, 4)
+	for i := 0; i < 4; i++ {
+		small.Record("key")
+	}
+	if small.Estimate("key") != 2 {
+		t.Error("The counters must be halved after the reset period")
+	}
+}
+
+func TestLRUAdmission(t *testing.T) {
+	lru, _ := NewLRU(2)
+	lru.SetAdmissionPolicy(NewTinyLFU(64, 0))
+	lru.Add("1", 111)
+	lru.Add("2", 222)
+	for i := 0; i < 3; i++ {
+		lru.Get("1")
+		lru.Get("2")
+	}
+	for i := 0; i < 10; i++ {
+		lru.Add(i, i)
+	}
+	if _, hit := lru.Get("1"); !hit {
+		t.Error("One-hit keys must not evict the hot key")
+	}
+	if _, hit := lru.Get("2"); !hit {
+		t.Error("One-hit keys must not evict the hot key")
+	}
+}
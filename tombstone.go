@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DeleteSoft delete a key-value pair and leave a tombstone for the key which
+// lasts tombstoneTTL. While the tombstone lasts, Set ignores the key, so a
+// loader which read the origin before the invalidation can not re-populate
+// the cache with a stale value. Use SetForce to write the key anyway.
+func (c *Cache) DeleteSoft(key interface{}, tombstoneTTL time.Duration) {
+	if !c.keyAllowed(key) {
+		return
+	}
+	c.Lock()
+	if c.access(key, OpDelete) != nil {
+		c.Unlock()
+		return
+	}
+	if item, ok := c.items[key]; ok {
+		delete(c.items, key)
+		atomic.AddUint64(&c.stats.deletes, 1)
+		c.removed(key, item)
+	}
+	if tombstoneTTL > 0 {
+		if c.tombstones == nil {
+			c.tombstones = map[interface{}]time.Time{}
+		}
+		c.tombstones[key] = c.now().Add(tombstoneTTL)
+	}
+	c.unlockAndNotify()
+	c.publishInvalidation(key)
+}
+
+// SetForce works like Set, but also removes the tombstone of the key if
+// there is one. It returns the error of the access check, or ErrFull or
+// ErrValueTooLarge if val is not stored, like SetSync.
+func (c *Cache) SetForce(key interface{}, val interface{}, dur time.Duration) error {
+	if !c.keyAllowed(key) {
+		return nil
+	}
+	c.Lock()
+	if err := c.access(key, OpSet); err != nil {
+		c.Unlock()
+		return err
+	}
+	delete(c.tombstones, key)
+	err := c.set(key, val, dur)
+	c.unlockAndNotify()
+	return err
+}
+
+// Tombstoned return true if the key has a tombstone which is not expired.
+func (c *Cache) Tombstoned(key interface{}) bool {
+	if !c.keyAllowed(key) {
+		return false
+	}
+	c.RLock()
+	defer c.RUnlock()
+	if c.access(key, OpGet) != nil {
+		return false
+	}
+	expiration, ok := c.tombstones[key]
+	return ok && !expiration.Before(c.now())
+}
+
+// tombstoned works like Tombstoned for callers holding the lock, and also
+// removes the tombstone if it is expired.
+func (c *Cache) tombstoned(key interface{}) bool {
+	expiration, ok := c.tombstones[key]
+	if !ok {
+		return false
+	}
+	if expiration.Before(c.now()) {
+		delete(c.tombstones, key)
+		return false
+	}
+	return true
+}
+
+func (c *Cache) deleteExpiredTombstones() {
+	now := c.now()
+	for k, expiration := range c.tombstones {
+		if expiration.Before(now) {
+			delete(c.tombstones, k)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeleteSoft(t *testing.T) {
+	c := New(0, 0)
+	c.Set("key", "old", 0)
+	c.DeleteSoft("key", 50*time.Millisecond)
+	if _, found := c.Get("key"); found {
+		t.Error("The key is delete, you should not get")
+	}
+	if !c.Tombstoned("key") {
+		t.Error("The key must have a tombstone")
+	}
+	c.Set("key", "stale", 0)
+	if _, found := c.Get("key"); found {
+		t.Error("Set must not re-add a tombstoned key")
+	}
+	c.SetForce("key", "new", 0)
+	if val, found := c.Get("key"); !found || val != "new" {
+		t.Error("SetForce must write a tombstoned key")
+	}
+	c.DeleteSoft("key", 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	c.Set("key", "fresh", 0)
+	if val, found := c.Get("key"); !found || val != "fresh" {
+		t.Error("The tombstone is expired, Set must work again")
+	}
+	c.DeleteSoft("other", 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	c.DeleteExpired()
+	if len(c.tombstones) != 0 {
+		t.Error("The expired tombstones must be deleted")
+	}
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+)
+
+// TopK approximates the k most frequently requested keys in little space:
+// a count-min sketch estimates the frequency of every key, and a heap keeps
+// the k keys with the highest estimates.
+type TopK struct {
+	sync.Mutex
+	k    int
+	rows [topKDepth][]uint32
+	mask uint64
+	heap topKHeap
+	pos  map[interface{}]int
+}
+
+const topKDepth = 4
+
+// NewTopK create a TopK of k keys, with a sketch of width counters per row
+// (rounded up to a power of 2). A wider sketch overestimates less.
+func NewTopK(k, width int) *TopK {
+	size := 16
+	for size < width {
+		size <<= 1
+	}
+	t := &TopK{
+		k:    k,
+		mask: uint64(size - 1),
+		pos:  map[interface{}]int{},
+	}
+	t.heap.pos = t.pos
+	for i := range t.rows {
+		t.rows[i] = make([]uint32, size)
+	}
+	return t
+}
+
+// Record a request of the key.
+func (t *TopK) Record(key interface{}) {
+	h := hashKey(key)
+	t.Lock()
+	defer t.Unlock()
+	var count uint32
+	for i := range t.rows {
+		idx := t.index(h, i)
+		if t.rows[i][idx] < ^uint32(0) {
+			t.rows[i][idx]++
+		}
+		if v := t.rows[i][idx]; i == 0 || v < count {
+			count = v
+		}
+	}
+	if i, ok := t.pos[key]; ok {
+		t.heap.items[i].Hits = int64(count)
+		heap.Fix(&t.heap, i)
+		return
+	}
+	if t.heap.Len() < t.k {
+		heap.Push(&t.heap, KeyCount{Key: key, Hits: int64(count)})
+		return
+	}
+	if t.k > 0 && int64(count) > t.heap.items[0].Hits {
+		delete(t.pos, t.heap.items[0].Key)
+		t.heap.items[0] = KeyCount{Key: key, Hits: int64(count)}
+		t.pos[key] = 0
+		heap.Fix(&t.heap, 0)
+	}
+}
+
+// Top return the keys with their estimated number of requests, most
+// requested first.
+func (t *TopK) Top() []KeyCount {
+	t.Lock()
+	keys := append([]KeyCount(nil), t.heap.items...)
+	t.Unlock()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Hits > keys[j].Hits })
+	return keys
+}
+
+func (t *TopK) index(h uint64, row int) uint64 {
+	lo, hi := h&0xffffffff, h>>32
+	return (lo + uint64(row)*hi) & t.mask
+}
+
+// topKHeap is a min-heap of keys by hits, which tracks the position of
+// every key in pos.
+type topKHeap struct {
+	items []KeyCount
+	pos   map[interface{}]int
+}
+
+func (h *topKHeap) Len() int           { return len(h.items) }
+func (h *topKHeap) Less(i, j int) bool { return h.items[i].Hits < h.items[j].Hits }
+
+func (h *topKHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.pos[h.items[i].Key] = i
+	h.pos[h.items[j].Key] = j
+}
+
+func (h *topKHeap) Push(x interface{}) {
+	kc := x.(KeyCount)
+	h.pos[kc.Key] = len(h.items)
+	h.items = append(h.items, kc)
+}
+
+func (h *topKHeap) Pop() interface{} {
+	kc := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.pos, kc.Key)
+	return kc
+}
+
+// TrackTopK record the key of every Get of the cache in t, hit or miss, to
+// find the keys worth pre-populating. Set to nil to stop.
+func (c *Cache) TrackTopK(t *TopK) {
+	c.Lock()
+	c.topK = t
+	c.Unlock()
+}
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestTopK(t *testing.T) {
+	c := New(0, 0)
+	topK := NewTopK(2, 1024)
+	c.TrackTopK(topK)
+	c.Set("a", 1, 0)
+	for i := 0; i < 5; i++ {
+		c.Get("missing")
+	}
+	for i := 0; i < 3; i++ {
+		c.Get("a")
+	}
+	for i := 0; i < 100; i++ {
+		c.Get(i)
+	}
+	top := topK.Top()
+	if len(top) != 2 || top[0].Key != "missing" || top[0].Hits != 5 || top[1].Key != "a" {
+		t.Errorf("You get a wrong top %v", top)
+	}
+	c.TrackTopK(nil)
+	c.Get("b")
+	if len(topK.Top()) != 2 {
+		t.Error("Impossiable!")
+	}
+}
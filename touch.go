@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"time"
+)
+
+// Touch change the expiration of a key to dur from now, as Set would,
+// without changing its value. It returns false if the key is not found.
+func (c *Cache) Touch(key interface{}, dur time.Duration) bool {
+	if !c.keyAllowed(key) {
+		return false
+	}
+	c.Lock()
+	defer c.Unlock()
+	item, ok := c.items[key]
+	if !ok || c.expired(item) || c.access(key, OpSet) != nil {
+		return false
+	}
+	item = c.writable(key, item)
+	c.changes++
+	item.Expiration = nil
+	if dur = c.cappedTTL(dur); dur > 0 {
+		t := c.now().Add(dur)
+		item.Expiration = &t
+		c.scheduleExpiry(key, t)
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTouch(t *testing.T) {
+	c := New(time.Hour, 0)
+	c.Set("a", 1, time.Millisecond)
+	if !c.Touch("a", -1) {
+		t.Fatal("The key must be found")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if val, found := c.Get("a"); !found || val != 1 {
+		t.Error("Now, the key never expires")
+	}
+	c.Touch("a", 0)
+	if info, _ := c.GetItemInfo("a"); info.Expiration == nil || info.Expiration.Before(time.Now().Add(59*time.Minute)) {
+		t.Error("The default expiration must be used")
+	}
+	if c.Touch("b", time.Minute) {
+		t.Error("Impossiable!")
+	}
+	f := c.Fork()
+	f.Touch("a", time.Millisecond)
+	if info, _ := c.GetItemInfo("a"); info.Expiration.Before(time.Now().Add(time.Minute)) {
+		t.Error("The parent of a fork must not change")
+	}
+}
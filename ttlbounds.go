@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// SetTTLBounds clamp the durations of the items set from now between min
+// and max, whatever the callers pass: a shorter duration is raised to min,
+// and a longer one, or no expiration, is lowered to max. The items added by
+// Import, Load and the other loaders of saved caches are capped at max too.
+// A bound is 0 means no bound.
+func (c *Cache) SetTTLBounds(min, max time.Duration) error {
+	if min < 0 || max < 0 {
+		return errors.New("The TTL bounds must no less than 0")
+	}
+	if max > 0 && min > max {
+		return errors.New("The min TTL must no greater than the max TTL")
+	}
+	c.Lock()
+	c.minTTL = min
+	c.maxTTL = max
+	c.Unlock()
+	return nil
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetTTLBounds(t *testing.T) {
+	c := New(0, 0)
+	if err := c.SetTTLBounds(time.Minute, time.Second); err == nil {
+		t.Error("Impossiable!")
+	}
+	if err := c.SetTTLBounds(time.Second, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("short", 1, time.Millisecond)
+	c.Set("long", 1, 24*time.Hour)
+	c.Set("never", 1, -1)
+	time.Sleep(2 * time.Millisecond)
+	if _, found := c.Get("short"); !found {
+		t.Error("The duration must be raised to the min TTL")
+	}
+	for _, key := range []string{"long", "never"} {
+		if info, _ := c.GetItemInfo(key); info.Expiration == nil || info.Expiration.After(time.Now().Add(time.Hour)) {
+			t.Errorf("The duration of %s must be lowered to the max TTL", key)
+		}
+	}
+	c.Import([]Entry{{Key: "imported", Value: 1}}, KeepExisting)
+	if info, _ := c.GetItemInfo("imported"); info.Expiration == nil {
+		t.Error("The imported items must be capped")
+	}
+}
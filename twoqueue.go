@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+)
+
+const (
+	// DefaultRecentRatio is the ratio of the TwoQueueCache size dedicated to
+	// the entries which have been accessed only once.
+	DefaultRecentRatio = 0.25
+	// DefaultGhostRatio is the ratio of the TwoQueueCache size used to
+	// remember the keys recently evicted from the recent queue.
+	DefaultGhostRatio = 0.5
+)
+
+// TwoQueueCache is a goroutine-safe 2Q cache. New entries go to a FIFO
+// "recent" queue; entries accessed again, or re-added shortly after being
+// evicted from it, are promoted to a LRU "frequent" queue. A ghost queue
+// keeps the keys evicted from the recent queue to detect them. This makes
+// the cache resistant to scans which would flush a plain LRUCache.
+type TwoQueueCache struct {
+	sync.Mutex
+	maxEntries  int
+	recentRatio float64
+	ghostRatio  float64
+	items       map[interface{}]*list.Element
+	recent      *list.List
+	frequent    *list.List
+	ghosts      map[interface{}]*list.Element
+	ghost       *list.List
+}
+
+type twoQueueEntry struct {
+	key      interface{}
+	value    interface{}
+	frequent bool
+}
+
+// NewTwoQueue create a TwoQueueCache with max size and the default queue
+// ratios.
+func NewTwoQueue(size int) (*TwoQueueCache, error) {
+	return NewTwoQueueParams(size, DefaultRecentRatio, DefaultGhostRatio)
+}
+
+// NewTwoQueueParams create a TwoQueueCache with max size, the ratio of the
+// size used by the recent queue and the ratio of the size used by the ghost
+// queue. The size must be greater than 0 and the ratios between 0 and 1.
+func NewTwoQueueParams(size int, recentRatio, ghostRatio float64) (*TwoQueueCache, error) {
+	if size <= 0 {
+		return nil, errors.New("The size of 2Q Cache must greater than 0")
+	}
+	if recentRatio < 0 || recentRatio > 1 {
+		return nil, errors.New("The recent ratio must between 0 and 1")
+	}
+	if ghostRatio < 0 || ghostRatio > 1 {
+		return nil, errors.New("The ghost ratio must between 0 and 1")
+	}
+	c := &TwoQueueCache{
+		maxEntries:  size,
+		recentRatio: recentRatio,
+		ghostRatio:  ghostRatio,
+		items:       make(map[interface{}]*list.Element, size),
+		recent:      list.New(),
+		frequent:    list.New(),
+		ghosts:      map[interface{}]*list.Element{},
+		ghost:       list.New(),
+	}
+	return c, nil
+}
+
+// Add a new key-value pair to the TwoQueueCache.
+func (c *TwoQueueCache) Add(key interface{}, value interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if ele, hit := c.items[key]; hit {
+		ele.Value.(*twoQueueEntry).value = value
+		c.promote(ele)
+		return
+	}
+	ent := &twoQueueEntry{key: key, value: value}
+	if ele, hit := c.ghosts[key]; hit {
+		c.ghost.Remove(ele)
+		delete(c.ghosts, key)
+		c.ensureSpace(true)
+		ent.frequent = true
+		c.items[key] = c.frequent.PushFront(ent)
+		return
+	}
+	c.ensureSpace(false)
+	c.items[key] = c.recent.PushFront(ent)
+}
+
+// Get a value from the TwoQueueCache. And a bool indicating
+// whether found or not.
+func (c *TwoQueueCache) Get(key interface{}) (interface{}, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if ele, hit := c.items[key]; hit {
+		c.promote(ele)
+		return ele.Value.(*twoQueueEntry).value, true
+	}
+	return nil, false
+}
+
+// Remove a key-value pair in TwoQueueCache. If the key is not existed,
+// nothing will happen.
+func (c *TwoQueueCache) Remove(key interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	if ele, hit := c.items[key]; hit {
+		c.removeElement(ele)
+	}
+	if ele, hit := c.ghosts[key]; hit {
+		c.ghost.Remove(ele)
+		delete(c.ghosts, key)
+	}
+}
+
+// Return the number of key-value pair in TwoQueueCache.
+func (c *TwoQueueCache) Len() int {
+	c.Lock()
+	length := len(c.items)
+	c.Unlock()
+	return length
+}
+
+// Delete all entry in the TwoQueueCache. But the max size will hold.
+func (c *TwoQueueCache) Clear() {
+	c.Lock()
+	c.items = make(map[interface{}]*list.Element, c.maxEntries)
+	c.recent = list.New()
+	c.frequent = list.New()
+	c.ghosts = map[interface{}]*list.Element{}
+	c.ghost = list.New()
+	c.Unlock()
+}
+
+// Resize the max limit. The limit must be greater than 0.
+func (c *TwoQueueCache) SetMaxEntries(max int) error {
+	if max <= 0 {
+		return errors.New("The max limit of entryies must greater than 0")
+	}
+	c.Lock()
+	c.maxEntries = max
+	for len(c.items) > max {
+		c.evict(false)
+	}
+	c.trimGhost()
+	c.Unlock()
+	return nil
+}
+
+func (c *TwoQueueCache) recentSize() int {
+	return int(float64(c.maxEntries) * c.recentRatio)
+}
+
+func (c *TwoQueueCache) ghostSize() int {
+	return int(float64(c.maxEntries) * c.ghostRatio)
+}
+
+// promote move an accessed entry to the front of the frequent queue.
+func (c *TwoQueueCache) promote(ele *list.Element) {
+	ent := ele.Value.(*twoQueueEntry)
+	if ent.frequent {
+		c.frequent.MoveToFront(ele)
+		return
+	}
+	c.recent.Remove(ele)
+	ent.frequent = true
+	c.items[ent.key] = c.frequent.PushFront(ent)
+}
+
+// ensureSpace evict one entry if the cache is full. The ghostHit tells
+// whether the entry to be added was found in the ghost queue.
+func (c *TwoQueueCache) ensureSpace(ghostHit bool) {
+	if len(c.items) < c.maxEntries {
+		return
+	}
+	c.evict(ghostHit)
+}
+
+func (c *TwoQueueCache) evict(ghostHit bool) {
+	recentLen := c.recent.Len()
+	if recentLen > 0 && (recentLen > c.recentSize() || (recentLen == c.recentSize() && !ghostHit) || c.frequent.Len() == 0) {
+		ele := c.recent.Back()
+		key := ele.Value.(*twoQueueEntry).key
+		c.removeElement(ele)
+		c.ghosts[key] = c.ghost.PushFront(key)
+		c.trimGhost()
+		return
+	}
+	if ele := c.frequent.Back(); ele != nil {
+		c.removeElement(ele)
+	}
+}
+
+func (c *TwoQueueCache) trimGhost() {
+	for c.ghost.Len() > c.ghostSize() {
+		ele := c.ghost.Back()
+		c.ghost.Remove(ele)
+		delete(c.ghosts, ele.Value)
+	}
+}
+
+func (c *TwoQueueCache) removeElement(ele *list.Element) {
+	ent := ele.Value.(*twoQueueEntry)
+	if ent.frequent {
+		c.frequent.Remove(ele)
+	} else {
+		c.recent.Remove(ele)
+	}
+	delete(c.items, ent.key)
+}
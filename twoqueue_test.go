@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestTwoQueueCache(t *testing.T) {
+	if _, err := NewTwoQueue(0); err == nil {
+		t.Error("Impossiable!")
+	}
+	if _, err := NewTwoQueueParams(4, 2, 0.5); err == nil {
+		t.Error("The ratio must be checked")
+	}
+	var c BoundedCache
+	c, err := NewTwoQueue(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add("hot", 1)
+	c.Get("hot")
+	// A scan of keys accessed only once must not flush the hot key.
+	for i := 0; i < 100; i++ {
+		c.Add(i, i)
+	}
+	if c.Len() != 4 {
+		t.Error("Now, the len of 2Q cache must be 4")
+	}
+	if val, hit := c.Get("hot"); !hit || val != 1 {
+		t.Error("The frequently used value must be kept")
+	}
+	c.Remove("hot")
+	if _, hit := c.Get("hot"); hit {
+		t.Error("The value must be removed")
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Error("Now, the 2Q cache is cleared")
+	}
+}
+
+func TestTwoQueueGhost(t *testing.T) {
+	c, err := NewTwoQueueParams(4, 0.5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		c.Add(i, i)
+	}
+	if _, hit := c.ghosts[0]; !hit {
+		t.Error("The evicted key must be remembered by the ghost queue")
+	}
+	c.Add(0, 0)
+	if ele, hit := c.items[0]; !hit || !ele.Value.(*twoQueueEntry).frequent {
+		t.Error("A ghost hit must go to the frequent queue")
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Txn is a transaction of Update. It must not be used after Update
+// returns.
+type Txn struct {
+	c      *Cache
+	writes map[interface{}]txnWrite
+	// err is the first error of the access check for a write.
+	err error
+}
+
+type txnWrite struct {
+	val    interface{}
+	dur    time.Duration
+	delete bool
+}
+
+// Get return the value of the key, as written by the transaction if it
+// wrote the key, otherwise as stored in the cache. Unlike Cache.Get, it
+// does not load the key from the Store or count a hit.
+func (tx *Txn) Get(key interface{}) (interface{}, bool) {
+	if !tx.c.keyAllowed(key) || tx.c.access(key, OpGet) != nil {
+		return nil, false
+	}
+	if w, ok := tx.writes[key]; ok {
+		if w.delete {
+			return nil, false
+		}
+		return w.val, true
+	}
+	c := tx.c
+	item, ok := c.items[key]
+	if !ok || c.expired(item) {
+		return nil, false
+	}
+	return c.copyValue(item.Object), true
+}
+
+// Set the value of the key for dur when the transaction is applied, like
+// Cache.Set.
+func (tx *Txn) Set(key interface{}, val interface{}, dur time.Duration) {
+	if !tx.c.keyAllowed(key) || !tx.allowed(key, OpSet) {
+		return
+	}
+	tx.writes[key] = txnWrite{val: val, dur: dur}
+}
+
+// Delete the key when the transaction is applied.
+func (tx *Txn) Delete(key interface{}) {
+	if !tx.c.keyAllowed(key) || !tx.allowed(key, OpDelete) {
+		return
+	}
+	tx.writes[key] = txnWrite{delete: true}
+}
+
+// allowed tell if the access check allows op on the key, keeping its error
+// for Update otherwise.
+func (tx *Txn) allowed(key interface{}, op Op) bool {
+	err := tx.c.access(key, op)
+	if err != nil && tx.err == nil {
+		tx.err = err
+	}
+	return err == nil
+}
+
+// Update call f with a transaction, and apply its writes at once if f
+// returns nil, so related keys, like an index and the keys it lists, stay
+// consistent. The lock of the cache is held during f, which must be short
+// and must not call the methods of the cache. If f returns an error,
+// nothing is written and the error is returned, and so is the error of the
+// access check if it denies a write. If a Set does not fit a
+// full cache, see SetFullPolicy, or a value is too large, see
+// SetMaxValueSize, nothing is written and ErrFull or ErrValueTooLarge is
+// returned. The writes skip the Store and the write-behind queue.
+func (c *Cache) Update(f func(tx *Txn) error) error {
+	c.Lock()
+	tx := &Txn{c: c, writes: map[interface{}]txnWrite{}}
+	err := f(tx)
+	if err == nil {
+		err = tx.err
+	}
+	if err != nil {
+		c.Unlock()
+		return err
+	}
+	for _, w := range tx.writes {
+		if _, err := c.limitValue(w.val); err != nil && !w.delete {
+			c.Unlock()
+			return err
+		}
+	}
+	// The deletes are applied first to make room for the sets, which are
+	// checked to fit before anything is written.
+	var deleted []keyValue
+	for key, w := range tx.writes {
+		if item, ok := c.items[key]; ok && w.delete {
+			deleted = append(deleted, keyValue{key, item})
+			delete(c.items, key)
+		}
+	}
+	if c.maxItems > 0 && (c.fullPolicy == FullReject || c.fullPolicy == FullBlock) {
+		added := 0
+		for key, w := range tx.writes {
+			if _, ok := c.items[key]; !ok && !w.delete && !c.tombstoned(key) {
+				added++
+			}
+		}
+		if len(c.items)+added > c.maxItems {
+			for _, kv := range deleted {
+				c.items[kv.key] = kv.value.(*Item)
+			}
+			c.Unlock()
+			return ErrFull
+		}
+	}
+	for _, kv := range deleted {
+		atomic.AddUint64(&c.stats.deletes, 1)
+		c.removed(kv.key, kv.value.(*Item))
+	}
+	for key, w := range tx.writes {
+		if !w.delete && !c.tombstoned(key) {
+			c.set(key, w.val, w.dur)
+		}
+	}
+	c.unlockAndNotify()
+	// The sets are published by set.
+	for key, w := range tx.writes {
+		if w.delete {
+			c.publishInvalidation(key)
+		}
+	}
+	return nil
+}
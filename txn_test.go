@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	c := New(0, 0)
+	c.Set("index", []string{"a"}, 0)
+	c.Set("a", 1, 0)
+	err := c.Update(func(tx *Txn) error {
+		index, _ := tx.Get("index")
+		tx.Set("b", 2, 0)
+		tx.Set("index", append(index.([]string), "b"), 0)
+		tx.Delete("a")
+		if _, found := tx.Get("a"); found {
+			t.Error("The transaction must see its own delete")
+		}
+		if val, _ := tx.Get("b"); val != 2 {
+			t.Error("The transaction must see its own set")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found := c.Get("a"); found {
+		t.Error("Now, a is deleted")
+	}
+	if index, _ := c.Get("index"); len(index.([]string)) != 2 {
+		t.Error("You get a wrong index", index)
+	}
+
+	fail := errors.New("abort")
+	if err := c.Update(func(tx *Txn) error {
+		tx.Set("c", 3, 0)
+		tx.Delete("b")
+		return fail
+	}); err != fail {
+		t.Error("You get a wrong error", err)
+	}
+	if _, found := c.Get("c"); found {
+		t.Error("The writes of a failed transaction must not be applied")
+	}
+	if _, found := c.Get("b"); !found {
+		t.Error("The deletes of a failed transaction must not be applied")
+	}
+}
+
+func TestUpdateFull(t *testing.T) {
+	c := New(0, 0)
+	c.SetMaxItems(2, 0, EvictNearestExpiry)
+	c.SetFullPolicy(FullReject)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	var evicted []interface{}
+	c.OnEvicted(func(key, value interface{}) {
+		evicted = append(evicted, key)
+	})
+	if err := c.Update(func(tx *Txn) error {
+		tx.Delete("a")
+		tx.Set("b", 3, 0)
+		tx.Set("c", 4, 0)
+		tx.Set("d", 5, 0)
+		return nil
+	}); err != ErrFull {
+		t.Error("You get a wrong error", err)
+	}
+	if val, _ := c.Get("a"); val != 1 || len(evicted) != 0 {
+		t.Error("The delete must be rolled back")
+	}
+	if val, _ := c.Get("b"); val != 2 || c.ItemCount() != 2 {
+		t.Error("The sets must be rolled back", val)
+	}
+	if err := c.Update(func(tx *Txn) error {
+		tx.Delete("a")
+		tx.Set("c", 4, 0)
+		return nil
+	}); err != nil {
+		t.Error("The delete must make room for the set", err)
+	}
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Error("The deleted item must be given to the eviction callback", evicted)
+	}
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// maxDatagram is the largest UDP payload.
+const maxDatagram = 65507
+
+// UDPTransport is a Transport over UDP, for the deployments without a
+// shared broker. Every message is sent to each peer, or once to a multicast
+// group which all the processes join. UDP does not retry, so a message can
+// be lost, and a cache stays stale until the next write or expiration of
+// the key: pair it with a TTL.
+type UDPTransport struct {
+	conn   *net.UDPConn
+	out    *net.UDPConn
+	closed int32
+
+	mu    sync.Mutex
+	peers []*net.UDPAddr
+}
+
+// NewUDPTransport create a UDPTransport listening on addr, like
+// "0.0.0.0:7946", and sending to peers, which can be completed with
+// AddPeer.
+func NewUDPTransport(addr string, peers ...string) (*UDPTransport, error) {
+	laddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", laddr)
+	if err != nil {
+		return nil, err
+	}
+	t := &UDPTransport{conn: conn, out: conn}
+	for _, peer := range peers {
+		if err := t.AddPeer(peer); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// NewMulticastTransport create a UDPTransport joining the multicast group,
+// like "239.0.0.1:7946", on the network interface ifi, or on the default
+// one if ifi is nil.
+func NewMulticastTransport(group string, ifi *net.Interface) (*UDPTransport, error) {
+	gaddr, err := net.ResolveUDPAddr("udp", group)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenMulticastUDP("udp", ifi, gaddr)
+	if err != nil {
+		return nil, err
+	}
+	out, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &UDPTransport{conn: conn, out: out, peers: []*net.UDPAddr{gaddr}}, nil
+}
+
+// Addr return the address the transport listens on.
+func (t *UDPTransport) Addr() net.Addr {
+	return t.conn.LocalAddr()
+}
+
+// AddPeer add the address of a process to send the messages to.
+func (t *UDPTransport) AddPeer(addr string) error {
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.peers = append(t.peers, raddr)
+	t.mu.Unlock()
+	return nil
+}
+
+// Publish send the message to every peer, and return the first error.
+func (t *UDPTransport) Publish(msg []byte) error {
+	t.mu.Lock()
+	peers := t.peers
+	t.mu.Unlock()
+	var err error
+	for _, peer := range peers {
+		if _, e := t.out.WriteToUDP(msg, peer); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Subscribe call f with the messages received until Close.
+func (t *UDPTransport) Subscribe(f func(msg []byte)) error {
+	buf := make([]byte, maxDatagram)
+	for {
+		n, _, err := t.conn.ReadFromUDP(buf)
+		if err != nil {
+			if atomic.LoadInt32(&t.closed) == 1 {
+				return nil
+			}
+			return err
+		}
+		f(append([]byte(nil), buf[:n]...))
+	}
+}
+
+// Close the sockets of the transport.
+func (t *UDPTransport) Close() error {
+	atomic.StoreInt32(&t.closed, 1)
+	err := t.conn.Close()
+	if t.out != t.conn {
+		t.out.Close()
+	}
+	return err
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUDPTransport(t *testing.T) {
+	ta, err := NewUDPTransport("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tb, err := NewUDPTransport("127.0.0.1:0", ta.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ta.AddPeer(tb.Addr().String())
+	a, b := New(0, 0), New(0, 0)
+	busA, _ := NewInvalidationBus(a, ta)
+	busB, _ := NewInvalidationBus(b, tb)
+	b.Set("k", "old", 0)
+	time.Sleep(10 * time.Millisecond)
+	a.Set("k", "new", 0)
+	time.Sleep(10 * time.Millisecond)
+	if _, found := b.Get("k"); found {
+		t.Error("The key set by a peer must be deleted")
+	}
+	if _, found := a.Get("k"); !found {
+		t.Error("The publisher must keep its value")
+	}
+	for _, bus := range []*InvalidationBus{busA, busB} {
+		if err := bus.Close(); err != nil || bus.LastError() != nil {
+			t.Error(err, bus.LastError())
+		}
+	}
+}
+
+func TestMulticastTransport(t *testing.T) {
+	tr, err := NewMulticastTransport("239.0.0.1:0", nil)
+	if err != nil {
+		t.Skip("Multicast is not available:", err)
+	}
+	tr.Close()
+}
@@ -17,6 +17,57 @@ type EXPLRUCache interface {
 	DumpKeys() []interface{}
 }
 
+// Interface is the common interface of Cache and LRUCache, to write code
+// generic over them. See NewFromConfig.
+type Interface interface {
+	EXPLRUCache
+	// Set add a new key or replace an exist key. The dur is ignored by
+	// LRUCache, whose entries never expire.
+	Set(key interface{}, val interface{}, dur time.Duration)
+	Delete(key interface{})
+	// Len return the number of entries, expired ones included.
+	Len() int
+	Flush()
+	// Range call f for every entry which is not expired, until f returns
+	// false. The order is random for Cache, and the most recently used
+	// first for LRUCache, which Range does not reorder. f may delete the
+	// entries.
+	Range(f func(key, value interface{}) bool)
+}
+
+var (
+	_ Interface = (*Cache)(nil)
+	_ Interface = (*LRUCache)(nil)
+)
+
+// Config select and configure an implementation of Interface.
+type Config struct {
+	// Policy is "cache" for a Cache, or "lru" for a LRUCache.
+	Policy string
+	// Size is the max number of entries of the LRUCache, 0 means no limit.
+	Size int
+	// DefaultExpiration and CleanupInterval are the arguments of New for
+	// the Cache.
+	DefaultExpiration time.Duration
+	CleanupInterval   time.Duration
+}
+
+// NewFromConfig create the cache selected by cfg.
+func NewFromConfig(cfg Config) (Interface, error) {
+	switch cfg.Policy {
+	case "cache":
+		return New(cfg.DefaultExpiration, cfg.CleanupInterval), nil
+	case "lru":
+		// A nil *LRUCache must not be returned as a non-nil Interface.
+		c, err := NewLRU(cfg.Size)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("Unknown policy %s", cfg.Policy)
+}
+
 // Cache is a goroutine-safe K/V cache.
 type Cache struct {
 	items             map[interface{}]*Item
@@ -179,6 +230,20 @@ func (c *Cache) ItemCount() int {
 	return counts
 }
 
+// Len is ItemCount.
+func (c *Cache) Len() int {
+	return c.ItemCount()
+}
+
+// Range call f for every item which is not expired, until f returns false.
+func (c *Cache) Range(f func(key, value interface{}) bool) {
+	for k, v := range c.items {
+		if !v.Expired() && !f(k, v.Object) {
+			return
+		}
+	}
+}
+
 // Delete all expired items.
 func (c *Cache) DeleteExpired() {
 	for k, v := range c.items {
@@ -241,6 +306,11 @@ func (c *LRUCache) Add(key interface{}, value interface{}) {
 	}
 }
 
+// Set is Add, the dur is ignored.
+func (c *LRUCache) Set(key interface{}, val interface{}, dur time.Duration) {
+	c.Add(key, val)
+}
+
 // Get a value from the LRUCache. And a bool indicating
 // whether found or not.
 func (c *LRUCache) Get(key interface{}) (interface{}, bool) {
@@ -259,6 +329,11 @@ func (c *LRUCache) Remove(key interface{}) {
 	}
 }
 
+// Delete is Remove.
+func (c *LRUCache) Delete(key interface{}) {
+	c.Remove(key)
+}
+
 // Return the number of key-value pair in LRUCache.
 func (c *LRUCache) Len() int {
 	length := c.cacheList.Len()
@@ -271,6 +346,24 @@ func (c *LRUCache) Clear() {
 	c.items = make(map[interface{}]*list.Element, c.maxEntries)
 }
 
+// Flush is Clear.
+func (c *LRUCache) Flush() {
+	c.Clear()
+}
+
+// Range call f for every entry, the most recently used first, until f
+// returns false. It does not change the order of the entries.
+func (c *LRUCache) Range(f func(key, value interface{}) bool) {
+	for e := c.cacheList.Front(); e != nil; {
+		next := e.Next()
+		ent := e.Value.(*entry)
+		if !f(ent.key, ent.value) {
+			return
+		}
+		e = next
+	}
+}
+
 // Resize the max limit.
 func (c *LRUCache) SetMaxEntries(max int) error {
 	if max < 0 {
@@ -221,3 +221,39 @@ func ExampleLRUCache() {
 	// Output:
 	// Not hit key 1
 }
+
+func TestInterface(t *testing.T) {
+	for _, policy := range []string{"cache", "lru"} {
+		c, err := NewFromConfig(Config{Policy: policy, Size: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Set("a", 1, 0)
+		c.Set("b", 2, 0)
+		c.Set("c", 3, 0)
+		c.Delete("c")
+		if c.Len() != 2 {
+			t.Errorf("Now, the %s cache must hold 2 entries", policy)
+		}
+		sum := 0
+		c.Range(func(key, value interface{}) bool {
+			sum += value.(int)
+			c.Delete(key)
+			return true
+		})
+		if sum != 3 || c.Len() != 0 {
+			t.Errorf("Range of the %s cache must visit every entry", policy)
+		}
+		c.Set("a", 1, 0)
+		c.Flush()
+		if _, found := c.Get("a"); found {
+			t.Error("Impossiable!")
+		}
+	}
+	if _, err := NewFromConfig(Config{Policy: "nope"}); err == nil {
+		t.Error("Impossiable!")
+	}
+	if c, err := NewFromConfig(Config{Policy: "lru", Size: -1}); err == nil || c != nil {
+		t.Error("The cache must be nil with the error", c)
+	}
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrValueTooLarge is returned when a value is larger than the limit of
+// SetMaxValueSize.
+var ErrValueTooLarge = errors.New("The value is too large")
+
+// Sizer is implemented by the values which know their size in bytes, for
+// SetMaxValueSize. The size of the other values is their length for a
+// string or a []byte, and estimated by following their pointers otherwise,
+// which is slow for large values.
+type Sizer interface {
+	Size() int64
+}
+
+// ValueSizePolicy tells what the cache does with a value larger than the
+// limit of SetMaxValueSize.
+type ValueSizePolicy int
+
+const (
+	// ValueSizeReject rejects the value with ErrValueTooLarge.
+	ValueSizeReject ValueSizePolicy = iota
+	// ValueSizeTruncate stores a copy of the first bytes of a string or a
+	// []byte, and rejects the other values like ValueSizeReject.
+	ValueSizeTruncate
+)
+
+// SetMaxValueSize limit the size of the values set in the cache to max
+// bytes, so a single large value can not blow the memory. The larger values
+// are handled by policy: Set ignores them and SetSync returns
+// ErrValueTooLarge, and the old value of the key, if any, is kept. With a
+// WritableStore, the value is saved in the store before it is rejected. The
+// values stored before are not checked. The max is 0 means no limit, which
+// is the default.
+func (c *Cache) SetMaxValueSize(max int64, policy ValueSizePolicy) {
+	c.Lock()
+	c.maxValueSize = max
+	c.valueSizePolicy = policy
+	c.Unlock()
+}
+
+// limitValue return val, or a truncated copy of it, if it fits in the max
+// value size, or ErrValueTooLarge. The caller must hold the lock.
+func (c *Cache) limitValue(val interface{}) (interface{}, error) {
+	if c.maxValueSize <= 0 || valueSize(val) <= c.maxValueSize {
+		return val, nil
+	}
+	if c.valueSizePolicy == ValueSizeTruncate {
+		switch v := val.(type) {
+		case string:
+			return strings.Clone(v[:c.maxValueSize]), nil
+		case []byte:
+			b := make([]byte, c.maxValueSize)
+			copy(b, v)
+			return b, nil
+		}
+	}
+	return nil, ErrValueTooLarge
+}
+
+// valueSize return the size of v in bytes, see Sizer.
+func valueSize(v interface{}) int64 {
+	switch v := v.(type) {
+	case Sizer:
+		return v.Size()
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	}
+	return estimateSize(v)
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"testing"
+)
+
+type sizedValue int64
+
+func (v sizedValue) Size() int64 {
+	return int64(v)
+}
+
+func TestSetMaxValueSize(t *testing.T) {
+	c := New(0, 0)
+	c.SetMaxValueSize(4, ValueSizeReject)
+	c.Set("a", "abc", 0)
+	if err := c.SetSync("a", "abcdef", 0); err != ErrValueTooLarge {
+		t.Error("You get a wrong error", err)
+	}
+	if val, _ := c.Get("a"); val != "abc" {
+		t.Error("The old value must be kept", val)
+	}
+	if err := c.SetSync("s", sizedValue(5), 0); err != ErrValueTooLarge {
+		t.Error("The size of a Sizer must be used", err)
+	}
+	if err := c.SetSync("m", map[string]string{"key": "value"}, 0); err != ErrValueTooLarge {
+		t.Error("The size of the other values must be estimated", err)
+	}
+
+	c.SetMaxValueSize(4, ValueSizeTruncate)
+	c.Set("b", []byte("abcdef"), 0)
+	if val, _ := c.Get("b"); string(val.([]byte)) != "abcd" || cap(val.([]byte)) != 4 {
+		t.Error("The value must be truncated", val)
+	}
+	c.Set("c", "abcdef", 0)
+	if val, _ := c.Get("c"); val != "abcd" {
+		t.Error("The value must be truncated", val)
+	}
+	if err := c.SetSync("s", sizedValue(5), 0); err != ErrValueTooLarge {
+		t.Error("Only strings and bytes are truncated", err)
+	}
+
+	c.SetMaxValueSize(0, ValueSizeReject)
+	if err := c.SetSync("a", "abcdef", 0); err != nil {
+		t.Error("Now, there is no limit", err)
+	}
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrVersionMismatch is returned by SetIfVersion when the value of the key
+// was written after the version was read.
+var ErrVersionMismatch = errors.New("The version of the key has changed")
+
+// nextVersion return a new version, greater than all the versions of the
+// cache, for a write of an item. The caller must hold the lock.
+func (c *Cache) nextVersion() uint64 {
+	c.changes++
+	c.versionSeq++
+	return c.versionSeq
+}
+
+// GetWithVersion works like Get, without the Store, but also returns the
+// version of the value. Every write of a key, by Set, Increment or the
+// other writes, gives it a new version, greater than the previous ones of
+// the cache. Pass the version to SetIfVersion for an optimistic update.
+func (c *Cache) GetWithVersion(key interface{}) (interface{}, uint64, bool) {
+	if !c.keyAllowed(key) {
+		return nil, 0, false
+	}
+	c.RLock()
+	defer c.RUnlock()
+	item, ok := c.items[key]
+	if !ok || c.expired(item) || c.access(key, OpGet) != nil {
+		return nil, 0, false
+	}
+	c.recordHit(item)
+	return c.copyValue(item.Object), item.version, true
+}
+
+// SetIfVersion works like Set, but only if the version of the key is still
+// version, as returned by GetWithVersion, and returns ErrVersionMismatch
+// otherwise. The version 0 means the key must not be found. The Store is
+// not written.
+func (c *Cache) SetIfVersion(key interface{}, val interface{}, dur time.Duration, version uint64) error {
+	if !c.keyAllowed(key) {
+		return nil
+	}
+	c.Lock()
+	defer c.unlockAndNotify()
+	if err := c.access(key, OpSet); err != nil {
+		return err
+	}
+	var current uint64
+	if item, ok := c.items[key]; ok && !c.expired(item) {
+		current = item.version
+	}
+	if current != version {
+		return ErrVersionMismatch
+	}
+	if c.tombstoned(key) {
+		return nil
+	}
+	return c.set(key, val, dur)
+}
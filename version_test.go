@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	c := New(0, 0)
+	if err := c.SetIfVersion("a", 1, 0, 0); err != nil {
+		t.Error("A missing key must match the version 0", err)
+	}
+	val, v1, found := c.GetWithVersion("a")
+	if !found || val != 1 || v1 == 0 {
+		t.Error("You get a wrong value", val, v1)
+	}
+	c.Increment("a", 1)
+	_, v2, _ := c.GetWithVersion("a")
+	if v2 <= v1 {
+		t.Error("Increment must give a new version")
+	}
+	if err := c.SetIfVersion("a", 10, 0, v1); err != ErrVersionMismatch {
+		t.Error("You get a wrong error", err)
+	}
+	if err := c.SetIfVersion("a", 10, 0, v2); err != nil {
+		t.Error("The version must match", err)
+	}
+	if info, _ := c.GetItemInfo("a"); info.Value != 10 || info.Version <= v2 {
+		t.Error("You get a wrong item", info)
+	}
+	if err := c.SetIfVersion("a", 11, 0, 0); err != ErrVersionMismatch {
+		t.Error("Impossiable!")
+	}
+}
@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"context"
+	"encoding/gob"
+	"io"
+	"sync"
+	"time"
+)
+
+// WarmSource produce the entries preloaded by Warm.
+type WarmSource interface {
+	// Next return the next key to preload, and false when there is none
+	// left. It is called by one goroutine at a time.
+	Next(ctx context.Context) (key interface{}, ok bool, err error)
+	// Load return the value of a key and how long to cache it, as the dur
+	// of Set. It is called by several goroutines at once, see
+	// WarmOptions. The key is skipped if it returns an error.
+	Load(ctx context.Context, key interface{}) (value interface{}, ttl time.Duration, err error)
+}
+
+// WarmOptions configure Warm.
+type WarmOptions struct {
+	// Concurrency is the number of Load of the source running at once, 1
+	// if less, to bound the load of a backend.
+	Concurrency int
+	// Progress is called after every key with the progress so far, one
+	// call at a time, if it is not nil.
+	Progress func(WarmProgress)
+}
+
+// WarmProgress count the keys done by Warm.
+type WarmProgress struct {
+	// Loaded is the number of values stored in the cache.
+	Loaded int
+	// Skipped is the number of keys already in the cache, or not allowed.
+	Skipped int
+	// Failed is the number of keys whose Load returned an error.
+	Failed int
+}
+
+// Warm preload the keys of source in the cache before it takes traffic, so
+// a cold start does not send all the misses to the backend at once. The
+// keys already in the cache are not replaced. It stops at the first error
+// of Next, or when ctx is done, and returns the error with the progress so
+// far. See WarmKeys and SnapshotSource.
+func (c *Cache) Warm(ctx context.Context, source WarmSource, opts WarmOptions) (WarmProgress, error) {
+	workers := opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	var (
+		mu       sync.Mutex
+		progress WarmProgress
+		wg       sync.WaitGroup
+	)
+	keys := make(chan interface{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				val, ttl, err := source.Load(ctx, key)
+				stored := err == nil && c.warmSet(key, val, ttl)
+				mu.Lock()
+				switch {
+				case err != nil:
+					progress.Failed++
+				case stored:
+					progress.Loaded++
+				default:
+					progress.Skipped++
+				}
+				if opts.Progress != nil {
+					opts.Progress(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	err := c.warmKeys(ctx, source, keys)
+	close(keys)
+	wg.Wait()
+	return progress, err
+}
+
+// warmKeys send the keys of source to the workers of Warm.
+func (c *Cache) warmKeys(ctx context.Context, source WarmSource, keys chan<- interface{}) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		key, ok, err := source.Next(ctx)
+		if err != nil || !ok {
+			return err
+		}
+		select {
+		case keys <- key:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// warmSet store a value loaded by Warm unless the key is in the cache, and
+// return true if it did.
+func (c *Cache) warmSet(key interface{}, val interface{}, ttl time.Duration) bool {
+	if !c.keyAllowed(key) {
+		return false
+	}
+	c.Lock()
+	if item, ok := c.items[key]; ok && !c.expired(item) || c.access(key, OpSet) != nil || c.tombstoned(key) {
+		c.Unlock()
+		return false
+	}
+	err := c.fill(key, val, ttl)
+	c.unlockAndNotify()
+	return err == nil
+}
+
+// WarmKeys return a WarmSource preloading keys from s, with its
+// LoadContext if it is a ContextStore.
+func WarmKeys(keys []interface{}, s Store) WarmSource {
+	return &keysSource{keys: keys, store: s}
+}
+
+type keysSource struct {
+	keys  []interface{}
+	store Store
+}
+
+func (s *keysSource) Next(ctx context.Context) (interface{}, bool, error) {
+	if len(s.keys) == 0 {
+		return nil, false, nil
+	}
+	key := s.keys[0]
+	s.keys = s.keys[1:]
+	return key, true, nil
+}
+
+func (s *keysSource) Load(ctx context.Context, key interface{}) (interface{}, time.Duration, error) {
+	if cs, ok := s.store.(ContextStore); ok {
+		return cs.LoadContext(ctx, key)
+	}
+	return s.store.Load(key)
+}
+
+// SnapshotSource return a WarmSource preloading the items written by Save
+// to r, with the rest of their expiration. The items already expired are
+// skipped. It reads all the items at once.
+func SnapshotSource(r io.Reader) (WarmSource, error) {
+	items := map[interface{}]*Item{}
+	if err := gob.NewDecoder(r).Decode(&items); err != nil {
+		return nil, err
+	}
+	s := &snapshotSource{items: items}
+	for k := range items {
+		s.keys = append(s.keys, k)
+	}
+	return s, nil
+}
+
+type snapshotSource struct {
+	keys  []interface{}
+	items map[interface{}]*Item
+}
+
+func (s *snapshotSource) Next(ctx context.Context) (interface{}, bool, error) {
+	for len(s.keys) > 0 {
+		key := s.keys[0]
+		s.keys = s.keys[1:]
+		if !s.items[key].expiredAt(time.Now()) {
+			return key, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (s *snapshotSource) Load(ctx context.Context, key interface{}) (interface{}, time.Duration, error) {
+	item := s.items[key]
+	if item.Expiration == nil {
+		return item.Object, -1, nil
+	}
+	ttl := time.Until(*item.Expiration)
+	if ttl <= 0 {
+		return nil, 0, ErrNotFound
+	}
+	return item.Object, ttl, nil
+}
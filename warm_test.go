@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowStore track the number of Load running at once.
+type slowStore struct {
+	running int32
+	max     int32
+}
+
+func (s *slowStore) Load(key interface{}) (interface{}, time.Duration, error) {
+	n := atomic.AddInt32(&s.running, 1)
+	defer atomic.AddInt32(&s.running, -1)
+	for {
+		max := atomic.LoadInt32(&s.max)
+		if n <= max || atomic.CompareAndSwapInt32(&s.max, max, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	if key == "bad" {
+		return nil, 0, errors.New("The backend is down")
+	}
+	return key, 0, nil
+}
+
+func TestWarm(t *testing.T) {
+	c := New(0, 0)
+	c.Set("k0", "already", 0)
+	keys := []interface{}{"bad"}
+	for i := 0; i < 20; i++ {
+		keys = append(keys, "k"+string(rune('a'+i)))
+	}
+	keys = append(keys, "k0")
+	s := &slowStore{}
+	calls := 0
+	p, err := c.Warm(context.Background(), WarmKeys(keys, s), WarmOptions{
+		Concurrency: 4,
+		Progress:    func(WarmProgress) { calls++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Loaded != 20 || p.Failed != 1 || p.Skipped != 1 || calls != 22 {
+		t.Errorf("You get a wrong progress: %+v %d", p, calls)
+	}
+	if s.max > 4 || s.max < 2 {
+		t.Error("The loads must run concurrently up to the limit", s.max)
+	}
+	if val, _ := c.Get("k0"); val != "already" {
+		t.Error("The existing keys must not be replaced")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New(0, 0).Warm(ctx, WarmKeys(keys, s), WarmOptions{}); err != context.Canceled {
+		t.Error("You get a wrong error", err)
+	}
+}
+
+func TestSnapshotSource(t *testing.T) {
+	c := New(0, 0)
+	c.Set("a", 1, time.Hour)
+	c.Set("b", 2, -1)
+	c.Set("c", 3, time.Nanosecond)
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	source, err := SnapshotSource(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2 := New(0, 0)
+	if p, _ := c2.Warm(context.Background(), source, WarmOptions{}); p.Loaded != 2 {
+		t.Errorf("You get a wrong progress: %+v", p)
+	}
+	if info, _ := c2.GetItemInfo("a"); info.Expiration == nil || info.Value != 1 {
+		t.Error("The expiration must be kept", info)
+	}
+	if _, found := c2.Get("c"); found {
+		t.Error("The expired items must be skipped")
+	}
+}
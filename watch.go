@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType is the type of an Event of Watch.
+type EventType int
+
+const (
+	// EventSet is a write of the key, by Set, Increment or the other
+	// writes.
+	EventSet EventType = iota
+	// EventDelete is a removal of the key by Delete, Flush or the other
+	// removals which are not an expiration or an eviction.
+	EventDelete
+	// EventExpire is a removal of the expired key by the cleanup, see
+	// DeleteExpired.
+	EventExpire
+	// EventEvict is a removal of the key to make room in a full cache, see
+	// SetMaxItems.
+	EventEvict
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	case EventExpire:
+		return "expire"
+	case EventEvict:
+		return "evict"
+	}
+	return "unknown"
+}
+
+// Event is a change of a watched key.
+type Event struct {
+	Type  EventType
+	Key   interface{}
+	Value interface{}
+	At    time.Time
+}
+
+// WatchBuffer is the number of the events of a key buffered for a watcher
+// which does not receive them.
+const WatchBuffer = 64
+
+type watcher struct {
+	ch chan Event
+}
+
+// Watch return a channel receiving the changes of the key, and a function
+// to cancel the watch, which closes the channel. The events are sent
+// without blocking the cache: when WatchBuffer events wait for a slow
+// receiver, the oldest one is dropped for the new one, so the receiver
+// always gets the latest change.
+func (c *Cache) Watch(key interface{}) (<-chan Event, func()) {
+	w := &watcher{ch: make(chan Event, WatchBuffer)}
+	if !c.keyAllowed(key) {
+		close(w.ch)
+		return w.ch, func() {}
+	}
+	c.Lock()
+	if c.access(key, OpGet) != nil {
+		c.Unlock()
+		close(w.ch)
+		return w.ch, func() {}
+	}
+	if c.watchers == nil {
+		c.watchers = map[interface{}][]*watcher{}
+	}
+	c.watchers[key] = append(c.watchers[key], w)
+	c.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			c.Lock()
+			ws := c.watchers[key]
+			for i, other := range ws {
+				if other == w {
+					ws = append(ws[:i:i], ws[i+1:]...)
+					break
+				}
+			}
+			if len(ws) == 0 {
+				delete(c.watchers, key)
+			} else {
+				c.watchers[key] = ws
+			}
+			close(w.ch)
+			c.Unlock()
+		})
+	}
+	return w.ch, cancel
+}
+
+// watchEvent send an event to the watchers of the key. The caller must
+// hold the lock.
+func (c *Cache) watchEvent(typ EventType, key interface{}, value interface{}) {
+	if len(c.watchers) == 0 {
+		return
+	}
+	ws := c.watchers[key]
+	if len(ws) == 0 {
+		return
+	}
+	ev := Event{Type: typ, Key: key, Value: c.copyValue(value), At: c.now()}
+	for _, w := range ws {
+		select {
+		case w.ch <- ev:
+			continue
+		default:
+		}
+		// Drop the oldest event, unless the receiver took it meanwhile.
+		// The events are only sent under the lock, so there is room now.
+		select {
+		case <-w.ch:
+		default:
+		}
+		w.ch <- ev
+	}
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	c := New(0, 0)
+	events, cancel := c.Watch("a")
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Increment("a", 1)
+	c.Delete("a")
+	c.Set("a", 3, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	c.DeleteExpired()
+	want := []Event{{Type: EventSet, Value: 1}, {Type: EventSet, Value: 2}, {Type: EventDelete, Value: 2}, {Type: EventSet, Value: 3}, {Type: EventExpire, Value: 3}}
+	for _, w := range want {
+		ev := <-events
+		if ev.Type != w.Type || ev.Key != "a" || ev.Value != w.Value {
+			t.Errorf("You get a wrong event %+v, not %+v", ev, w)
+		}
+	}
+	cancel()
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("The channel must be closed by cancel")
+	}
+	c.Set("a", 4, 0)
+}
+
+func TestWatchDropOldest(t *testing.T) {
+	c := New(0, 0)
+	events, cancel := c.Watch("a")
+	defer cancel()
+	for i := 0; i < WatchBuffer+10; i++ {
+		c.Set("a", i, 0)
+	}
+	if ev := <-events; ev.Value != 10 {
+		t.Error("The oldest events must be dropped", ev.Value)
+	}
+	for i := 0; i < WatchBuffer-2; i++ {
+		<-events
+	}
+	if ev := <-events; ev.Value != WatchBuffer+9 {
+		t.Error("The latest event must be kept", ev.Value)
+	}
+}
@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// The timing wheel has wheelLevels levels of wheelSlots slots. A slot of
+// the first level lasts a tick, and a slot of the next levels lasts a whole
+// turn of the previous one.
+const (
+	wheelBits   = 6
+	wheelSlots  = 1 << wheelBits
+	wheelLevels = 4
+)
+
+// timingWheel schedule the keys of a cache at the tick of their expiration,
+// so the due keys are found without scanning the others. The keys which
+// were deleted or set again are not removed from the wheel, but skipped
+// when they are due.
+type timingWheel struct {
+	tick  time.Duration
+	start time.Time
+	// cur is the number of ticks done since start.
+	cur int64
+	// slots map the keys to their due tick, and pos tells in which slot a
+	// key is, to move it when it is scheduled again.
+	slots [wheelLevels][wheelSlots]map[interface{}]int64
+	pos   map[interface{}]*map[interface{}]int64
+}
+
+func newTimingWheel(tick time.Duration, start time.Time) *timingWheel {
+	return &timingWheel{
+		tick:  tick,
+		start: start,
+		pos:   map[interface{}]*map[interface{}]int64{},
+	}
+}
+
+// tickOf return the first tick at or after t.
+func (w *timingWheel) tickOf(t time.Time) int64 {
+	d := t.Sub(w.start)
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + w.tick - 1) / w.tick)
+}
+
+// schedule the key at the tick of t, or at the next tick if it is past.
+func (w *timingWheel) schedule(key interface{}, t time.Time) {
+	w.add(key, w.tickOf(t))
+}
+
+func (w *timingWheel) add(key interface{}, due int64) {
+	if slot, ok := w.pos[key]; ok {
+		delete(*slot, key)
+	}
+	if due <= w.cur {
+		due = w.cur + 1
+	}
+	level := 0
+	for level < wheelLevels-1 && due-w.cur >= 1<<(wheelBits*(level+1)) {
+		level++
+	}
+	slot := &w.slots[level][(due>>(wheelBits*level))&(wheelSlots-1)]
+	if *slot == nil {
+		*slot = map[interface{}]int64{}
+	}
+	(*slot)[key] = due
+	w.pos[key] = slot
+}
+
+// advance move the wheel to the tick of now, and return the keys which are
+// due. Every turn of a level moves the keys of the next slot of the next
+// level down, so a key is moved at most wheelLevels times.
+func (w *timingWheel) advance(now time.Time) []interface{} {
+	end := int64(now.Sub(w.start) / w.tick)
+	var due []interface{}
+	for w.cur < end {
+		w.cur++
+		for level := 1; level < wheelLevels && w.cur&(1<<(wheelBits*level)-1) == 0; level++ {
+			slot := &w.slots[level][(w.cur>>(wheelBits*level))&(wheelSlots-1)]
+			keys := *slot
+			*slot = nil
+			for key, t := range keys {
+				delete(w.pos, key)
+				w.add(key, t)
+			}
+		}
+		slot := &w.slots[0][w.cur&(wheelSlots-1)]
+		keys := *slot
+		*slot = nil
+		for key, t := range keys {
+			delete(w.pos, key)
+			if t <= w.cur {
+				due = append(due, key)
+			} else {
+				// It was beyond the last level.
+				w.add(key, t)
+			}
+		}
+	}
+	return due
+}
+
+// SetTimingWheel make the janitor delete the expired items with a
+// hierarchical timing wheel instead of scanning all the items, for the
+// caches with many short-lived items. The janitor runs every tick, which is
+// the precision of the expiration, and costs only the items which are due.
+// Every write of an item with an expiration costs a little more. The
+// cleanup budget does not apply, see SetCleanupBudget. The tick is 0 turns
+// the wheel off, but does not change the cleanup interval back, see
+// SetCleanupInterval. Set the clock of the cache before, see SetClock.
+func (c *Cache) SetTimingWheel(tick time.Duration) error {
+	if tick < 0 {
+		return errors.New("The tick of the timing wheel must no less than 0")
+	}
+	c.Lock()
+	if tick == 0 {
+		c.wheel = nil
+		c.Unlock()
+		return nil
+	}
+	c.wheel = newTimingWheel(tick, c.now())
+	for k, v := range c.items {
+		if v.Expiration != nil {
+			c.wheel.schedule(k, *v.Expiration)
+		}
+	}
+	c.Unlock()
+	return c.SetCleanupInterval(tick)
+}
+
+// cleanup run a cleanup of the janitor.
+func (c *Cache) cleanup() {
+	if !c.advanceWheel() {
+		c.DeleteExpiredIncremental()
+	}
+}
+
+// advanceWheel delete the items due in the timing wheel, and return false
+// if there is no wheel.
+func (c *Cache) advanceWheel() bool {
+	c.Lock()
+	w := c.wheel
+	if w == nil {
+		c.Unlock()
+		return false
+	}
+	var expired []Expiration
+	now := c.now()
+	for _, k := range w.advance(now) {
+		v, ok := c.items[k]
+		if !ok || v.Expiration == nil || c.deleteIfExpired(k, v, &expired) {
+			continue
+		}
+		// The item is stale, see SetStaleWindow, or was not expired
+		// yet by the clock, or the cache is degraded.
+		next := v.Expiration.Add(c.staleWindow)
+		if min := now.Add(w.tick); next.Before(min) {
+			next = min
+		}
+		w.schedule(k, next)
+	}
+	c.deleteExpiredTombstones()
+	c.notifyExpired(expired)
+	c.unlockAndNotify()
+	return true
+}
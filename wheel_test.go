@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestTimingWheel(t *testing.T) {
+	start := time.Unix(0, 0)
+	w := newTimingWheel(time.Millisecond, start)
+	due := map[int]int64{}
+	for i := 0; i < 10000; i++ {
+		d := rand.Int63n(1 << 20)
+		if i%100 == 0 {
+			d = rand.Int63n(1 << 26)
+		}
+		due[i] = d
+		w.schedule(i, start.Add(time.Duration(d)*time.Millisecond))
+	}
+	w.schedule(0, start.Add(5*time.Millisecond))
+	due[0] = 5
+	fired := 0
+	for tick := int64(0); fired < len(due); tick += 1 + rand.Int63n(2000) {
+		for _, key := range w.advance(start.Add(time.Duration(tick) * time.Millisecond)) {
+			d := due[key.(int)]
+			if d < 1 {
+				d = 1
+			}
+			if d > tick || tick-d > 2000 {
+				t.Fatal("The key is due at a wrong tick", key, d, tick)
+			}
+			fired++
+		}
+	}
+	if len(w.pos) != 0 {
+		t.Error("All the keys must be removed from the wheel")
+	}
+}
+
+func TestSetTimingWheel(t *testing.T) {
+	if New(0, 0).SetTimingWheel(-1) == nil {
+		t.Error("Impossiable!")
+	}
+	c := New(0, 0)
+	defer c.Close()
+	clk := &fixedClock{now: time.Unix(1000, 0)}
+	c.SetClock(clk)
+	c.Set("before", 1, time.Second)
+	if err := c.SetTimingWheel(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	// The wheel is advanced by the test.
+	c.PauseCleanup()
+	c.Set("a", 1, 2*time.Second)
+	c.Set("b", 1, time.Minute)
+	c.Set("c", 1, -1)
+	c.Set("touched", 1, time.Second)
+	c.Touch("touched", time.Minute)
+	c.Set("deleted", 1, time.Second)
+	c.Delete("deleted")
+	clk.now = clk.now.Add(3 * time.Second)
+	c.advanceWheel()
+	if c.ItemCount() != 3 {
+		t.Error("The expired items must be deleted", c.ItemCount())
+	}
+	clk.now = clk.now.Add(time.Minute)
+	c.advanceWheel()
+	if _, found := c.Get("c"); !found || c.ItemCount() != 1 {
+		t.Error("Now, only the item without expiration is left", c.ItemCount())
+	}
+}
@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Mutation is a write of the cache queued for the Store by a WriteBehind.
+type Mutation struct {
+	Key   interface{}
+	Value interface{}
+	TTL   time.Duration
+	// Delete is true if the key was deleted.
+	Delete bool
+	// version is the version of the item set, with HoldUntilWritten.
+	version uint64
+}
+
+// BatchStore is a WritableStore which can apply many mutations at once.
+// A WriteBehind uses it if the store implements it.
+type BatchStore interface {
+	WritableStore
+	Apply(batch []Mutation) error
+}
+
+// WriteBehindPolicy configure a WriteBehind.
+type WriteBehindPolicy struct {
+	// BufferSize is the number of mutations queued at most. Set and Delete
+	// block while the queue is full. It defaults to 1024.
+	BufferSize int
+	// BatchSize is the number of mutations written at once at most. It
+	// defaults to 1.
+	BatchSize int
+	// Workers is the number of batches written at once, 1 if less.
+	Workers int
+	// RetryDelay is the delay before writing a failed batch again, and
+	// MaxRetries the number of retries before it is dropped.
+	RetryDelay time.Duration
+	MaxRetries int
+	// HoldUntilWritten makes the keys set not expire until their mutation
+	// is written to the store, then expire after their TTL from then, so
+	// a key can not expire and miss before the store has it. A key whose
+	// mutation is dropped does not expire until it is written again. The
+	// max TTL of the cache still applies.
+	HoldUntilWritten bool
+}
+
+// WriteBehind write the mutations of a Cache to its store asynchronously.
+type WriteBehind struct {
+	c       *Cache
+	store   WritableStore
+	policy  WriteBehindPolicy
+	queue   chan Mutation
+	wg      sync.WaitGroup
+	senders sync.WaitGroup
+	dropped uint64
+
+	mu      sync.RWMutex
+	closed  bool
+	lastErr error
+}
+
+// EnableWriteBehind make Set and Delete update the cache only, and queue
+// the mutations for workers writing them to s in the background, retrying
+// the failures. Use Drain to write the queued mutations and stop at
+// shutdown. The writes are paused while the cache is degraded, see
+// SetDegraded. It replaces the write-through of a WritableStore set with
+// SetStore.
+func (c *Cache) EnableWriteBehind(s WritableStore, policy WriteBehindPolicy) (*WriteBehind, error) {
+	if s == nil {
+		return nil, errors.New("The store must not be nil")
+	}
+	if policy.BufferSize < 1 {
+		policy.BufferSize = 1024
+	}
+	if policy.BatchSize < 1 {
+		policy.BatchSize = 1
+	}
+	if policy.Workers < 1 {
+		policy.Workers = 1
+	}
+	wb := &WriteBehind{
+		c:      c,
+		store:  s,
+		policy: policy,
+		queue:  make(chan Mutation, policy.BufferSize),
+	}
+	c.Lock()
+	if c.writeBehind != nil {
+		c.Unlock()
+		return nil, errors.New("The write-behind is already enabled")
+	}
+	c.writeBehind = wb
+	c.Unlock()
+	wb.wg.Add(policy.Workers)
+	for i := 0; i < policy.Workers; i++ {
+		go wb.work()
+	}
+	return wb, nil
+}
+
+// Drain stop queuing the mutations of the cache, then wait for the queued
+// ones to be written, or dropped after their retries.
+func (wb *WriteBehind) Drain() {
+	wb.c.Lock()
+	if wb.c.writeBehind == wb {
+		wb.c.writeBehind = nil
+	}
+	wb.c.Unlock()
+	wb.mu.Lock()
+	closing := !wb.closed
+	wb.closed = true
+	wb.mu.Unlock()
+	if closing {
+		// The workers go on while the senders blocked on a full queue
+		// finish, then stop when the queue is empty.
+		wb.senders.Wait()
+		close(wb.queue)
+	}
+	wb.wg.Wait()
+}
+
+// Pending return the number of queued mutations.
+func (wb *WriteBehind) Pending() int {
+	return len(wb.queue)
+}
+
+// Dropped return the number of mutations dropped after failing all their
+// retries.
+func (wb *WriteBehind) Dropped() uint64 {
+	return atomic.LoadUint64(&wb.dropped)
+}
+
+// LastError return the last error of the store.
+func (wb *WriteBehind) LastError() error {
+	wb.mu.RLock()
+	defer wb.mu.RUnlock()
+	return wb.lastErr
+}
+
+// writeBehindPause is how often the workers check if the cache is still
+// degraded.
+const writeBehindPause = 10 * time.Millisecond
+
+func (wb *WriteBehind) draining() bool {
+	wb.mu.RLock()
+	defer wb.mu.RUnlock()
+	return wb.closed
+}
+
+// enqueue queue a mutation, blocking while the queue is full. The lock is
+// not held while blocking, as the workers take it to record their errors.
+func (wb *WriteBehind) enqueue(m Mutation) {
+	wb.mu.RLock()
+	if wb.closed {
+		wb.mu.RUnlock()
+		return
+	}
+	wb.senders.Add(1)
+	wb.mu.RUnlock()
+	wb.queue <- m
+	wb.senders.Done()
+}
+
+func (wb *WriteBehind) work() {
+	defer wb.wg.Done()
+	for m := range wb.queue {
+		batch := []Mutation{m}
+	collect:
+		for len(batch) < wb.policy.BatchSize {
+			select {
+			case m, ok := <-wb.queue:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, m)
+			default:
+				break collect
+			}
+		}
+		wb.write(batch)
+	}
+}
+
+func (wb *WriteBehind) write(batch []Mutation) {
+	// The writes are paused while the cache is degraded, unless draining.
+	for wb.c.Degraded() && !wb.draining() {
+		time.Sleep(writeBehindPause)
+	}
+	var err error
+	for try := 0; try <= wb.policy.MaxRetries; try++ {
+		if try > 0 {
+			time.Sleep(wb.policy.RetryDelay)
+		}
+		if batch, err = wb.apply(batch); err == nil {
+			return
+		}
+		wb.mu.Lock()
+		wb.lastErr = err
+		wb.mu.Unlock()
+	}
+	atomic.AddUint64(&wb.dropped, uint64(len(batch)))
+}
+
+// apply write the batch, returning the mutations not written if it fails.
+func (wb *WriteBehind) apply(batch []Mutation) ([]Mutation, error) {
+	if bs, ok := wb.store.(BatchStore); ok {
+		if err := bs.Apply(batch); err != nil {
+			return batch, err
+		}
+		for _, m := range batch {
+			wb.c.expireWritten(m)
+		}
+		return nil, nil
+	}
+	for i, m := range batch {
+		var err error
+		if m.Delete {
+			err = wb.store.Delete(m.Key)
+		} else {
+			err = wb.store.Save(m.Key, m.Value, m.TTL)
+		}
+		if err != nil {
+			return batch[i:], err
+		}
+		wb.c.expireWritten(m)
+	}
+	return nil, nil
+}
+
+// expireWritten start the TTL of a key held until its mutation is written,
+// see HoldUntilWritten, unless it was written again since.
+func (c *Cache) expireWritten(m Mutation) {
+	if m.version == 0 || m.TTL <= 0 {
+		return
+	}
+	c.Lock()
+	if item, ok := c.items[m.Key]; ok && item.version == m.version {
+		item = c.writable(m.Key, item)
+		c.changes++
+		t := c.now().Add(m.TTL)
+		item.Expiration = &t
+		c.scheduleExpiry(m.Key, t)
+	}
+	c.Unlock()
+}
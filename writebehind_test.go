@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchMapStore struct {
+	sync.Mutex
+	data    map[interface{}]interface{}
+	batches int
+	fails   int
+}
+
+func (s *batchMapStore) Load(key interface{}) (interface{}, time.Duration, error) {
+	return nil, 0, ErrNotFound
+}
+
+func (s *batchMapStore) Save(key interface{}, value interface{}, ttl time.Duration) error {
+	return s.Apply([]Mutation{{Key: key, Value: value, TTL: ttl}})
+}
+
+func (s *batchMapStore) Delete(key interface{}) error {
+	return s.Apply([]Mutation{{Key: key, Delete: true}})
+}
+
+func (s *batchMapStore) Apply(batch []Mutation) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.fails > 0 {
+		s.fails--
+		return errors.New("database down")
+	}
+	s.batches++
+	for _, m := range batch {
+		if m.Delete {
+			delete(s.data, m.Key)
+		} else {
+			s.data[m.Key] = m.Value
+		}
+	}
+	return nil
+}
+
+func TestWriteBehind(t *testing.T) {
+	c := New(0, 0)
+	s := &batchMapStore{data: map[interface{}]interface{}{}, fails: 1}
+	wb, err := c.EnableWriteBehind(s, WriteBehindPolicy{BatchSize: 100, RetryDelay: time.Millisecond, MaxRetries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.EnableWriteBehind(s, WriteBehindPolicy{}); err == nil {
+		t.Error("Impossiable!")
+	}
+	for i := 0; i < 50; i++ {
+		c.Set(i, i, 0)
+	}
+	c.Delete(0)
+	if val, _ := c.Get(1); val != 1 {
+		t.Error("The cache must be updated at once")
+	}
+	wb.Drain()
+	if len(s.data) != 49 || s.data[49] != 49 {
+		t.Errorf("The store holds %d keys", len(s.data))
+	}
+	if s.batches >= 51 || wb.Dropped() != 0 || wb.LastError() == nil {
+		t.Errorf("You get %d batches, %d dropped", s.batches, wb.Dropped())
+	}
+	c.Set("after", 1, 0)
+	if _, ok := s.data["after"]; ok {
+		t.Error("Nothing must be queued after Drain")
+	}
+}
+
+func TestWriteBehindDrop(t *testing.T) {
+	c := New(0, 0)
+	s := &batchMapStore{data: map[interface{}]interface{}{}, fails: 10}
+	wb, _ := c.EnableWriteBehind(s, WriteBehindPolicy{MaxRetries: 1})
+	c.Set("a", 1, 0)
+	wb.Drain()
+	if wb.Dropped() != 1 || len(s.data) != 0 {
+		t.Error("The mutation must be dropped after its retries")
+	}
+}
+
+func TestWriteBehindDegraded(t *testing.T) {
+	c := New(0, 0)
+	s := &batchMapStore{data: map[interface{}]interface{}{}}
+	wb, _ := c.EnableWriteBehind(s, WriteBehindPolicy{})
+	c.SetDegraded(true)
+	c.Set("a", 1, 0)
+	time.Sleep(30 * time.Millisecond)
+	s.Lock()
+	if len(s.data) != 0 {
+		t.Error("The writes must be paused while degraded")
+	}
+	s.Unlock()
+	c.SetDegraded(false)
+	wb.Drain()
+	if s.data["a"] != 1 {
+		t.Error("The writes must resume")
+	}
+}
+
+func TestWriteBehindFullQueue(t *testing.T) {
+	c := New(0, 0)
+	s := &batchMapStore{data: map[interface{}]interface{}{}, fails: 3}
+	wb, _ := c.EnableWriteBehind(s, WriteBehindPolicy{BufferSize: 1, MaxRetries: 5, RetryDelay: time.Millisecond})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Set(i, i, 0)
+		}(i)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		wb.Drain()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("The writers, the workers and Drain are deadlocked")
+	}
+	if len(s.data) != 5 || wb.LastError() == nil {
+		t.Error("All the mutations must be written after the failures", len(s.data))
+	}
+}
+
+func TestWriteBehindHoldUntilWritten(t *testing.T) {
+	c := New(0, 0)
+	s := &batchMapStore{data: map[interface{}]interface{}{}}
+	wb, _ := c.EnableWriteBehind(s, WriteBehindPolicy{HoldUntilWritten: true})
+	c.SetDegraded(true)
+	c.Set("a", 1, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if info, found := c.GetItemInfo("a"); !found || info.Expiration != nil {
+		t.Error("The key must not expire before it is written")
+	}
+	c.SetDegraded(false)
+	wb.Drain()
+	info, found := c.GetItemInfo("a")
+	if !found || info.Expiration == nil || info.Expiration.Before(time.Now()) {
+		t.Error("Now, the TTL must start from the write")
+	}
+	if s.data["a"] != 1 {
+		t.Error("Impossiable!")
+	}
+}